@@ -0,0 +1,214 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	databaseFile       = "bot_data.sqlite"
+	backupPrefix       = "bot_data-"
+	backupSuffix       = ".sqlite"
+	backupTimestamp    = "20060102-150405"
+	restoredFileSuffix = ".restoring"
+)
+
+var (
+	ErrBackupUnsupported = errors.New("backups are only supported for sqlite databases")
+	ErrInvalidBackup     = errors.New("invalid backup")
+)
+
+// Backup writes a consistent snapshot of the database in dir with VACUUM
+// INTO, which is safe while the bot keeps serving requests. The snapshot is
+// written under a temporary name and renamed once complete.
+func (d *Database) Backup(dir string) (string, error) {
+	if d.dialect != SQLite {
+		return "", ErrBackupUnsupported
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupTimestamp)+backupSuffix)
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+
+	if _, err := d.exec(`VACUUM INTO @path;`, map[string]any{"path": tmp}); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// ListBackups returns the snapshots found in dir, oldest first.
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+
+		return nil, err
+	}
+
+	backups := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+
+		backups = append(backups, filepath.Join(dir, name))
+	}
+
+	// the timestamp in the name sorts chronologically
+	sort.Strings(backups)
+
+	return backups, nil
+}
+
+// PruneBackups deletes the oldest snapshots in dir, keeping the latest keep.
+func PruneBackups(dir string, keep int) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > keep {
+		log.Println("removing old backup", backups[0])
+		if err = os.Remove(backups[0]); err != nil {
+			return err
+		}
+
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// ValidateBackup checks that the file is an intact SQLite database with a
+// schema this binary knows how to use.
+func ValidateBackup(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("%w: %s is a directory", ErrInvalidBackup, path)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var integrity string
+	if err = db.QueryRow(`PRAGMA integrity_check;`).Scan(&integrity); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	if integrity != "ok" {
+		return fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, integrity)
+	}
+
+	var version int
+	if err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&version); err != nil {
+		return fmt.Errorf("%w: missing schema version: %v", ErrInvalidBackup, err)
+	}
+
+	migrations, err := LoadMigrations("migrations/sqlite")
+	if err != nil {
+		return err
+	}
+
+	if latest := migrations[len(migrations)-1].Version; version > latest {
+		return fmt.Errorf("%w: backup is at version %d, latest known is %d", ErrSchemaTooNew, version, latest)
+	}
+
+	var events int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM events;`).Scan(&events); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	log.Printf("backup %s is valid: schema version %d, %d events", path, version, events)
+
+	return nil
+}
+
+// RestoreBackup replaces the database in dbPath with the snapshot, once it
+// has been validated. The bot must be stopped, the replaced database is kept
+// next to it. Pending migrations are applied at the next start.
+func RestoreBackup(snapshot, dbPath string) error {
+	if err := ValidateBackup(snapshot); err != nil {
+		return err
+	}
+
+	target := filepath.Join(dbPath, databaseFile)
+	tmp := target + restoredFileSuffix
+
+	if err := copyFile(snapshot, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if _, err := os.Stat(target); err == nil {
+		replaced := fmt.Sprintf("%s.replaced-%s", target, time.Now().UTC().Format(backupTimestamp))
+		if err = os.Rename(target, replaced); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+
+		log.Println("current database moved to", replaced)
+	}
+
+	// the journal of the replaced database must not be applied to the snapshot
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(target + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(tmp, target)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
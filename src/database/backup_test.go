@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	db := NewDatabase(t.TempDir())
+	t.Cleanup(db.Close)
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	eventID := mustInsertEvent(t, db, 1, "Game night")
+	mustInsertBoardGame(t, db, eventID, "Catan", 4)
+
+	dir := t.TempDir()
+	path, err := db.Backup(dir)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	if err = ValidateBackup(path); err != nil {
+		t.Fatalf("backup is not valid: %v", err)
+	}
+
+	restored := t.TempDir()
+	if err = RestoreBackup(path, restored); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	restoredDB := NewDatabase(restored)
+	t.Cleanup(restoredDB.Close)
+
+	event := mustSelectEvent(t, restoredDB, eventID)
+	if event.Name != "Game night" || len(event.BoardGames) != 1 || event.BoardGames[0].Name != "Catan" {
+		t.Fatalf("unexpected restored event: %+v", event)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"bot_data-20240101-000000.sqlite",
+		"bot_data-20240102-000000.sqlite",
+		"bot_data-20240103-000000.sqlite",
+		"notes.txt",
+	}
+
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := PruneBackups(dir, 2); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 2 || filepath.Base(backups[0]) != names[1] || filepath.Base(backups[1]) != names[2] {
+		t.Fatalf("unexpected backups left: %v", backups)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("unrelated file removed: %v", err)
+	}
+}
+
+func TestRestoreRejectsInvalidBackup(t *testing.T) {
+	dbPath := t.TempDir()
+	current := filepath.Join(dbPath, databaseFile)
+	if err := os.WriteFile(current, []byte("current"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := filepath.Join(t.TempDir(), "bot_data-20240101-000000.sqlite")
+	if err := os.WriteFile(snapshot, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreBackup(snapshot, dbPath); !errors.Is(err, ErrInvalidBackup) {
+		t.Fatalf("expected ErrInvalidBackup, got %v", err)
+	}
+
+	content, err := os.ReadFile(current)
+	if err != nil || string(content) != "current" {
+		t.Fatalf("current database was touched: %q %v", content, err)
+	}
+}
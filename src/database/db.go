@@ -5,28 +5,47 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"math"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
 var ErrNoRows = errors.New("sql: no rows in result set")
 
 func NewDatabase(path string) *Database {
-	db, err := sql.Open("sqlite3", filepath.Join(path, "bot_data.sqlite"))
+	// immediate transactions take the write lock upfront, so that concurrent
+	// joins cannot both grab the last seat of a game
+	db, err := sql.Open("sqlite3", filepath.Join(path, databaseFile)+"?_txlock=immediate&_busy_timeout=5000")
 	if err != nil {
-		log.Fatal("failed to open database '"+filepath.Join(path, "bot_data.sqlite")+"':", err)
+		log.Fatal("failed to open database '"+filepath.Join(path, databaseFile)+"':", err)
 	}
 
-	return &Database{db}
+	return &Database{db, SQLite}
+}
+
+func NewPostgresDatabase(url string) *Database {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		log.Fatal("failed to open postgres database:", err)
+	}
+
+	if err = db.Ping(); err != nil {
+		log.Fatal("failed to connect to postgres database:", err)
+	}
+
+	return &Database{db, Postgres}
 }
 
 func NamedArgs(arg map[string]any) []any {
@@ -38,76 +57,31 @@ func NamedArgs(arg map[string]any) []any {
 	return args
 }
 
-func (d *Database) CreateTables() {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS chats (
-			chat_id INTEGER NOT NULL,
-			language TEXT NOT NULL DEFAULT 'en',
-			PRIMARY KEY(chat_id)
-			UNIQUE(chat_id) ON CONFLICT REPLACE
-		);`,
-		`CREATE TABLE IF NOT EXISTS events (
-			id TEXT PRIMARY KEY,
-			chat_id INTEGER,
-			user_id INTEGER,
-			user_name TEXT,
-			name TEXT,
-			message_id INTEGER,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);`,
-		`CREATE TABLE IF NOT EXISTS boardgames (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			event_id INTEGER,
-			name TEXT,
-			max_players INTEGER,
-			message_id INTEGER,
-			bgg_id INTEGER,
-			bgg_name TEXT,
-			bgg_url TEXT,
-			bgg_image_url TEXT,
-			FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE
-   			FOREIGN KEY(initiator_name) REFERENCES events(user_name) ON DELETE CASCADE
-		);`,
-		`CREATE TABLE IF NOT EXISTS participants (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			event_id INTEGER,
-			boardgame_id INTEGER,
-			user_id INTEGER,
-			user_name TEXT,
-			FOREIGN KEY(event_id) REFERENCES boardgames(events) ON DELETE CASCADE,
-			FOREIGN KEY(boardgame_id) REFERENCES boardgames(id) ON DELETE CASCADE,
-			UNIQUE(event_id, user_id) ON CONFLICT REPLACE
-		);`,
-	}
-
-	for _, query := range queries {
-		_, err := d.db.Exec(query)
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	log.Println("database tables ensured")
-}
-
 func (d *Database) Close() {
 	d.db.Close()
 	log.Println("database connection closed")
 }
 
-func (d *Database) InsertEvent(chatID, userID int64, userName, name string, messageID *int64) (string, error) {
+func (d *Database) InsertEvent(chatID, userID int64, userName, name string, messageID *int64, startsAt, endsAt *time.Time, location *string, status models.EventStatus) (string, error) {
 	var eventID string
-	query := `INSERT INTO events (id, chat_id, user_id, user_name, name, message_id) VALUES (@event_id, @chat_id, @user_id, @user_name, @name, @message_id) RETURNING id;`
+	// created_at is set here rather than by the column default, which only has
+	// second precision, so events created in a row keep their order.
+	query := `INSERT INTO events (id, chat_id, user_id, user_name, name, message_id, starts_at, ends_at, location, status, created_at) VALUES (@event_id, @chat_id, @user_id, @user_name, @name, @message_id, @starts_at, @ends_at, @location, @status, @created_at) RETURNING id;`
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
+	if err := d.queryRow(query,
+		map[string]any{
 			"event_id":   uuid.New().String(),
 			"chat_id":    chatID,
 			"user_id":    userID,
 			"user_name":  userName,
 			"name":       name,
 			"message_id": messageID,
-		})...,
+			"starts_at":  startsAt,
+			"ends_at":    endsAt,
+			"location":   location,
+			"status":     status,
+			"created_at": time.Now().UTC(),
+		},
 	).Scan(&eventID); err != nil {
 		return "", err
 	}
@@ -115,14 +89,21 @@ func (d *Database) InsertEvent(chatID, userID int64, userName, name string, mess
 	return eventID, nil
 }
 
-func (d *Database) SelectEvent(chatID int64) (*models.Event, error) {
-	query := `
+// selectEventQuery loads an event with its games and participants, the
+// caller appends the WHERE clause selecting the event.
+const selectEventQuery = `
 	SELECT 
 	e.id, 
 	e.name, 
 	e.chat_id,
 	e.message_id,
 	e.user_id,
+	e.user_name,
+	e.starts_at,
+	e.ends_at,
+	e.location,
+	e.status,
+	e.join_mode,
 	b.id,
 	b.name,
 	b.max_players,
@@ -130,43 +111,173 @@ func (d *Database) SelectEvent(chatID int64) (*models.Event, error) {
 	b.bgg_name,
 	b.bgg_url,
 	b.bgg_image_url,
+	b.initiator_name,
+	b.bringer_id,
+	b.bringer_name,
+	b.message_id,
 	p.id,
 	p.user_id,
-	p.user_name
+	p.user_name,
+	p.waitlisted
 	FROM events e
 	LEFT JOIN boardgames b ON e.id = b.event_id
 	LEFT JOIN participants p ON b.id = p.boardgame_id
-	WHERE e.id = (SELECT id FROM events WHERE chat_id = @chat_id ORDER BY created_at DESC LIMIT 1);`
+	`
+
+func (d *Database) SelectEvent(chatID int64) (*models.Event, error) {
+	query := selectEventQuery + `WHERE e.id = (SELECT id FROM events WHERE chat_id = @chat_id ORDER BY created_at DESC LIMIT 1);`
 	return d.selectEventByQuery(query, map[string]any{"chat_id": chatID})
 }
 
 func (d *Database) SelectEventByEventID(eventID string) (*models.Event, error) {
-	query := `
-	SELECT 
-	e.id, 
-	e.name, 
-	e.chat_id,
-	e.message_id,
-	e.user_id,
-	b.id,
-	b.name,
-	b.max_players,
-	b.bgg_id,
-	b.bgg_name,
-	b.bgg_url,
-	b.bgg_image_url,
-	p.id,
-	p.user_id,
-	p.user_name
-	FROM events e
-	LEFT JOIN boardgames b ON e.id = b.event_id
-	LEFT JOIN participants p ON b.id = p.boardgame_id
-	WHERE e.id = @id;`
+	query := selectEventQuery + `WHERE e.id = @id;`
 	return d.selectEventByQuery(query, map[string]any{"id": eventID})
 }
 
+func (d *Database) SelectEventByMessageID(chatID, messageID int64) (*models.Event, error) {
+	query := selectEventQuery + `WHERE e.id = (SELECT id FROM events WHERE chat_id = @chat_id AND message_id = @message_id LIMIT 1);`
+	return d.selectEventByQuery(query, map[string]any{"chat_id": chatID, "message_id": messageID})
+}
+
+func (d *Database) SelectEventByCode(chatID int64, code string) (*models.Event, error) {
+	query := selectEventQuery + `WHERE e.id = (SELECT id FROM events WHERE chat_id = @chat_id AND id LIKE @code ORDER BY created_at DESC LIMIT 1);`
+	return d.selectEventByQuery(query, map[string]any{"chat_id": chatID, "code": strings.ToLower(code) + "%"})
+}
+
+func (d *Database) SelectEventByBoardGameMessageID(chatID, messageID int64) (*models.Event, error) {
+	query := selectEventQuery + `WHERE e.id = (
+		SELECT bg.event_id FROM boardgames bg
+		JOIN events ev ON ev.id = bg.event_id
+		WHERE ev.chat_id = @chat_id AND bg.message_id = @message_id
+		LIMIT 1
+	);`
+	return d.selectEventByQuery(query, map[string]any{"chat_id": chatID, "message_id": messageID})
+}
+
+// SelectActiveEvents returns the open and locked events of a chat, newest
+// first, without their games.
+func (d *Database) SelectActiveEvents(chatID int64) ([]models.Event, error) {
+	query := `SELECT id, name, chat_id, message_id, user_id, user_name, starts_at, status
+	FROM events
+	WHERE chat_id = @chat_id AND status IN (@open, @locked)
+	ORDER BY created_at DESC;`
+
+	rows, err := d.query(query, map[string]any{
+		"chat_id": chatID,
+		"open":    models.StatusOpen,
+		"locked":  models.StatusLocked,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		var messageID pgtype.Int8
+		var userName pgtype.Text
+		var startsAt sql.NullTime
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.Name,
+			&event.ChatID,
+			&messageID,
+			&event.UserID,
+			&userName,
+			&startsAt,
+			&event.Status,
+		); err != nil {
+			return nil, err
+		}
+
+		event.MessageID = IntOrNil(messageID)
+		if userName.Valid {
+			event.UserName = userName.String
+		}
+		event.StartsAt = TimeOrNil(startsAt)
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// SelectEventSummaries pages through the events of a chat, the open and
+// locked ones first, each group newest first.
+func (d *Database) SelectEventSummaries(chatID int64, limit, offset int) ([]models.EventSummary, error) {
+	query := `SELECT e.id, e.name, e.chat_id, e.message_id, e.user_id, e.user_name, e.starts_at, e.ends_at, e.location, e.status, e.join_mode,
+	(SELECT COUNT(*) FROM boardgames b WHERE b.event_id = e.id AND b.name <> @counter),
+	(SELECT COUNT(DISTINCT p.user_id) FROM participants p JOIN boardgames b ON p.boardgame_id = b.id WHERE b.event_id = e.id AND p.waitlisted = FALSE)
+	FROM events e
+	WHERE e.chat_id = @chat_id
+	ORDER BY CASE WHEN e.status IN (@open, @locked) THEN 0 ELSE 1 END, e.created_at DESC
+	LIMIT @limit OFFSET @offset;`
+
+	rows, err := d.query(query, map[string]any{
+		"chat_id": chatID,
+		"counter": models.PLAYER_COUNTER,
+		"open":    models.StatusOpen,
+		"locked":  models.StatusLocked,
+		"limit":   limit,
+		"offset":  offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	summaries := []models.EventSummary{}
+	for rows.Next() {
+		var summary models.EventSummary
+		var messageID pgtype.Int8
+		var userName, location pgtype.Text
+		var startsAt, endsAt sql.NullTime
+
+		if err := rows.Scan(
+			&summary.ID,
+			&summary.Name,
+			&summary.ChatID,
+			&messageID,
+			&summary.UserID,
+			&userName,
+			&startsAt,
+			&endsAt,
+			&location,
+			&summary.Status,
+			&summary.JoinMode,
+			&summary.Games,
+			&summary.Participants,
+		); err != nil {
+			return nil, err
+		}
+
+		summary.MessageID = IntOrNil(messageID)
+		if userName.Valid {
+			summary.UserName = userName.String
+		}
+		summary.StartsAt = TimeOrNil(startsAt)
+		summary.EndsAt = TimeOrNil(endsAt)
+		summary.Location = StringOrNil(location)
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (d *Database) CountEvents(chatID int64) (int, error) {
+	var count int
+	err := d.queryRow(`SELECT COUNT(*) FROM events WHERE chat_id = @chat_id;`, map[string]any{"chat_id": chatID}).Scan(&count)
+
+	return count, err
+}
+
 func (d *Database) selectEventByQuery(query string, args map[string]any) (*models.Event, error) {
-	rows, err := d.db.Query(query, NamedArgs(args)...)
+	rows, err := d.query(query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -180,8 +291,10 @@ func (d *Database) selectEventByQuery(query string, args map[string]any) (*model
 		var boardGame models.BoardGame
 		var participant models.Participant
 
-		var eventMessageID, boardGameID, boardGameMaxPlayers, participantID, participantUserID, bggID pgtype.Int8
-		var boardGameName, participantUserName, bggName, bggUrl, bggImageUrl pgtype.Text
+		var eventMessageID, boardGameID, boardGameMaxPlayers, boardGameMessageID, participantID, participantUserID, bggID, bringerID pgtype.Int8
+		var startsAt, endsAt sql.NullTime
+		var participantWaitlisted sql.NullBool
+		var eventUserName, eventLocation, boardGameName, participantUserName, bggName, bggUrl, bggImageUrl, initiatorName, bringerName pgtype.Text
 
 		if err := rows.Scan(
 			&event.ID,
@@ -189,6 +302,12 @@ func (d *Database) selectEventByQuery(query string, args map[string]any) (*model
 			&event.ChatID,
 			&eventMessageID,
 			&event.UserID,
+			&eventUserName,
+			&startsAt,
+			&endsAt,
+			&eventLocation,
+			&event.Status,
+			&event.JoinMode,
 			&boardGameID,
 			&boardGameName,
 			&boardGameMaxPlayers,
@@ -196,25 +315,39 @@ func (d *Database) selectEventByQuery(query string, args map[string]any) (*model
 			&bggName,
 			&bggUrl,
 			&bggImageUrl,
+			&initiatorName,
+			&bringerID,
+			&bringerName,
+			&boardGameMessageID,
 			&participantID,
 			&participantUserID,
 			&participantUserName,
+			&participantWaitlisted,
 		); err != nil {
 			return nil, err
 		}
 
 		event.MessageID = IntOrNil(eventMessageID)
-		event.Locked = strings.Contains(event.Name, "🔒")
+		if eventUserName.Valid {
+			event.UserName = eventUserName.String
+		}
+		event.StartsAt = TimeOrNil(startsAt)
+		event.EndsAt = TimeOrNil(endsAt)
+		event.Location = StringOrNil(eventLocation)
 
 		if IntOrNil(boardGameID) != nil {
 			boardGame = models.BoardGame{
-				ID:          *IntOrNil(boardGameID),
-				Name:        *StringOrNil(boardGameName),
-				MaxPlayers:  *IntOrNil(boardGameMaxPlayers),
-				BggID:       IntOrNil(bggID),
-				BggName:     StringOrNil(bggName),
-				BggUrl:      StringOrNil(bggUrl),
-				BggImageUrl: StringOrNil(bggImageUrl),
+				ID:            *IntOrNil(boardGameID),
+				Name:          *StringOrNil(boardGameName),
+				MaxPlayers:    *IntOrNil(boardGameMaxPlayers),
+				BggID:         IntOrNil(bggID),
+				BggName:       StringOrNil(bggName),
+				BggUrl:        StringOrNil(bggUrl),
+				BggImageUrl:   StringOrNil(bggImageUrl),
+				InitiatorName: StringOrNil(initiatorName),
+				BringerID:     IntOrNil(bringerID),
+				BringerName:   StringOrNil(bringerName),
+				MessageID:     IntOrNil(boardGameMessageID),
 			}
 
 			if _, ok := boardGameMap[boardGame.ID]; !ok {
@@ -224,12 +357,18 @@ func (d *Database) selectEventByQuery(query string, args map[string]any) (*model
 
 		if IntOrNil(participantID) != nil {
 			participant = models.Participant{
-				ID:       *IntOrNil(participantID),
-				UserID:   *IntOrNil(participantUserID),
-				UserName: *StringOrNil(participantUserName),
+				ID:         *IntOrNil(participantID),
+				UserID:     *IntOrNil(participantUserID),
+				UserName:   *StringOrNil(participantUserName),
+				Waitlisted: participantWaitlisted.Bool,
 			}
 
-			boardGameMap[boardGame.ID].Participants = append(boardGameMap[boardGame.ID].Participants, participant)
+			bg := boardGameMap[boardGame.ID]
+			if participant.Waitlisted {
+				bg.Waitlist = append(bg.Waitlist, participant)
+			} else {
+				bg.Participants = append(bg.Participants, participant)
+			}
 		}
 	}
 
@@ -237,29 +376,53 @@ func (d *Database) selectEventByQuery(query string, args map[string]any) (*model
 		return nil, rows.Err()
 	}
 
+	if event.ID == "" {
+		return nil, ErrNoRows
+	}
+
 	for _, boardGame := range boardGameMap {
+		event.BoardGames = append(event.BoardGames, *boardGame)
+	}
+
+	sortEvent(event)
+
+	if err := d.selectOwners(event); err != nil {
+		return nil, err
+	}
+
+	if err := d.selectOrganizers(event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// sortEvent orders the games by name, their participants by user name and
+// their waitlist by joining order, so that every Store renders events the
+// same way.
+func sortEvent(event *models.Event) {
+	for _, boardGame := range event.BoardGames {
 		sort.SliceStable(boardGame.Participants, func(i, j int) bool {
 			return boardGame.Participants[i].UserName < boardGame.Participants[j].UserName
 		})
-
-		event.BoardGames = append(event.BoardGames, *boardGame)
+		sort.SliceStable(boardGame.Waitlist, func(i, j int) bool {
+			return boardGame.Waitlist[i].ID < boardGame.Waitlist[j].ID
+		})
 	}
 
 	sort.SliceStable(event.BoardGames, func(i, j int) bool {
 		return event.BoardGames[i].Name < event.BoardGames[j].Name || (event.BoardGames[i].Name == event.BoardGames[j].Name && event.BoardGames[i].ID < event.BoardGames[j].ID)
 	})
-
-	return event, nil
 }
 
 func (d *Database) UpdateEventMessageID(eventID string, messageID int64) error {
 	query := `UPDATE events SET message_id = @message_id where id = @event_id;`
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
+	if _, err := d.exec(query,
+		map[string]any{
 			"event_id":   eventID,
 			"message_id": messageID,
-		})...,
+		},
 	); err != nil {
 		return err
 	}
@@ -267,9 +430,64 @@ func (d *Database) UpdateEventMessageID(eventID string, messageID int64) error {
 	return nil
 }
 
-func (d *Database) InsertBoardGame(eventID string, name string, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string, initiator_name string) (int64, error) {
+func (d *Database) UpdateEventDetails(eventID string, startsAt, endsAt *time.Time, location *string) error {
+	query := `UPDATE events SET starts_at = @starts_at, ends_at = @ends_at, location = @location WHERE id = @event_id RETURNING id;`
+
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id":  eventID,
+			"starts_at": startsAt,
+			"ends_at":   endsAt,
+			"location":  location,
+		},
+	).Scan(&eventID); err != nil {
+		return ParseError(err)
+	}
+
+	return nil
+}
+
+func (d *Database) UpdateEventStatus(eventID string, status models.EventStatus) error {
+	query := `UPDATE events SET status = @status WHERE id = @event_id RETURNING id;`
+
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id": eventID,
+			"status":   status,
+		},
+	).Scan(&eventID); err != nil {
+		return ParseError(err)
+	}
+
+	return nil
+}
+
+func (d *Database) UpdateEventJoinMode(eventID string, joinMode models.JoinMode) error {
+	query := `UPDATE events SET join_mode = @join_mode WHERE id = @event_id RETURNING id;`
+
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id":  eventID,
+			"join_mode": joinMode,
+		},
+	).Scan(&eventID); err != nil {
+		return ParseError(err)
+	}
+
+	return nil
+}
+
+// InsertBoardGame adds a game to the event, the user who added it brings it
+// unless the initiator is unknown.
+func (d *Database) InsertBoardGame(eventID string, name string, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string, initiatorID *int64, initiatorName *string) (int64, error) {
 	var boardGameID int64
-	query := `INSERT INTO boardgames (event_id, name, max_players, bgg_id, bgg_name, bgg_url, bgg_image_url, initiator_name) VALUES (@event_id, @name, @max_players, @bgg_id, @bgg_name, @bgg_url, @bgg_image_url, @initiator_name) RETURNING id;`
+	query := `INSERT INTO boardgames (event_id, name, max_players, bgg_id, bgg_name, bgg_url, bgg_image_url, initiator_name, bringer_id, bringer_name)
+	VALUES (@event_id, @name, @max_players, @bgg_id, @bgg_name, @bgg_url, @bgg_image_url, @initiator_name, @bringer_id, @bringer_name) RETURNING id;`
+
+	var bringerName *string
+	if initiatorID != nil {
+		bringerName = initiatorName
+	}
 
 	if bggImageUrl != nil && *bggImageUrl == "" {
 		// Fix for BGG image URLs that contains a filter with mandatory (png)
@@ -279,16 +497,19 @@ func (d *Database) InsertBoardGame(eventID string, name string, maxPlayers int,
 		bggImageUrl = &tmp
 	}
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"event_id":      eventID,
-			"name":          name,
-			"max_players":   maxPlayers,
-			"bgg_id":        bggID,
-			"bgg_url":       bggUrl,
-			"bgg_name":      bggName,
-			"bgg_image_url": bggImageUrl,
-		})...,
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id":       eventID,
+			"name":           name,
+			"max_players":    maxPlayers,
+			"bgg_id":         bggID,
+			"bgg_url":        bggUrl,
+			"bgg_name":       bggName,
+			"bgg_image_url":  bggImageUrl,
+			"initiator_name": initiatorName,
+			"bringer_id":     initiatorID,
+			"bringer_name":   bringerName,
+		},
 	).Scan(&boardGameID); err != nil {
 		return 0, err
 	}
@@ -296,14 +517,37 @@ func (d *Database) InsertBoardGame(eventID string, name string, maxPlayers int,
 	return boardGameID, nil
 }
 
+// UpdateBoardGameBringer changes who brings the game, a nil user leaves it
+// unclaimed.
+func (d *Database) UpdateBoardGameBringer(ID int64, userID *int64, userName *string) error {
+	query := `UPDATE boardgames SET bringer_id = @bringer_id, bringer_name = @bringer_name WHERE id = @id RETURNING id;`
+
+	if userID == nil {
+		userName = nil
+	}
+
+	var id int64
+	if err := d.queryRow(query,
+		map[string]any{
+			"id":           ID,
+			"bringer_id":   userID,
+			"bringer_name": userName,
+		},
+	).Scan(&id); err != nil {
+		return ParseError(err)
+	}
+
+	return nil
+}
+
 func (d *Database) UpdateBoardGameMessageID(boardgameID, messageID int64) error {
 	query := `UPDATE boardgames SET message_id = @message_id where id = @boardgame_id;`
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
+	if _, err := d.exec(query,
+		map[string]any{
 			"boardgame_id": boardgameID,
 			"message_id":   messageID,
-		})...,
+		},
 	); err != nil {
 		return err
 	}
@@ -311,84 +555,127 @@ func (d *Database) UpdateBoardGameMessageID(boardgameID, messageID int64) error
 	return nil
 }
 
-func (d *Database) UpdateBoardGamePlayerNumber(messageID int64, maxPlayers int) error {
-	var boardGameID int64
+func (d *Database) UpdateBoardGamePlayerNumber(chatID, messageID int64, maxPlayers int) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		var boardGameID int64
+
+		query := `UPDATE boardgames SET max_players = @max_players
+		WHERE message_id = @message_id AND event_id IN (SELECT id FROM events WHERE chat_id = @chat_id)
+		RETURNING id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"max_players": maxPlayers,
+				"message_id":  messageID,
+				"chat_id":     chatID,
+			},
+		).Scan(&boardGameID); err != nil {
+			return ParseError(err)
+		}
 
-	query := `UPDATE boardgames SET max_players = @max_players where message_id = @message_id RETURNING id;`
+		return d.promote(tx, boardGameID)
+	})
+}
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players": maxPlayers,
-			"message_id":  messageID,
-		})...,
-	).Scan(&boardGameID); err != nil {
-		return ParseError(err)
-	}
+func (d *Database) UpdateBoardGamePlayerNumberByID(ID int64, maxPlayers int) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `UPDATE boardgames SET max_players = @max_players WHERE id = @id RETURNING id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"max_players": maxPlayers,
+				"id":          ID,
+			},
+		).Scan(&ID); err != nil {
+			return ParseError(err)
+		}
 
-	return nil
+		return d.promote(tx, ID)
+	})
 }
 
-func (d *Database) UpdateBoardGameBGGInfo(messageID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
-	var boardGameID int64
+func (d *Database) UpdateBoardGameName(ID int64, name string) error {
+	query := `UPDATE boardgames SET name = @name WHERE id = @id RETURNING id;`
 
-	query := `UPDATE boardgames 
-	SET 
-	max_players = @max_players,
-	bgg_id = @bgg_id,
-	bgg_name = @bgg_name,
-	bgg_url = @bgg_url,
-	bgg_image_url = @bgg_image_url
-	WHERE message_id = @message_id RETURNING id;`
-
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players":   maxPlayers,
-			"message_id":    messageID,
-			"bgg_id":        bggID,
-			"bgg_name":      bggName,
-			"bgg_url":       bggUrl,
-			"bgg_image_url": bggImageUrl,
-		})...,
-	).Scan(&boardGameID); err != nil {
+	if err := d.queryRow(query,
+		map[string]any{
+			"name": name,
+			"id":   ID,
+		},
+	).Scan(&ID); err != nil {
 		return ParseError(err)
 	}
 
 	return nil
 }
 
+func (d *Database) UpdateBoardGameBGGInfo(chatID, messageID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		var boardGameID int64
+
+		query := `UPDATE boardgames 
+		SET 
+		max_players = @max_players,
+		bgg_id = @bgg_id,
+		bgg_name = @bgg_name,
+		bgg_url = @bgg_url,
+		bgg_image_url = @bgg_image_url
+		WHERE message_id = @message_id AND event_id IN (SELECT id FROM events WHERE chat_id = @chat_id)
+		RETURNING id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"max_players":   maxPlayers,
+				"message_id":    messageID,
+				"chat_id":       chatID,
+				"bgg_id":        bggID,
+				"bgg_name":      bggName,
+				"bgg_url":       bggUrl,
+				"bgg_image_url": bggImageUrl,
+			},
+		).Scan(&boardGameID); err != nil {
+			return ParseError(err)
+		}
+
+		return d.promote(tx, boardGameID)
+	})
+}
+
 func (d *Database) UpdateBoardGameBGGInfoByID(ID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
-	query := `UPDATE boardgames 
-	SET 
-	max_players = @max_players,
-	bgg_id = @bgg_id,
-	bgg_name = @bgg_name,
-	bgg_url = @bgg_url,
-	bgg_image_url = @bgg_image_url
-	WHERE id = @id RETURNING id;`
-
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players":   maxPlayers,
-			"id":            ID,
-			"bgg_id":        bggID,
-			"bgg_name":      bggName,
-			"bgg_url":       bggUrl,
-			"bgg_image_url": bggImageUrl,
-		})...,
-	).Scan(&ID); err != nil {
-		return ParseError(err)
-	}
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `UPDATE boardgames 
+		SET 
+		max_players = @max_players,
+		bgg_id = @bgg_id,
+		bgg_name = @bgg_name,
+		bgg_url = @bgg_url,
+		bgg_image_url = @bgg_image_url
+		WHERE id = @id RETURNING id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"max_players":   maxPlayers,
+				"id":            ID,
+				"bgg_id":        bggID,
+				"bgg_name":      bggName,
+				"bgg_url":       bggUrl,
+				"bgg_image_url": bggImageUrl,
+			},
+		).Scan(&ID); err != nil {
+			return ParseError(err)
+		}
 
-	return nil
+		return d.promote(tx, ID)
+	})
 }
 
 func (d *Database) DeleteBoardGameByID(ID int64) error {
 	query := `DELETE FROM boardgames WHERE id = @id;`
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
+	if _, err := d.exec(query,
+		map[string]any{
 			"id": ID,
-		})...,
+		},
 	); err != nil {
 		return err
 	}
@@ -396,14 +683,15 @@ func (d *Database) DeleteBoardGameByID(ID int64) error {
 	return nil
 }
 
-func (d *Database) HasBoardGameWithMessageID(messageID int64) bool {
-	query := `SELECT id FROM boardgames WHERE message_id = @message_id;`
+func (d *Database) HasBoardGameWithMessageID(chatID, messageID int64) bool {
+	query := `SELECT id FROM boardgames WHERE message_id = @message_id AND event_id IN (SELECT id FROM events WHERE chat_id = @chat_id);`
 
 	var id int64
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
+	if err := d.queryRow(query,
+		map[string]any{
 			"message_id": messageID,
-		})...,
+			"chat_id":    chatID,
+		},
 	).Scan(&id); err != nil {
 		return false
 	}
@@ -411,541 +699,1235 @@ func (d *Database) HasBoardGameWithMessageID(messageID int64) bool {
 	return true
 }
 
+// InsertParticipant adds the user to a game. Unless the event allows joining
+// multiple tables, their previous choice in the event is replaced and the seat
+// left goes to the first one waiting there. When the table is full the user
+// is put on its waitlist.
 func (d *Database) InsertParticipant(eventID string, boardgameID, userID int64, userName string) (int64, error) {
 	var participantID int64
-	query := `INSERT INTO participants (event_id, boardgame_id, user_id, user_name) VALUES (@event_id, @boardgame_id, @user_id, @user_name) RETURNING id;`
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"event_id":     eventID,
-			"boardgame_id": boardgameID,
-			"user_id":      userID,
-			"user_name":    userName,
-		})...,
-	).Scan(&participantID); err != nil {
+	err := d.withTx(func(tx *sql.Tx) error {
+		if err := d.lockBoardGame(tx, boardgameID); err != nil {
+			return err
+		}
+
+		var joinMode models.JoinMode
+		var maxPlayers, seated int64
+		query := `SELECT e.join_mode, b.max_players, (
+			SELECT COUNT(*) FROM participants p WHERE p.boardgame_id = b.id AND p.waitlisted = FALSE
+		) FROM boardgames b JOIN events e ON e.id = b.event_id
+		WHERE b.id = @boardgame_id AND b.event_id = @event_id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"boardgame_id": boardgameID,
+				"event_id":     eventID,
+			},
+		).Scan(&joinMode, &maxPlayers, &seated); err != nil {
+			return ParseError(err)
+		}
+
+		query = `SELECT id FROM participants WHERE boardgame_id = @boardgame_id AND user_id = @user_id;`
+		err := d.txQueryRow(tx, query,
+			map[string]any{
+				"boardgame_id": boardgameID,
+				"user_id":      userID,
+			},
+		).Scan(&participantID)
+		if err == nil {
+			// already at this table or on its waitlist, keep the position
+			return nil
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		left := []int64{}
+		if joinMode != models.JoinMultiple {
+			if left, err = d.deleteParticipant(tx, eventID, nil, userID); err != nil {
+				return err
+			}
+		}
+
+		query = `INSERT INTO participants (event_id, boardgame_id, user_id, user_name, waitlisted) VALUES (@event_id, @boardgame_id, @user_id, @user_name, @waitlisted) RETURNING id;`
+		if err = d.txQueryRow(tx, query,
+			map[string]any{
+				"event_id":     eventID,
+				"boardgame_id": boardgameID,
+				"user_id":      userID,
+				"user_name":    userName,
+				"waitlisted":   maxPlayers != -1 && seated >= maxPlayers,
+			},
+		).Scan(&participantID); err != nil {
+			return err
+		}
+
+		for _, boardGameID := range left {
+			if err = d.promote(tx, boardGameID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
 		return 0, err
 	}
 
 	return participantID, nil
 }
 
+// RemoveParticipant removes the user from every game of the event, giving
+// their seats to the first ones on the waitlists.
 func (d *Database) RemoveParticipant(eventID string, userID int64) error {
-	query := `DELETE FROM participants WHERE event_id = @event_id AND user_id = @user_id;`
-
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
-			"event_id": eventID,
-			"user_id":  userID,
-		})...,
-	); err != nil {
-		return err
-	}
+	return d.removeParticipant(eventID, nil, userID)
+}
 
-	return nil
+// RemoveParticipantFromBoardGame removes the user from a single game, giving
+// their seat to the first one on its waitlist.
+func (d *Database) RemoveParticipantFromBoardGame(eventID string, boardgameID, userID int64) error {
+	return d.removeParticipant(eventID, &boardgameID, userID)
 }
 
-func (d *Database) InsertChat(chatID int64, language string) error {
-	query := `
-		INSERT INTO chats (chat_id, language) 
-		VALUES (@chat_id, @language)
-		ON CONFLICT (chat_id) 
-		DO UPDATE SET language = EXCLUDED.language;
-	`
+func (d *Database) removeParticipant(eventID string, boardgameID *int64, userID int64) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		left, err := d.deleteParticipant(tx, eventID, boardgameID, userID)
+		if err != nil {
+			return err
+		}
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
-			"chat_id":  chatID,
-			"language": language,
-		})...,
-	); err != nil {
-		return err
-	}
+		for _, boardGameID := range left {
+			if err = d.promote(tx, boardGameID); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (d *Database) GetPreferredLanguage(chatID int64) string {
-	query := `SELECT language FROM chats WHERE chat_id = @chat_id;`
+// deleteParticipant removes the user from a game of the event, or from all of
+// them when boardgameID is nil, and returns the ids of the games left.
+func (d *Database) deleteParticipant(tx *sql.Tx, eventID string, boardgameID *int64, userID int64) ([]int64, error) {
+	query := `DELETE FROM participants
+	WHERE event_id = @event_id AND user_id = @user_id AND (@boardgame_id IS NULL OR boardgame_id = @boardgame_id)
+	RETURNING boardgame_id;`
 
-	var language string
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"chat_id": chatID,
-		})...,
-	).Scan(&language); err != nil {
-		return "en"
+	rows, err := d.txQuery(tx, query,
+		map[string]any{
+			"event_id":     eventID,
+			"user_id":      userID,
+			"boardgame_id": boardgameID,
+		},
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return language
-}
+	defer rows.Close()
 
-func IntOrNil(i pgtype.Int8) *int64 {
-	if i.Valid {
-		v := i.Int64
-		return &v
+	left := []int64{}
+	for rows.Next() {
+		var boardGameID int64
+		if err = rows.Scan(&boardGameID); err != nil {
+			return nil, err
+		}
+
+		left = append(left, boardGameID)
 	}
 
-	return nil
+	return left, rows.Err()
 }
 
-func StringOrNil(i pgtype.Text) *string {
-	if i.Valid {
-		v := i.String
-		return &v
+// promote seats the first players of the waitlist of a game while there are
+// free seats at its table.
+func (d *Database) promote(tx *sql.Tx, boardgameID int64) error {
+	if err := d.lockBoardGame(tx, boardgameID); err != nil {
+		return err
 	}
 
-	return nil
-}
+	var maxPlayers, seated int64
+	query := `SELECT b.max_players, (
+		SELECT COUNT(*) FROM participants p WHERE p.boardgame_id = b.id AND p.waitlisted = FALSE
+	) FROM boardgames b WHERE b.id = @boardgame_id;`
 
-func ParseError(err error) error {
-	if err.Error() == "sql: no rows in result set" {
-		return ErrNoRows
+	if err := d.txQueryRow(tx, query,
+		map[string]any{
+			"boardgame_id": boardgameID,
+		},
+	).Scan(&maxPlayers, &seated); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	free := maxPlayers - seated
+	if maxPlayers == -1 {
+		free = math.MaxInt32
+	}
+
+	if free <= 0 {
+		return nil
 	}
 
+	query = `UPDATE participants SET waitlisted = FALSE WHERE id IN (
+		SELECT id FROM participants WHERE boardgame_id = @boardgame_id AND waitlisted = TRUE ORDER BY id LIMIT @free
+	);`
+
+	_, err := d.txExec(tx, query,
+		map[string]any{
+			"boardgame_id": boardgameID,
+			"free":         free,
+		},
+	)
+
 	return err
-}package database
+}
 
-import (
-	"boardgame-night-bot/src/models"
-	"database/sql"
-	"errors"
-	"log"
-	"path/filepath"
-	"sort"
-	"strings"
+func (d *Database) InsertAuditEntry(entry models.AuditEntry) error {
+	query := `INSERT INTO audit_log (event_id, user_id, user_name, action, target, before_value, after_value, source, created_at)
+	VALUES (@event_id, @user_id, @user_name, @action, @target, @before_value, @after_value, @source, @created_at);`
 
-	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
-	_ "github.com/mattn/go-sqlite3"
-)
+	createdAt := entry.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
 
-type Database struct {
-	db *sql.DB
+	if _, err := d.exec(query,
+		map[string]any{
+			"event_id":     entry.EventID,
+			"user_id":      entry.UserID,
+			"user_name":    entry.UserName,
+			"action":       entry.Action,
+			"target":       entry.Target,
+			"before_value": entry.Before,
+			"after_value":  entry.After,
+			"source":       entry.Source,
+			"created_at":   createdAt.UTC(),
+		},
+	); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-var ErrNoRows = errors.New("sql: no rows in result set")
+// SelectAuditLog returns the latest entries of an event, newest first.
+func (d *Database) SelectAuditLog(eventID string, limit int) ([]models.AuditEntry, error) {
+	query := `SELECT id, event_id, user_id, user_name, action, target, before_value, after_value, source, created_at
+	FROM audit_log
+	WHERE event_id = @event_id
+	ORDER BY id DESC
+	LIMIT @limit;`
 
-func NewDatabase(path string) *Database {
-	db, err := sql.Open("sqlite3", filepath.Join(path, "bot_data.sqlite"))
+	rows, err := d.query(query,
+		map[string]any{
+			"event_id": eventID,
+			"limit":    limit,
+		},
+	)
 	if err != nil {
-		log.Fatal("failed to open database '"+filepath.Join(path, "bot_data.sqlite")+"':", err)
+		return nil, err
 	}
 
-	return &Database{db}
-}
+	defer rows.Close()
 
-func NamedArgs(arg map[string]any) []any {
-	args := make([]any, 0, len(arg))
-	for k, v := range arg {
-		args = append(args, sql.Named(k, v))
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var userID pgtype.Int8
+		var userName, target, before, after pgtype.Text
+
+		if err = rows.Scan(
+			&entry.ID,
+			&entry.EventID,
+			&userID,
+			&userName,
+			&entry.Action,
+			&target,
+			&before,
+			&after,
+			&entry.Source,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if userID.Valid {
+			entry.UserID = userID.Int64
+		}
+		if userName.Valid {
+			entry.UserName = userName.String
+		}
+		entry.Target = StringOrNil(target)
+		entry.Before = StringOrNil(before)
+		entry.After = StringOrNil(after)
+
+		entries = append(entries, entry)
 	}
 
-	return args
+	return entries, rows.Err()
 }
 
-func (d *Database) CreateTables() {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS chats (
-			chat_id INTEGER NOT NULL,
-			language TEXT NOT NULL DEFAULT 'en',
-			PRIMARY KEY(chat_id)
-			UNIQUE(chat_id) ON CONFLICT REPLACE
-		);`,
-		`CREATE TABLE IF NOT EXISTS events (
-			id TEXT PRIMARY KEY,
-			chat_id INTEGER,
-			user_id INTEGER,
-			user_name TEXT,
-			name TEXT,
-			message_id INTEGER,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);`,
-		`CREATE TABLE IF NOT EXISTS boardgames (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			event_id INTEGER,
-			name TEXT,
-			max_players INTEGER,
-			message_id INTEGER,
-			bgg_id INTEGER,
-			bgg_name TEXT,
-			bgg_url TEXT,
-			bgg_image_url TEXT,
-			FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE
-		);`,
-		`CREATE TABLE IF NOT EXISTS participants (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			event_id INTEGER,
-			boardgame_id INTEGER,
-			user_id INTEGER,
-			user_name TEXT,
-			FOREIGN KEY(event_id) REFERENCES boardgames(events) ON DELETE CASCADE,
-			FOREIGN KEY(boardgame_id) REFERENCES boardgames(id) ON DELETE CASCADE,
-			UNIQUE(event_id, user_id) ON CONFLICT REPLACE
-		);`,
-	}
-
-	for _, query := range queries {
-		_, err := d.db.Exec(query)
-		if err != nil {
-			log.Fatal(err)
+// SelectScheduledEvents returns the open and locked events with a start
+// time, without their games. Times are compared by the caller as SQLite keeps
+// them as text.
+func (d *Database) SelectScheduledEvents() ([]models.Event, error) {
+	query := `SELECT id, name, chat_id, message_id, user_id, user_name, starts_at, status
+	FROM events
+	WHERE starts_at IS NOT NULL AND status IN (@open, @locked);`
+
+	rows, err := d.query(query, map[string]any{
+		"open":   models.StatusOpen,
+		"locked": models.StatusLocked,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		var messageID pgtype.Int8
+		var userName pgtype.Text
+		var startsAt sql.NullTime
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.Name,
+			&event.ChatID,
+			&messageID,
+			&event.UserID,
+			&userName,
+			&startsAt,
+			&event.Status,
+		); err != nil {
+			return nil, err
 		}
+
+		event.MessageID = IntOrNil(messageID)
+		if userName.Valid {
+			event.UserName = userName.String
+		}
+		event.StartsAt = TimeOrNil(startsAt)
+
+		events = append(events, event)
 	}
 
-	log.Println("database tables ensured")
+	return events, rows.Err()
 }
 
-func (d *Database) Close() {
-	d.db.Close()
-	log.Println("database connection closed")
+// ClaimReminder records that a reminder is being sent, it reports false when
+// it was already sent for the same start time of the event.
+func (d *Database) ClaimReminder(eventID string, startsAt time.Time, offset time.Duration) (bool, error) {
+	query := `INSERT INTO reminders (event_id, starts_at, offset_minutes, sent_at)
+	VALUES (@event_id, @starts_at, @offset_minutes, @sent_at)
+	ON CONFLICT (event_id, starts_at, offset_minutes) DO NOTHING;`
+
+	res, err := d.exec(query,
+		map[string]any{
+			"event_id":       eventID,
+			"starts_at":      startsAt.Unix(),
+			"offset_minutes": int64(offset / time.Minute),
+			"sent_at":        time.Now().UTC(),
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
 }
 
-func (d *Database) InsertEvent(chatID, userID int64, userName, name string, messageID *int64) (string, error) {
-	var eventID string
-	query := `INSERT INTO events (id, chat_id, user_id, user_name, name, message_id) VALUES (@event_id, @chat_id, @user_id, @user_name, @name, @message_id) RETURNING id;`
+func (d *Database) UpsertUser(user models.User) error {
+	query := `INSERT INTO users (user_id, user_name, chat_id, reminders, notify_full, notify_deleted, notify_max_players, notify_seat, updated_at)
+	VALUES (@user_id, @user_name, @chat_id, @reminders, @notify_full, @notify_deleted, @notify_max_players, @notify_seat, @updated_at)
+	ON CONFLICT (user_id)
+	DO UPDATE SET user_name = EXCLUDED.user_name, chat_id = EXCLUDED.chat_id, reminders = EXCLUDED.reminders,
+	notify_full = EXCLUDED.notify_full, notify_deleted = EXCLUDED.notify_deleted, notify_max_players = EXCLUDED.notify_max_players,
+	notify_seat = EXCLUDED.notify_seat, updated_at = EXCLUDED.updated_at;`
+
+	if _, err := d.exec(query,
+		map[string]any{
+			"user_id":            user.ID,
+			"user_name":          user.UserName,
+			"chat_id":            user.ChatID,
+			"reminders":          user.Reminders,
+			"notify_full":        user.NotifyFull,
+			"notify_deleted":     user.NotifyDeleted,
+			"notify_max_players": user.NotifyMaxPlayers,
+			"notify_seat":        user.NotifySeat,
+			"updated_at":         time.Now().UTC(),
+		},
+	); err != nil {
+		return err
+	}
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"event_id":   uuid.New().String(),
-			"chat_id":    chatID,
-			"user_id":    userID,
-			"user_name":  userName,
-			"name":       name,
-			"message_id": messageID,
-		})...,
-	).Scan(&eventID); err != nil {
-		return "", err
+	return nil
+}
+
+func (d *Database) SelectUser(userID int64) (*models.User, error) {
+	query := `SELECT user_id, user_name, chat_id, reminders, notify_full, notify_deleted, notify_max_players, notify_seat
+	FROM users WHERE user_id = @user_id;`
+
+	var user models.User
+	var userName pgtype.Text
+	var chatID pgtype.Int8
+
+	if err := d.queryRow(query,
+		map[string]any{
+			"user_id": userID,
+		},
+	).Scan(&user.ID, &userName, &chatID, &user.Reminders, &user.NotifyFull, &user.NotifyDeleted, &user.NotifyMaxPlayers, &user.NotifySeat); err != nil {
+		return nil, ParseError(err)
 	}
 
-	return eventID, nil
+	if userName.Valid {
+		user.UserName = userName.String
+	}
+	user.ChatID = IntOrNil(chatID)
+
+	return &user, nil
 }
 
-func (d *Database) SelectEvent(chatID int64) (*models.Event, error) {
-	query := `
-	SELECT 
-	e.id, 
-	e.name, 
-	e.chat_id,
-	e.message_id,
-	e.user_id,
-	b.id,
-	b.name,
-	b.max_players,
-	b.bgg_id,
-	b.bgg_name,
-	b.bgg_url,
-	b.bgg_image_url,
-	p.id,
-	p.user_id,
-	p.user_name
-	FROM events e
-	LEFT JOIN boardgames b ON e.id = b.event_id
-	LEFT JOIN participants p ON b.id = p.boardgame_id
-	WHERE e.id = (SELECT id FROM events WHERE chat_id = @chat_id ORDER BY created_at DESC LIMIT 1);`
-	return d.selectEventByQuery(query, map[string]any{"chat_id": chatID})
+func (d *Database) InsertSeries(series models.Series) (int64, error) {
+	var seriesID int64
+
+	err := d.withTx(func(tx *sql.Tx) error {
+		query := `INSERT INTO series (chat_id, user_id, user_name, name, frequency, weekday, day, time, location, locked, status)
+		VALUES (@chat_id, @user_id, @user_name, @name, @frequency, @weekday, @day, @time, @location, @locked, @status) RETURNING id;`
+
+		var weekday *int64
+		if series.Weekday != nil {
+			w := int64(*series.Weekday)
+			weekday = &w
+		}
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"chat_id":   series.ChatID,
+				"user_id":   series.UserID,
+				"user_name": series.UserName,
+				"name":      series.Name,
+				"frequency": series.Frequency,
+				"weekday":   weekday,
+				"day":       series.Day,
+				"time":      series.Time,
+				"location":  series.Location,
+				"locked":    series.Locked,
+				"status":    models.SeriesActive,
+			},
+		).Scan(&seriesID); err != nil {
+			return err
+		}
+
+		for i, game := range series.Games {
+			if _, err := d.txExec(tx, `INSERT INTO series_games (series_id, position, name) VALUES (@series_id, @position, @name);`,
+				map[string]any{
+					"series_id": seriesID,
+					"position":  i,
+					"name":      game,
+				},
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return seriesID, err
 }
 
-func (d *Database) SelectEventByEventID(eventID string) (*models.Event, error) {
-	query := `
-	SELECT 
-	e.id, 
-	e.name, 
-	e.chat_id,
-	e.message_id,
-	e.user_id,
-	b.id,
-	b.name,
-	b.max_players,
-	b.bgg_id,
-	b.bgg_name,
-	b.bgg_url,
-	b.bgg_image_url,
-	p.id,
-	p.user_id,
-	p.user_name
-	FROM events e
-	LEFT JOIN boardgames b ON e.id = b.event_id
-	LEFT JOIN participants p ON b.id = p.boardgame_id
-	WHERE e.id = @id;`
-	return d.selectEventByQuery(query, map[string]any{"id": eventID})
+const selectSeriesQuery = `SELECT s.id, s.chat_id, s.user_id, s.user_name, s.name, s.frequency, s.weekday, s.day, s.time, s.location, s.locked, s.status, s.last_starts_at, g.name
+	FROM series s
+	LEFT JOIN series_games g ON g.series_id = s.id`
+
+// SelectSeries returns the series of a chat which are not stopped, oldest
+// first.
+func (d *Database) SelectSeries(chatID int64) ([]models.Series, error) {
+	return d.selectSeriesByQuery(selectSeriesQuery+` WHERE s.chat_id = @chat_id AND s.status <> @stopped ORDER BY s.id, g.position;`,
+		map[string]any{
+			"chat_id": chatID,
+			"stopped": models.SeriesStopped,
+		},
+	)
 }
 
-func (d *Database) selectEventByQuery(query string, args map[string]any) (*models.Event, error) {
-	rows, err := d.db.Query(query, NamedArgs(args)...)
+// SelectActiveSeries returns the series of every chat that create events.
+func (d *Database) SelectActiveSeries() ([]models.Series, error) {
+	return d.selectSeriesByQuery(selectSeriesQuery+` WHERE s.status = @active ORDER BY s.id, g.position;`,
+		map[string]any{
+			"active": models.SeriesActive,
+		},
+	)
+}
+
+func (d *Database) SelectSeriesByID(ID int64) (*models.Series, error) {
+	series, err := d.selectSeriesByQuery(selectSeriesQuery+` WHERE s.id = @id ORDER BY g.position;`,
+		map[string]any{
+			"id": ID,
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
+	if len(series) == 0 {
+		return nil, ErrNoRows
+	}
 
-	boardGameMap := make(map[int64]*models.BoardGame)
+	return &series[0], nil
+}
 
-	event := &models.Event{}
-	for rows.Next() {
-		var boardGame models.BoardGame
-		var participant models.Participant
+func (d *Database) selectSeriesByQuery(query string, args map[string]any) ([]models.Series, error) {
+	rows, err := d.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
 
-		var eventMessageID, boardGameID, boardGameMaxPlayers, participantID, participantUserID, bggID pgtype.Int8
-		var boardGameName, participantUserName, bggName, bggUrl, bggImageUrl pgtype.Text
+	series := []models.Series{}
+	for rows.Next() {
+		var s models.Series
+		var userName, location, game pgtype.Text
+		var weekday, lastStartsAt pgtype.Int8
 
 		if err := rows.Scan(
-			&event.ID,
-			&event.Name,
-			&event.ChatID,
-			&eventMessageID,
-			&event.UserID,
-			&boardGameID,
-			&boardGameName,
-			&boardGameMaxPlayers,
-			&bggID,
-			&bggName,
-			&bggUrl,
-			&bggImageUrl,
-			&participantID,
-			&participantUserID,
-			&participantUserName,
+			&s.ID,
+			&s.ChatID,
+			&s.UserID,
+			&userName,
+			&s.Name,
+			&s.Frequency,
+			&weekday,
+			&s.Day,
+			&s.Time,
+			&location,
+			&s.Locked,
+			&s.Status,
+			&lastStartsAt,
+			&game,
 		); err != nil {
 			return nil, err
 		}
 
-		event.MessageID = IntOrNil(eventMessageID)
-		event.Locked = strings.Contains(event.Name, "🔒")
-
-		if IntOrNil(boardGameID) != nil {
-			boardGame = models.BoardGame{
-				ID:          *IntOrNil(boardGameID),
-				Name:        *StringOrNil(boardGameName),
-				MaxPlayers:  *IntOrNil(boardGameMaxPlayers),
-				BggID:       IntOrNil(bggID),
-				BggName:     StringOrNil(bggName),
-				BggUrl:      StringOrNil(bggUrl),
-				BggImageUrl: StringOrNil(bggImageUrl),
+		if len(series) == 0 || series[len(series)-1].ID != s.ID {
+			if userName.Valid {
+				s.UserName = userName.String
 			}
-
-			if _, ok := boardGameMap[boardGame.ID]; !ok {
-				boardGameMap[boardGame.ID] = &boardGame
+			if weekday.Valid {
+				w := time.Weekday(weekday.Int64)
+				s.Weekday = &w
 			}
-		}
-
-		if IntOrNil(participantID) != nil {
-			participant = models.Participant{
-				ID:       *IntOrNil(participantID),
-				UserID:   *IntOrNil(participantUserID),
-				UserName: *StringOrNil(participantUserName),
+			s.Location = StringOrNil(location)
+			if lastStartsAt.Valid {
+				t := time.Unix(lastStartsAt.Int64, 0)
+				s.LastStartsAt = &t
 			}
+			s.Games = []string{}
+
+			series = append(series, s)
+		}
 
-			boardGameMap[boardGame.ID].Participants = append(boardGameMap[boardGame.ID].Participants, participant)
+		if game.Valid {
+			last := &series[len(series)-1]
+			last.Games = append(last.Games, game.String)
 		}
 	}
 
-	if rows.Err() != nil {
-		return nil, rows.Err()
+	return series, rows.Err()
+}
+
+func (d *Database) UpdateSeriesStatus(ID int64, status models.SeriesStatus) error {
+	query := `UPDATE series SET status = @status WHERE id = @id RETURNING id;`
+
+	var seriesID int64
+	if err := d.queryRow(query,
+		map[string]any{
+			"id":     ID,
+			"status": status,
+		},
+	).Scan(&seriesID); err != nil {
+		return ParseError(err)
 	}
 
-	for _, boardGame := range boardGameMap {
-		sort.SliceStable(boardGame.Participants, func(i, j int) bool {
-			return boardGame.Participants[i].UserName < boardGame.Participants[j].UserName
-		})
+	return nil
+}
 
-		event.BoardGames = append(event.BoardGames, *boardGame)
+// ClaimSeriesEvent records that the event of the series starting at the
+// given time is being created, it reports false when it already was.
+func (d *Database) ClaimSeriesEvent(ID int64, startsAt time.Time) (bool, error) {
+	query := `UPDATE series SET last_starts_at = @starts_at
+	WHERE id = @id AND status = @active AND (last_starts_at IS NULL OR last_starts_at < @starts_at);`
+
+	res, err := d.exec(query,
+		map[string]any{
+			"id":        ID,
+			"starts_at": startsAt.Unix(),
+			"active":    models.SeriesActive,
+		},
+	)
+	if err != nil {
+		return false, err
 	}
 
-	sort.SliceStable(event.BoardGames, func(i, j int) bool {
-		return event.BoardGames[i].Name < event.BoardGames[j].Name || (event.BoardGames[i].Name == event.BoardGames[j].Name && event.BoardGames[i].ID < event.BoardGames[j].ID)
-	})
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
 
-	return event, nil
+	return affected > 0, nil
 }
 
-func (d *Database) UpdateEventMessageID(eventID string, messageID int64) error {
-	query := `UPDATE events SET message_id = @message_id where id = @event_id;`
+// LinkBGGAccount stores the BoardGameGeek account of a user, the collection
+// of the previous account is dropped when it changes. The chat where the
+// account was linked, if any, shares its collection.
+func (d *Database) LinkBGGAccount(account models.BGGAccount, chatID *int64) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `DELETE FROM collections WHERE user_id = @user_id
+		AND EXISTS (SELECT 1 FROM bgg_accounts WHERE user_id = @user_id AND bgg_username <> @bgg_username);`
+
+		if _, err := d.txExec(tx, query,
+			map[string]any{
+				"user_id":      account.UserID,
+				"bgg_username": account.BGGUsername,
+			},
+		); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO bgg_accounts (user_id, user_name, bgg_username, synced_at, updated_at)
+		VALUES (@user_id, @user_name, @bgg_username, NULL, @updated_at)
+		ON CONFLICT (user_id)
+		DO UPDATE SET user_name = EXCLUDED.user_name, bgg_username = EXCLUDED.bgg_username, updated_at = EXCLUDED.updated_at,
+		synced_at = CASE WHEN bgg_accounts.bgg_username = EXCLUDED.bgg_username THEN bgg_accounts.synced_at ELSE NULL END;`
+
+		if _, err := d.txExec(tx, query,
+			map[string]any{
+				"user_id":      account.UserID,
+				"user_name":    account.UserName,
+				"bgg_username": account.BGGUsername,
+				"updated_at":   time.Now().UTC(),
+			},
+		); err != nil {
+			return err
+		}
+
+		if chatID == nil {
+			return nil
+		}
+
+		query = `INSERT INTO bgg_account_chats (user_id, chat_id) VALUES (@user_id, @chat_id)
+		ON CONFLICT (user_id, chat_id) DO NOTHING;`
+
+		_, err := d.txExec(tx, query,
+			map[string]any{
+				"user_id": account.UserID,
+				"chat_id": *chatID,
+			},
+		)
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
-			"event_id":   eventID,
-			"message_id": messageID,
-		})...,
-	); err != nil {
 		return err
+	})
+}
+
+// UnlinkBGGAccount drops the BoardGameGeek account of a user and their
+// collection.
+func (d *Database) UnlinkBGGAccount(userID int64) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `DELETE FROM bgg_accounts WHERE user_id = @user_id RETURNING user_id;`
+
+		var id int64
+		if err := d.txQueryRow(tx, query, map[string]any{"user_id": userID}).Scan(&id); err != nil {
+			return ParseError(err)
+		}
+
+		for _, query := range []string{
+			`DELETE FROM bgg_account_chats WHERE user_id = @user_id;`,
+			`DELETE FROM collections WHERE user_id = @user_id;`,
+		} {
+			if _, err := d.txExec(tx, query, map[string]any{"user_id": userID}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+const selectBGGAccountQuery = `SELECT user_id, user_name, bgg_username, synced_at FROM bgg_accounts `
+
+func (d *Database) SelectBGGAccount(userID int64) (*models.BGGAccount, error) {
+	accounts, err := d.selectBGGAccountsByQuery(selectBGGAccountQuery+`WHERE user_id = @user_id;`,
+		map[string]any{
+			"user_id": userID,
+		},
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if len(accounts) == 0 {
+		return nil, ErrNoRows
+	}
+
+	return &accounts[0], nil
 }
 
-func (d *Database) InsertBoardGame(eventID string, name string, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) (int64, error) {
-	var boardGameID int64
-	query := `INSERT INTO boardgames (event_id, name, max_players, bgg_id, bgg_name, bgg_url, bgg_image_url) VALUES (@event_id, @name, @max_players, @bgg_id, @bgg_name, @bgg_url, @bgg_image_url) RETURNING id;`
+// SelectBGGAccountsToSync returns the accounts whose collection was never
+// fetched or was fetched before the given time, the oldest first.
+func (d *Database) SelectBGGAccountsToSync(before time.Time) ([]models.BGGAccount, error) {
+	return d.selectBGGAccountsByQuery(selectBGGAccountQuery+`WHERE synced_at IS NULL OR synced_at < @before ORDER BY synced_at IS NOT NULL, synced_at;`,
+		map[string]any{
+			"before": before.Unix(),
+		},
+	)
+}
 
-	if bggImageUrl != nil && *bggImageUrl == "" {
-		// Fix for BGG image URLs that contains a filter with mandatory (png)
-		tmp := *bggImageUrl
-		tmp = strings.ReplaceAll(tmp, "%28", "(")
-		tmp = strings.ReplaceAll(tmp, "%29", ")")
-		bggImageUrl = &tmp
+func (d *Database) selectBGGAccountsByQuery(query string, args map[string]any) ([]models.BGGAccount, error) {
+	rows, err := d.query(query, args)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"event_id":      eventID,
-			"name":          name,
-			"max_players":   maxPlayers,
-			"bgg_id":        bggID,
-			"bgg_url":       bggUrl,
-			"bgg_name":      bggName,
-			"bgg_image_url": bggImageUrl,
-		})...,
-	).Scan(&boardGameID); err != nil {
-		return 0, err
+	defer rows.Close()
+
+	accounts := []models.BGGAccount{}
+	for rows.Next() {
+		var account models.BGGAccount
+		var userName pgtype.Text
+		var syncedAt pgtype.Int8
+
+		if err := rows.Scan(&account.UserID, &userName, &account.BGGUsername, &syncedAt); err != nil {
+			return nil, err
+		}
+
+		if userName.Valid {
+			account.UserName = userName.String
+		}
+		if syncedAt.Valid {
+			t := time.Unix(syncedAt.Int64, 0)
+			account.SyncedAt = &t
+		}
+
+		accounts = append(accounts, account)
 	}
 
-	return boardGameID, nil
+	return accounts, rows.Err()
 }
 
-func (d *Database) UpdateBoardGameMessageID(boardgameID, messageID int64) error {
-	query := `UPDATE boardgames SET message_id = @message_id where id = @boardgame_id;`
+// ReplaceCollection stores the games owned by a user, replacing the ones
+// fetched before.
+func (d *Database) ReplaceCollection(userID int64, games []models.CollectionGame, syncedAt time.Time) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `UPDATE bgg_accounts SET synced_at = @synced_at WHERE user_id = @user_id RETURNING user_id;`
+
+		var id int64
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"user_id":   userID,
+				"synced_at": syncedAt.Unix(),
+			},
+		).Scan(&id); err != nil {
+			return ParseError(err)
+		}
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
-			"boardgame_id": boardgameID,
-			"message_id":   messageID,
-		})...,
-	); err != nil {
-		return err
+		if _, err := d.txExec(tx, `DELETE FROM collections WHERE user_id = @user_id;`, map[string]any{"user_id": userID}); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO collections (user_id, bgg_id, name, year) VALUES (@user_id, @bgg_id, @name, @year)
+		ON CONFLICT (user_id, bgg_id) DO NOTHING;`
+
+		for _, game := range games {
+			if _, err := d.txExec(tx, query,
+				map[string]any{
+					"user_id": userID,
+					"bgg_id":  game.BGGID,
+					"name":    game.Name,
+					"year":    game.Year,
+				},
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// chatMembersQuery selects the users of a chat: the ones who linked their
+// account there and the ones who joined one of its events.
+const chatMembersQuery = `SELECT user_id FROM bgg_account_chats WHERE chat_id = @chat_id
+	UNION SELECT p.user_id FROM participants p JOIN events e ON e.id = p.event_id WHERE e.chat_id = @chat_id`
+
+// SelectChatCollection returns the games owned by the users of a chat.
+func (d *Database) SelectChatCollection(chatID int64) ([]models.CollectionGame, error) {
+	query := `SELECT c.user_id, a.user_name, c.bgg_id, c.name, c.year
+	FROM collections c
+	JOIN bgg_accounts a ON a.user_id = c.user_id
+	WHERE c.user_id IN (` + chatMembersQuery + `)
+	ORDER BY c.name, a.user_name;`
+
+	rows, err := d.query(query, map[string]any{"chat_id": chatID})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	defer rows.Close()
+
+	games := []models.CollectionGame{}
+	for rows.Next() {
+		var game models.CollectionGame
+		var userName pgtype.Text
+		var year pgtype.Int8
+
+		if err := rows.Scan(&game.UserID, &userName, &game.BGGID, &game.Name, &year); err != nil {
+			return nil, err
+		}
+
+		if userName.Valid {
+			game.UserName = userName.String
+		}
+		game.Year = int(year.Int64)
+
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
 }
 
-func (d *Database) UpdateBoardGamePlayerNumber(messageID int64, maxPlayers int) error {
-	var boardGameID int64
+// selectOwners fills the owners of the games of the event among its
+// participants.
+func (d *Database) selectOwners(event *models.Event) error {
+	query := `SELECT DISTINCT bg.id, a.user_name
+	FROM boardgames bg
+	JOIN collections c ON c.bgg_id = bg.bgg_id
+	JOIN bgg_accounts a ON a.user_id = c.user_id
+	WHERE bg.event_id = @event_id AND c.user_id IN (SELECT user_id FROM participants WHERE event_id = @event_id)
+	ORDER BY a.user_name;`
+
+	rows, err := d.query(query, map[string]any{"event_id": event.ID})
+	if err != nil {
+		return err
+	}
 
-	query := `UPDATE boardgames SET max_players = @max_players where message_id = @message_id RETURNING id;`
+	defer rows.Close()
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players": maxPlayers,
-			"message_id":  messageID,
-		})...,
-	).Scan(&boardGameID); err != nil {
-		return ParseError(err)
+	owners := map[int64][]string{}
+	for rows.Next() {
+		var boardGameID int64
+		var userName pgtype.Text
+
+		if err := rows.Scan(&boardGameID, &userName); err != nil {
+			return err
+		}
+
+		owners[boardGameID] = append(owners[boardGameID], userName.String)
 	}
 
-	return nil
+	for i := range event.BoardGames {
+		event.BoardGames[i].Owners = owners[event.BoardGames[i].ID]
+	}
+
+	return rows.Err()
 }
 
-func (d *Database) UpdateBoardGameBGGInfo(messageID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
-	var boardGameID int64
+// selectOrganizers fills the co-organizers of the event, by name.
+func (d *Database) selectOrganizers(event *models.Event) error {
+	query := `SELECT user_id, user_name FROM event_organizers WHERE event_id = @event_id ORDER BY user_name, user_id;`
 
-	query := `UPDATE boardgames 
-	SET 
-	max_players = @max_players,
-	bgg_id = @bgg_id,
-	bgg_name = @bgg_name,
-	bgg_url = @bgg_url,
-	bgg_image_url = @bgg_image_url
-	WHERE message_id = @message_id RETURNING id;`
-
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players":   maxPlayers,
-			"message_id":    messageID,
-			"bgg_id":        bggID,
-			"bgg_name":      bggName,
-			"bgg_url":       bggUrl,
-			"bgg_image_url": bggImageUrl,
-		})...,
-	).Scan(&boardGameID); err != nil {
-		return ParseError(err)
+	rows, err := d.query(query, map[string]any{"event_id": event.ID})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	defer rows.Close()
+
+	event.Organizers = []models.Organizer{}
+	for rows.Next() {
+		var organizer models.Organizer
+		var userName pgtype.Text
+
+		if err := rows.Scan(&organizer.UserID, &userName); err != nil {
+			return err
+		}
+
+		organizer.UserName = userName.String
+		event.Organizers = append(event.Organizers, organizer)
+	}
+
+	return rows.Err()
 }
 
-func (d *Database) UpdateBoardGameBGGInfoByID(ID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
-	query := `UPDATE boardgames 
-	SET 
-	max_players = @max_players,
-	bgg_id = @bgg_id,
-	bgg_name = @bgg_name,
-	bgg_url = @bgg_url,
-	bgg_image_url = @bgg_image_url
-	WHERE id = @id RETURNING id;`
-
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"max_players":   maxPlayers,
-			"id":            ID,
-			"bgg_id":        bggID,
-			"bgg_name":      bggName,
-			"bgg_url":       bggUrl,
-			"bgg_image_url": bggImageUrl,
-		})...,
-	).Scan(&ID); err != nil {
+// InsertEventOrganizer names the user co-organizer of the event, updating
+// their name when they already are.
+func (d *Database) InsertEventOrganizer(eventID string, userID int64, userName string) error {
+	query := `INSERT INTO event_organizers (event_id, user_id, user_name)
+	VALUES (@event_id, @user_id, @user_name)
+	ON CONFLICT (event_id, user_id)
+	DO UPDATE SET user_name = EXCLUDED.user_name;`
+
+	_, err := d.exec(query,
+		map[string]any{
+			"event_id":  eventID,
+			"user_id":   userID,
+			"user_name": userName,
+		},
+	)
+
+	return err
+}
+
+// DeleteEventOrganizer removes the user from the co-organizers, it fails with
+// ErrNoRows when they were not one.
+func (d *Database) DeleteEventOrganizer(eventID string, userID int64) error {
+	query := `DELETE FROM event_organizers WHERE event_id = @event_id AND user_id = @user_id RETURNING user_id;`
+
+	var id int64
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id": eventID,
+			"user_id":  userID,
+		},
+	).Scan(&id); err != nil {
 		return ParseError(err)
 	}
 
 	return nil
 }
 
-func (d *Database) DeleteBoardGameByID(ID int64) error {
-	query := `DELETE FROM boardgames WHERE id = @id;`
+// InsertPoll starts the vote on the games of an event, replacing a closed
+// vote and its ballots.
+func (d *Database) InsertPoll(poll models.Poll) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		if _, err := d.txExec(tx, `DELETE FROM votes WHERE event_id = @event_id;`, map[string]any{"event_id": poll.EventID}); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO polls (event_id, method, winners, status, message_id)
+		VALUES (@event_id, @method, @winners, @status, NULL)
+		ON CONFLICT (event_id)
+		DO UPDATE SET method = EXCLUDED.method, winners = EXCLUDED.winners, status = EXCLUDED.status, message_id = NULL;`
+
+		_, err := d.txExec(tx, query,
+			map[string]any{
+				"event_id": poll.EventID,
+				"method":   poll.Method,
+				"winners":  poll.Winners,
+				"status":   models.PollOpen,
+			},
+		)
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
-			"id": ID,
-		})...,
-	); err != nil {
 		return err
+	})
+}
+
+// SelectPoll returns the vote of an event with every ballot.
+func (d *Database) SelectPoll(eventID string) (*models.Poll, error) {
+	query := `SELECT event_id, method, winners, status, message_id FROM polls WHERE event_id = @event_id;`
+
+	var poll models.Poll
+	var messageID pgtype.Int8
+
+	if err := d.queryRow(query, map[string]any{"event_id": eventID}).Scan(&poll.EventID, &poll.Method, &poll.Winners, &poll.Status, &messageID); err != nil {
+		return nil, ParseError(err)
 	}
 
-	return nil
+	poll.MessageID = IntOrNil(messageID)
+
+	query = `SELECT user_id, user_name, boardgame_id, rank FROM votes WHERE event_id = @event_id ORDER BY user_id, rank;`
+
+	rows, err := d.query(query, map[string]any{"event_id": eventID})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	poll.Votes = []models.Vote{}
+	for rows.Next() {
+		var vote models.Vote
+		var userName pgtype.Text
+
+		if err := rows.Scan(&vote.UserID, &userName, &vote.BoardGameID, &vote.Rank); err != nil {
+			return nil, err
+		}
+
+		if userName.Valid {
+			vote.UserName = userName.String
+		}
+
+		poll.Votes = append(poll.Votes, vote)
+	}
+
+	return &poll, rows.Err()
 }
 
-func (d *Database) HasBoardGameWithMessageID(messageID int64) bool {
-	query := `SELECT id FROM boardgames WHERE message_id = @message_id;`
+func (d *Database) UpdatePollMessageID(eventID string, messageID int64) error {
+	query := `UPDATE polls SET message_id = @message_id WHERE event_id = @event_id RETURNING event_id;`
 
-	var id int64
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
+	var id string
+	if err := d.queryRow(query,
+		map[string]any{
+			"event_id":   eventID,
 			"message_id": messageID,
-		})...,
+		},
 	).Scan(&id); err != nil {
-		return false
+		return ParseError(err)
 	}
 
-	return true
+	return nil
 }
 
-func (d *Database) InsertParticipant(eventID string, boardgameID, userID int64, userName string) (int64, error) {
-	var participantID int64
-	query := `INSERT INTO participants (event_id, boardgame_id, user_id, user_name) VALUES (@event_id, @boardgame_id, @user_id, @user_name) RETURNING id;`
+// ReplaceBallot stores the games voted by a user in the order of their
+// rank, it fails with ErrNoRows when the vote is not open.
+func (d *Database) ReplaceBallot(eventID string, userID int64, userName string, ballot []int64) error {
+	return d.withTx(func(tx *sql.Tx) error {
+		query := `SELECT event_id FROM polls WHERE event_id = @event_id AND status = @open;`
+
+		var id string
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"event_id": eventID,
+				"open":     models.PollOpen,
+			},
+		).Scan(&id); err != nil {
+			return ParseError(err)
+		}
 
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
-			"event_id":     eventID,
-			"boardgame_id": boardgameID,
-			"user_id":      userID,
-			"user_name":    userName,
-		})...,
-	).Scan(&participantID); err != nil {
-		return 0, err
-	}
+		query = `DELETE FROM votes WHERE event_id = @event_id AND user_id = @user_id;`
 
-	return participantID, nil
+		if _, err := d.txExec(tx, query,
+			map[string]any{
+				"event_id": eventID,
+				"user_id":  userID,
+			},
+		); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO votes (event_id, user_id, user_name, boardgame_id, rank)
+		VALUES (@event_id, @user_id, @user_name, @boardgame_id, @rank)
+		ON CONFLICT (event_id, user_id, boardgame_id) DO NOTHING;`
+
+		for i, boardGameID := range ballot {
+			if _, err := d.txExec(tx, query,
+				map[string]any{
+					"event_id":     eventID,
+					"user_id":      userID,
+					"user_name":    userName,
+					"boardgame_id": boardGameID,
+					"rank":         i + 1,
+				},
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-func (d *Database) RemoveParticipant(eventID string, userID int64) error {
-	query := `DELETE FROM participants WHERE event_id = @event_id AND user_id = @user_id;`
+// ClosePoll closes the vote, it reports false when it was not open.
+func (d *Database) ClosePoll(eventID string) (bool, error) {
+	query := `UPDATE polls SET status = @closed WHERE event_id = @event_id AND status = @open;`
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
+	res, err := d.exec(query,
+		map[string]any{
 			"event_id": eventID,
-			"user_id":  userID,
-		})...,
-	); err != nil {
-		return err
+			"open":     models.PollOpen,
+			"closed":   models.PollClosed,
+		},
+	)
+	if err != nil {
+		return false, err
 	}
 
-	return nil
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// InsertPlay stores the result of a game, replacing the one recorded before
+// for the same game.
+func (d *Database) InsertPlay(play models.Play) (int64, error) {
+	var playID int64
+
+	err := d.withTx(func(tx *sql.Tx) error {
+		args := map[string]any{"boardgame_id": play.BoardGameID}
+
+		if _, err := d.txExec(tx, `DELETE FROM play_scores WHERE play_id IN (SELECT id FROM plays WHERE boardgame_id = @boardgame_id);`, args); err != nil {
+			return err
+		}
+
+		if _, err := d.txExec(tx, `DELETE FROM plays WHERE boardgame_id = @boardgame_id;`, args); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO plays (event_id, boardgame_id, chat_id, name, bgg_id, duration_minutes, notes, user_id, user_name, played_at)
+		VALUES (@event_id, @boardgame_id, @chat_id, @name, @bgg_id, @duration_minutes, @notes, @user_id, @user_name, @played_at) RETURNING id;`
+
+		if err := d.txQueryRow(tx, query,
+			map[string]any{
+				"event_id":         play.EventID,
+				"boardgame_id":     play.BoardGameID,
+				"chat_id":          play.ChatID,
+				"name":             play.Name,
+				"bgg_id":           play.BggID,
+				"duration_minutes": play.DurationMinutes,
+				"notes":            play.Notes,
+				"user_id":          play.UserID,
+				"user_name":        play.UserName,
+				"played_at":        play.PlayedAt.Unix(),
+			},
+		).Scan(&playID); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO play_scores (play_id, user_id, user_name, score, winner)
+		VALUES (@play_id, @user_id, @user_name, @score, @winner)
+		ON CONFLICT (play_id, user_id) DO NOTHING;`
+
+		for _, s := range play.Scores {
+			if _, err := d.txExec(tx, query,
+				map[string]any{
+					"play_id":   playID,
+					"user_id":   s.UserID,
+					"user_name": s.UserName,
+					"score":     s.Score,
+					"winner":    s.Winner,
+				},
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return playID, err
+}
+
+const selectPlaysQuery = `SELECT p.id, p.event_id, p.boardgame_id, p.chat_id, p.name, p.bgg_id, p.duration_minutes, p.notes, p.user_id, p.user_name, p.played_at,
+	s.user_id, s.user_name, s.score, s.winner
+	FROM plays p
+	LEFT JOIN play_scores s ON s.play_id = p.id`
+
+// SelectPlay returns the result recorded for a game.
+func (d *Database) SelectPlay(boardGameID int64) (*models.Play, error) {
+	plays, err := d.selectPlaysByQuery(selectPlaysQuery+` WHERE p.boardgame_id = @boardgame_id ORDER BY p.id, s.user_id;`,
+		map[string]any{
+			"boardgame_id": boardGameID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plays) == 0 {
+		return nil, ErrNoRows
+	}
+
+	return &plays[0], nil
+}
+
+// SelectChatPlays returns the results recorded in the chat, from the first
+// one played.
+func (d *Database) SelectChatPlays(chatID int64) ([]models.Play, error) {
+	return d.selectPlaysByQuery(selectPlaysQuery+` WHERE p.chat_id = @chat_id ORDER BY p.played_at, p.id, s.user_id;`,
+		map[string]any{
+			"chat_id": chatID,
+		},
+	)
+}
+
+func (d *Database) selectPlaysByQuery(query string, args map[string]any) ([]models.Play, error) {
+	rows, err := d.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	plays := []models.Play{}
+	for rows.Next() {
+		var p models.Play
+		var s models.PlayScore
+		var bggID, duration, scoreUserID, score pgtype.Int8
+		var notes, userName, scoreUserName pgtype.Text
+		var playedAt int64
+		var winner sql.NullBool
+
+		if err := rows.Scan(
+			&p.ID,
+			&p.EventID,
+			&p.BoardGameID,
+			&p.ChatID,
+			&p.Name,
+			&bggID,
+			&duration,
+			&notes,
+			&p.UserID,
+			&userName,
+			&playedAt,
+			&scoreUserID,
+			&scoreUserName,
+			&score,
+			&winner,
+		); err != nil {
+			return nil, err
+		}
+
+		if len(plays) == 0 || plays[len(plays)-1].ID != p.ID {
+			p.BggID = IntOrNil(bggID)
+			if duration.Valid {
+				minutes := int(duration.Int64)
+				p.DurationMinutes = &minutes
+			}
+			p.Notes = StringOrNil(notes)
+			if userName.Valid {
+				p.UserName = userName.String
+			}
+			p.PlayedAt = time.Unix(playedAt, 0)
+			p.Scores = []models.PlayScore{}
+
+			plays = append(plays, p)
+		}
+
+		if scoreUserID.Valid {
+			s.UserID = scoreUserID.Int64
+			if scoreUserName.Valid {
+				s.UserName = scoreUserName.String
+			}
+			s.Score = IntOrNil(score)
+			s.Winner = winner.Bool
+
+			last := &plays[len(plays)-1]
+			last.Scores = append(last.Scores, s)
+		}
+	}
+
+	for i := range plays {
+		plays[i].SortScores()
+	}
+
+	return plays, rows.Err()
 }
 
 func (d *Database) InsertChat(chatID int64, language string) error {
@@ -956,11 +1938,11 @@ func (d *Database) InsertChat(chatID int64, language string) error {
 		DO UPDATE SET language = EXCLUDED.language;
 	`
 
-	if _, err := d.db.Exec(query,
-		NamedArgs(map[string]any{
+	if _, err := d.exec(query,
+		map[string]any{
 			"chat_id":  chatID,
 			"language": language,
-		})...,
+		},
 	); err != nil {
 		return err
 	}
@@ -972,10 +1954,10 @@ func (d *Database) GetPreferredLanguage(chatID int64) string {
 	query := `SELECT language FROM chats WHERE chat_id = @chat_id;`
 
 	var language string
-	if err := d.db.QueryRow(query,
-		NamedArgs(map[string]any{
+	if err := d.queryRow(query,
+		map[string]any{
 			"chat_id": chatID,
-		})...,
+		},
 	).Scan(&language); err != nil {
 		return "en"
 	}
@@ -1001,6 +1983,15 @@ func StringOrNil(i pgtype.Text) *string {
 	return nil
 }
 
+func TimeOrNil(t sql.NullTime) *time.Time {
+	if t.Valid {
+		v := t.Time
+		return &v
+	}
+
+	return nil
+}
+
 func ParseError(err error) error {
 	if err.Error() == "sql: no rows in result set" {
 		return ErrNoRows
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite3"
+	Postgres Dialect = "postgres"
+)
+
+var namedParamRegex = regexp.MustCompile(`@([a-zA-Z_][a-zA-Z0-9_]*)`)
+
+// bind adapts a query written with @named parameters to the dialect of the
+// database. SQLite understands named parameters natively, while Postgres
+// needs them rewritten to positional $n placeholders.
+func (d *Database) bind(query string, args map[string]any) (string, []any) {
+	if d.dialect != Postgres {
+		return query, NamedArgs(args)
+	}
+
+	positions := map[string]int{}
+	positional := []any{}
+
+	query = namedParamRegex.ReplaceAllStringFunc(query, func(match string) string {
+		name := match[1:]
+		if _, ok := positions[name]; !ok {
+			positional = append(positional, args[name])
+			positions[name] = len(positional)
+		}
+
+		return fmt.Sprintf("$%d", positions[name])
+	})
+
+	return query, positional
+}
+
+func (d *Database) exec(query string, args map[string]any) (sql.Result, error) {
+	query, params := d.bind(query, args)
+	return d.db.Exec(query, params...)
+}
+
+func (d *Database) queryRow(query string, args map[string]any) *sql.Row {
+	query, params := d.bind(query, args)
+	return d.db.QueryRow(query, params...)
+}
+
+func (d *Database) query(query string, args map[string]any) (*sql.Rows, error) {
+	query, params := d.bind(query, args)
+	return d.db.Query(query, params...)
+}
+
+func (d *Database) txExec(tx *sql.Tx, query string, args map[string]any) (sql.Result, error) {
+	query, params := d.bind(query, args)
+	return tx.Exec(query, params...)
+}
+
+func (d *Database) txQueryRow(tx *sql.Tx, query string, args map[string]any) *sql.Row {
+	query, params := d.bind(query, args)
+	return tx.QueryRow(query, params...)
+}
+
+func (d *Database) txQuery(tx *sql.Tx, query string, args map[string]any) (*sql.Rows, error) {
+	query, params := d.bind(query, args)
+	return tx.Query(query, params...)
+}
+
+// withTx runs fn in a transaction, committed only when fn succeeds.
+func (d *Database) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockBoardGame serializes the changes to the table of a game. SQLite
+// transactions are already exclusive as they are opened with _txlock=immediate.
+func (d *Database) lockBoardGame(tx *sql.Tx, boardgameID int64) error {
+	if d.dialect != Postgres {
+		return nil
+	}
+
+	_, err := d.txExec(tx, `SELECT id FROM boardgames WHERE id = @id FOR UPDATE;`,
+		map[string]any{
+			"id": boardgameID,
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,1177 @@
+package database
+
+import (
+	"boardgame-night-bot/src/models"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryEvent struct {
+	models.Event
+	seq int64
+}
+
+type memoryBoardGame struct {
+	models.BoardGame
+	EventID string
+}
+
+type memoryParticipant struct {
+	models.Participant
+	EventID     string
+	BoardGameID int64
+}
+
+// MemoryDatabase is a Store kept entirely in memory. It mirrors the behaviour
+// of Database and is meant for tests.
+type MemoryDatabase struct {
+	mu           sync.Mutex
+	seq          int64
+	events       map[string]*memoryEvent
+	boardGames   []*memoryBoardGame
+	participants []*memoryParticipant
+	chats        map[int64]string
+	auditLog     []models.AuditEntry
+	reminders    map[string]bool
+	users        map[int64]models.User
+	series       []*models.Series
+	bggAccounts  map[int64]models.BGGAccount
+	accountChats map[int64]map[int64]bool
+	collections  map[int64][]models.CollectionGame
+	polls        map[string]*models.Poll
+	organizers   map[string][]models.Organizer
+	plays        map[int64]models.Play
+}
+
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		events:       map[string]*memoryEvent{},
+		chats:        map[int64]string{},
+		reminders:    map[string]bool{},
+		users:        map[int64]models.User{},
+		bggAccounts:  map[int64]models.BGGAccount{},
+		accountChats: map[int64]map[int64]bool{},
+		collections:  map[int64][]models.CollectionGame{},
+		polls:        map[string]*models.Poll{},
+		organizers:   map[string][]models.Organizer{},
+		plays:        map[int64]models.Play{},
+	}
+}
+
+func (m *MemoryDatabase) nextID() int64 {
+	m.seq++
+	return m.seq
+}
+
+func (m *MemoryDatabase) InsertEvent(chatID, userID int64, userName, name string, messageID *int64, startsAt, endsAt *time.Time, location *string, status models.EventStatus) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New().String()
+	m.events[id] = &memoryEvent{
+		Event: models.Event{
+			ID:        id,
+			ChatID:    chatID,
+			UserID:    userID,
+			UserName:  userName,
+			Name:      name,
+			MessageID: messageID,
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+			Location:  location,
+			Status:    status,
+			JoinMode:  models.JoinSingle,
+		},
+		seq: m.nextID(),
+	}
+
+	return id, nil
+}
+
+func (m *MemoryDatabase) SelectEvent(chatID int64) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *memoryEvent
+	for _, e := range m.events {
+		if e.ChatID == chatID && (latest == nil || e.seq > latest.seq) {
+			latest = e
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoRows
+	}
+
+	return m.buildEvent(latest), nil
+}
+
+func (m *MemoryDatabase) SelectEventByEventID(eventID string) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.events[eventID]
+	if !ok {
+		return nil, ErrNoRows
+	}
+
+	return m.buildEvent(e), nil
+}
+
+func (m *MemoryDatabase) SelectEventByMessageID(chatID, messageID int64) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.events {
+		if e.ChatID == chatID && e.MessageID != nil && *e.MessageID == messageID {
+			return m.buildEvent(e), nil
+		}
+	}
+
+	return nil, ErrNoRows
+}
+
+func (m *MemoryDatabase) SelectEventByCode(chatID int64, code string) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	code = strings.ToLower(code)
+
+	var latest *memoryEvent
+	for _, e := range m.events {
+		if e.ChatID == chatID && strings.HasPrefix(e.ID, code) && (latest == nil || e.seq > latest.seq) {
+			latest = e
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoRows
+	}
+
+	return m.buildEvent(latest), nil
+}
+
+func (m *MemoryDatabase) SelectEventByBoardGameMessageID(chatID, messageID int64) (*models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(m.inChat(chatID, byMessageID(messageID)))
+	if bg == nil {
+		return nil, ErrNoRows
+	}
+
+	return m.buildEvent(m.events[bg.EventID]), nil
+}
+
+func (m *MemoryDatabase) SelectActiveEvents(chatID int64) ([]models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	active := []*memoryEvent{}
+	for _, e := range m.events {
+		if e.ChatID == chatID && e.IsActive() {
+			active = append(active, e)
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].seq > active[j].seq
+	})
+
+	events := []models.Event{}
+	for _, e := range active {
+		event := e.Event
+		event.BoardGames = nil
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (m *MemoryDatabase) SelectEventSummaries(chatID int64, limit, offset int) ([]models.EventSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inChat := []*memoryEvent{}
+	for _, e := range m.events {
+		if e.ChatID == chatID {
+			inChat = append(inChat, e)
+		}
+	}
+
+	sort.Slice(inChat, func(i, j int) bool {
+		if inChat[i].IsActive() != inChat[j].IsActive() {
+			return inChat[i].IsActive()
+		}
+
+		return inChat[i].seq > inChat[j].seq
+	})
+
+	summaries := []models.EventSummary{}
+	for i := offset; i < len(inChat) && len(summaries) < limit; i++ {
+		event := m.buildEvent(inChat[i])
+
+		summary := models.EventSummary{Event: *event}
+		summary.BoardGames = nil
+
+		seated := map[int64]bool{}
+		for _, bg := range event.BoardGames {
+			if bg.Name != models.PLAYER_COUNTER {
+				summary.Games++
+			}
+
+			for _, p := range bg.Participants {
+				seated[p.UserID] = true
+			}
+		}
+		summary.Participants = len(seated)
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+func (m *MemoryDatabase) CountEvents(chatID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, e := range m.events {
+		if e.ChatID == chatID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *MemoryDatabase) buildEvent(e *memoryEvent) *models.Event {
+	event := e.Event
+	event.BoardGames = nil
+
+	for _, bg := range m.boardGames {
+		if bg.EventID != e.ID {
+			continue
+		}
+
+		boardGame := bg.BoardGame
+		boardGame.Participants = nil
+		boardGame.Waitlist = nil
+		for _, p := range m.participants {
+			if p.BoardGameID != bg.ID {
+				continue
+			}
+
+			if p.Waitlisted {
+				boardGame.Waitlist = append(boardGame.Waitlist, p.Participant)
+			} else {
+				boardGame.Participants = append(boardGame.Participants, p.Participant)
+			}
+		}
+
+		event.BoardGames = append(event.BoardGames, boardGame)
+	}
+
+	sortEvent(&event)
+
+	joined := map[int64]bool{}
+	for _, p := range m.participants {
+		if p.EventID == e.ID {
+			joined[p.UserID] = true
+		}
+	}
+
+	for i, bg := range event.BoardGames {
+		owners := []string{}
+		for userID := range joined {
+			for _, game := range m.collections[userID] {
+				if bg.BggID != nil && game.BGGID == *bg.BggID {
+					owners = append(owners, m.bggAccounts[userID].UserName)
+				}
+			}
+		}
+
+		if len(owners) > 0 {
+			sort.Strings(owners)
+			event.BoardGames[i].Owners = owners
+		}
+	}
+
+	event.Organizers = append([]models.Organizer{}, m.organizers[e.ID]...)
+	sort.Slice(event.Organizers, func(i, j int) bool {
+		a, b := event.Organizers[i], event.Organizers[j]
+		return a.UserName < b.UserName || (a.UserName == b.UserName && a.UserID < b.UserID)
+	})
+
+	return &event
+}
+
+func (m *MemoryDatabase) UpdateEventMessageID(eventID string, messageID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.events[eventID]; ok {
+		e.MessageID = &messageID
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateEventDetails(eventID string, startsAt, endsAt *time.Time, location *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.events[eventID]
+	if !ok {
+		return ErrNoRows
+	}
+
+	e.StartsAt = startsAt
+	e.EndsAt = endsAt
+	e.Location = location
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateEventStatus(eventID string, status models.EventStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.events[eventID]
+	if !ok {
+		return ErrNoRows
+	}
+
+	e.Status = status
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateEventJoinMode(eventID string, joinMode models.JoinMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.events[eventID]
+	if !ok {
+		return ErrNoRows
+	}
+
+	e.JoinMode = joinMode
+
+	return nil
+}
+
+func (m *MemoryDatabase) InsertBoardGame(eventID string, name string, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string, initiatorID *int64, initiatorName *string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var bringerName *string
+	if initiatorID != nil {
+		bringerName = initiatorName
+	}
+
+	bg := &memoryBoardGame{
+		BoardGame: models.BoardGame{
+			ID:            m.nextID(),
+			Name:          name,
+			MaxPlayers:    int64(maxPlayers),
+			BggID:         bggID,
+			BggName:       bggName,
+			BggUrl:        bggUrl,
+			BggImageUrl:   bggImageUrl,
+			InitiatorName: initiatorName,
+			BringerID:     initiatorID,
+			BringerName:   bringerName,
+		},
+		EventID: eventID,
+	}
+	m.boardGames = append(m.boardGames, bg)
+
+	return bg.ID, nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGameBringer(ID int64, userID *int64, userName *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(func(bg *memoryBoardGame) bool { return bg.ID == ID })
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	if userID == nil {
+		userName = nil
+	}
+
+	bg.BringerID = userID
+	bg.BringerName = userName
+
+	return nil
+}
+
+func (m *MemoryDatabase) findBoardGame(match func(bg *memoryBoardGame) bool) *memoryBoardGame {
+	for _, bg := range m.boardGames {
+		if match(bg) {
+			return bg
+		}
+	}
+
+	return nil
+}
+
+func byMessageID(messageID int64) func(bg *memoryBoardGame) bool {
+	return func(bg *memoryBoardGame) bool {
+		return bg.MessageID != nil && *bg.MessageID == messageID
+	}
+}
+
+// inChat restricts a board game match to the events of a chat.
+func (m *MemoryDatabase) inChat(chatID int64, match func(bg *memoryBoardGame) bool) func(bg *memoryBoardGame) bool {
+	return func(bg *memoryBoardGame) bool {
+		e, ok := m.events[bg.EventID]
+		return ok && e.ChatID == chatID && match(bg)
+	}
+}
+
+func byID(ID int64) func(bg *memoryBoardGame) bool {
+	return func(bg *memoryBoardGame) bool {
+		return bg.ID == ID
+	}
+}
+
+func (m *MemoryDatabase) UpdateBoardGameMessageID(boardgameID, messageID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bg := m.findBoardGame(byID(boardgameID)); bg != nil {
+		bg.MessageID = &messageID
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGamePlayerNumber(chatID, messageID int64, maxPlayers int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(m.inChat(chatID, byMessageID(messageID)))
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	bg.MaxPlayers = int64(maxPlayers)
+	m.promote(bg)
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGamePlayerNumberByID(ID int64, maxPlayers int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(byID(ID))
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	bg.MaxPlayers = int64(maxPlayers)
+	m.promote(bg)
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGameName(ID int64, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(byID(ID))
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	bg.Name = name
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGameBGGInfo(chatID, messageID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(m.inChat(chatID, byMessageID(messageID)))
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	bg.setBGGInfo(maxPlayers, bggID, bggName, bggUrl, bggImageUrl)
+	m.promote(bg)
+
+	return nil
+}
+
+func (m *MemoryDatabase) UpdateBoardGameBGGInfoByID(ID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(byID(ID))
+	if bg == nil {
+		return ErrNoRows
+	}
+
+	bg.setBGGInfo(maxPlayers, bggID, bggName, bggUrl, bggImageUrl)
+	m.promote(bg)
+
+	return nil
+}
+
+func (bg *memoryBoardGame) setBGGInfo(maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) {
+	bg.MaxPlayers = int64(maxPlayers)
+	bg.BggID = bggID
+	bg.BggName = bggName
+	bg.BggUrl = bggUrl
+	bg.BggImageUrl = bggImageUrl
+}
+
+func (m *MemoryDatabase) DeleteBoardGameByID(ID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	boardGames := []*memoryBoardGame{}
+	for _, bg := range m.boardGames {
+		if bg.ID != ID {
+			boardGames = append(boardGames, bg)
+		}
+	}
+	m.boardGames = boardGames
+
+	return nil
+}
+
+func (m *MemoryDatabase) HasBoardGameWithMessageID(chatID, messageID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.findBoardGame(m.inChat(chatID, byMessageID(messageID))) != nil
+}
+
+func (m *MemoryDatabase) InsertParticipant(eventID string, boardgameID, userID int64, userName string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bg := m.findBoardGame(byID(boardgameID))
+	if bg == nil || bg.EventID != eventID {
+		return 0, ErrNoRows
+	}
+
+	for _, p := range m.participants {
+		if p.UserID == userID && p.BoardGameID == boardgameID {
+			return p.ID, nil
+		}
+	}
+
+	left := []int64{}
+	if m.events[eventID].JoinMode != models.JoinMultiple {
+		left = m.removeParticipant(eventID, nil, userID)
+	}
+
+	p := &memoryParticipant{
+		Participant: models.Participant{
+			ID:         m.nextID(),
+			UserID:     userID,
+			UserName:   userName,
+			Waitlisted: bg.MaxPlayers != -1 && m.seated(bg.ID) >= int(bg.MaxPlayers),
+		},
+		EventID:     eventID,
+		BoardGameID: boardgameID,
+	}
+	m.participants = append(m.participants, p)
+
+	m.promoteAll(left)
+
+	return p.ID, nil
+}
+
+func (m *MemoryDatabase) RemoveParticipant(eventID string, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.promoteAll(m.removeParticipant(eventID, nil, userID))
+
+	return nil
+}
+
+func (m *MemoryDatabase) RemoveParticipantFromBoardGame(eventID string, boardgameID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.promoteAll(m.removeParticipant(eventID, &boardgameID, userID))
+
+	return nil
+}
+
+// removeParticipant removes the user from a game of the event, or from all of
+// them when boardgameID is nil, and returns the ids of the games left.
+func (m *MemoryDatabase) removeParticipant(eventID string, boardgameID *int64, userID int64) []int64 {
+	left := []int64{}
+	participants := []*memoryParticipant{}
+	for _, p := range m.participants {
+		if p.EventID != eventID || p.UserID != userID || (boardgameID != nil && p.BoardGameID != *boardgameID) {
+			participants = append(participants, p)
+		} else {
+			left = append(left, p.BoardGameID)
+		}
+	}
+	m.participants = participants
+
+	return left
+}
+
+func (m *MemoryDatabase) seated(boardgameID int64) int {
+	seated := 0
+	for _, p := range m.participants {
+		if p.BoardGameID == boardgameID && !p.Waitlisted {
+			seated++
+		}
+	}
+
+	return seated
+}
+
+func (m *MemoryDatabase) promoteAll(boardgameIDs []int64) {
+	for _, ID := range boardgameIDs {
+		if bg := m.findBoardGame(byID(ID)); bg != nil {
+			m.promote(bg)
+		}
+	}
+}
+
+// promote seats the first players of the waitlist while there are free seats,
+// participants are kept in joining order.
+func (m *MemoryDatabase) promote(bg *memoryBoardGame) {
+	seated := m.seated(bg.ID)
+	for _, p := range m.participants {
+		if p.BoardGameID == bg.ID && p.Waitlisted && (bg.MaxPlayers == -1 || seated < int(bg.MaxPlayers)) {
+			p.Waitlisted = false
+			seated++
+		}
+	}
+}
+
+func (m *MemoryDatabase) InsertAuditEntry(entry models.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry.ID = m.nextID()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m.auditLog = append(m.auditLog, entry)
+
+	return nil
+}
+
+func (m *MemoryDatabase) SelectAuditLog(eventID string, limit int) ([]models.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := []models.AuditEntry{}
+	for i := len(m.auditLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		if m.auditLog[i].EventID == eventID {
+			entries = append(entries, m.auditLog[i])
+		}
+	}
+
+	return entries, nil
+}
+
+func (m *MemoryDatabase) SelectScheduledEvents() ([]models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	events := []models.Event{}
+	for _, e := range m.events {
+		if e.StartsAt != nil && e.IsActive() {
+			event := e.Event
+			event.BoardGames = nil
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (m *MemoryDatabase) ClaimReminder(eventID string, startsAt time.Time, offset time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := fmt.Sprintf("%s|%d|%d", eventID, startsAt.Unix(), int64(offset/time.Minute))
+	if m.reminders[key] {
+		return false, nil
+	}
+	m.reminders[key] = true
+
+	return true, nil
+}
+
+func (m *MemoryDatabase) InsertSeries(series models.Series) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series.ID = m.nextID()
+	series.Status = models.SeriesActive
+	series.LastStartsAt = nil
+	series.Games = append([]string{}, series.Games...)
+	m.series = append(m.series, &series)
+
+	return series.ID, nil
+}
+
+func (m *MemoryDatabase) selectSeries(match func(s *models.Series) bool) []models.Series {
+	series := []models.Series{}
+	for _, s := range m.series {
+		if match(s) {
+			copied := *s
+			copied.Games = append([]string{}, s.Games...)
+			series = append(series, copied)
+		}
+	}
+
+	return series
+}
+
+func (m *MemoryDatabase) SelectSeries(chatID int64) ([]models.Series, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.selectSeries(func(s *models.Series) bool {
+		return s.ChatID == chatID && s.Status != models.SeriesStopped
+	}), nil
+}
+
+func (m *MemoryDatabase) SelectActiveSeries() ([]models.Series, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.selectSeries(func(s *models.Series) bool {
+		return s.IsActive()
+	}), nil
+}
+
+func (m *MemoryDatabase) SelectSeriesByID(ID int64) (*models.Series, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series := m.selectSeries(func(s *models.Series) bool {
+		return s.ID == ID
+	})
+	if len(series) == 0 {
+		return nil, ErrNoRows
+	}
+
+	return &series[0], nil
+}
+
+func (m *MemoryDatabase) UpdateSeriesStatus(ID int64, status models.SeriesStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.series {
+		if s.ID == ID {
+			s.Status = status
+			return nil
+		}
+	}
+
+	return ErrNoRows
+}
+
+func (m *MemoryDatabase) ClaimSeriesEvent(ID int64, startsAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.series {
+		if s.ID != ID || !s.IsActive() || (s.LastStartsAt != nil && !s.LastStartsAt.Before(startsAt)) {
+			continue
+		}
+
+		claimed := time.Unix(startsAt.Unix(), 0)
+		s.LastStartsAt = &claimed
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (m *MemoryDatabase) UpsertUser(user models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[user.ID] = user
+
+	return nil
+}
+
+func (m *MemoryDatabase) SelectUser(userID int64) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, ErrNoRows
+	}
+
+	return &user, nil
+}
+
+func (m *MemoryDatabase) LinkBGGAccount(account models.BGGAccount, chatID *int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account.SyncedAt = nil
+	if previous, ok := m.bggAccounts[account.UserID]; ok {
+		if previous.BGGUsername == account.BGGUsername {
+			account.SyncedAt = previous.SyncedAt
+		} else {
+			delete(m.collections, account.UserID)
+		}
+	}
+
+	m.bggAccounts[account.UserID] = account
+
+	if chatID != nil {
+		if m.accountChats[account.UserID] == nil {
+			m.accountChats[account.UserID] = map[int64]bool{}
+		}
+		m.accountChats[account.UserID][*chatID] = true
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) UnlinkBGGAccount(userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.bggAccounts[userID]; !ok {
+		return ErrNoRows
+	}
+
+	delete(m.bggAccounts, userID)
+	delete(m.accountChats, userID)
+	delete(m.collections, userID)
+
+	return nil
+}
+
+func (m *MemoryDatabase) SelectBGGAccount(userID int64) (*models.BGGAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.bggAccounts[userID]
+	if !ok {
+		return nil, ErrNoRows
+	}
+
+	return &account, nil
+}
+
+func (m *MemoryDatabase) SelectBGGAccountsToSync(before time.Time) ([]models.BGGAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accounts := []models.BGGAccount{}
+	for _, account := range m.bggAccounts {
+		if account.SyncedAt == nil || account.SyncedAt.Unix() < before.Unix() {
+			accounts = append(accounts, account)
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		a, b := accounts[i].SyncedAt, accounts[j].SyncedAt
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		default:
+			return a.Before(*b)
+		}
+	})
+
+	return accounts, nil
+}
+
+func (m *MemoryDatabase) ReplaceCollection(userID int64, games []models.CollectionGame, syncedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.bggAccounts[userID]
+	if !ok {
+		return ErrNoRows
+	}
+
+	syncedAt = time.Unix(syncedAt.Unix(), 0)
+	account.SyncedAt = &syncedAt
+	m.bggAccounts[userID] = account
+
+	collection := []models.CollectionGame{}
+	seen := map[int64]bool{}
+	for _, game := range games {
+		if seen[game.BGGID] {
+			continue
+		}
+		seen[game.BGGID] = true
+
+		game.UserID = userID
+		collection = append(collection, game)
+	}
+	m.collections[userID] = collection
+
+	return nil
+}
+
+func (m *MemoryDatabase) SelectChatCollection(chatID int64) ([]models.CollectionGame, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := map[int64]bool{}
+	for userID, chats := range m.accountChats {
+		if chats[chatID] {
+			members[userID] = true
+		}
+	}
+	for _, p := range m.participants {
+		if e, ok := m.events[p.EventID]; ok && e.ChatID == chatID {
+			members[p.UserID] = true
+		}
+	}
+
+	games := []models.CollectionGame{}
+	for userID := range members {
+		account, ok := m.bggAccounts[userID]
+		if !ok {
+			continue
+		}
+
+		for _, game := range m.collections[userID] {
+			game.UserName = account.UserName
+			games = append(games, game)
+		}
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Name != games[j].Name {
+			return games[i].Name < games[j].Name
+		}
+		return games[i].UserName < games[j].UserName
+	})
+
+	return games, nil
+}
+
+func (m *MemoryDatabase) InsertEventOrganizer(eventID string, userID int64, userName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, o := range m.organizers[eventID] {
+		if o.UserID == userID {
+			m.organizers[eventID][i].UserName = userName
+			return nil
+		}
+	}
+
+	m.organizers[eventID] = append(m.organizers[eventID], models.Organizer{UserID: userID, UserName: userName})
+
+	return nil
+}
+
+func (m *MemoryDatabase) DeleteEventOrganizer(eventID string, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, o := range m.organizers[eventID] {
+		if o.UserID == userID {
+			m.organizers[eventID] = append(m.organizers[eventID][:i], m.organizers[eventID][i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNoRows
+}
+
+func (m *MemoryDatabase) InsertPoll(poll models.Poll) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.polls[poll.EventID] = &models.Poll{
+		EventID: poll.EventID,
+		Method:  poll.Method,
+		Winners: poll.Winners,
+		Status:  models.PollOpen,
+		Votes:   []models.Vote{},
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) SelectPoll(eventID string) (*models.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll, ok := m.polls[eventID]
+	if !ok {
+		return nil, ErrNoRows
+	}
+
+	copied := *poll
+	copied.Votes = append([]models.Vote{}, poll.Votes...)
+	sort.SliceStable(copied.Votes, func(i, j int) bool {
+		a, b := copied.Votes[i], copied.Votes[j]
+		return a.UserID < b.UserID || (a.UserID == b.UserID && a.Rank < b.Rank)
+	})
+
+	return &copied, nil
+}
+
+func (m *MemoryDatabase) UpdatePollMessageID(eventID string, messageID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll, ok := m.polls[eventID]
+	if !ok {
+		return ErrNoRows
+	}
+
+	poll.MessageID = &messageID
+
+	return nil
+}
+
+func (m *MemoryDatabase) ReplaceBallot(eventID string, userID int64, userName string, ballot []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll, ok := m.polls[eventID]
+	if !ok || !poll.IsOpen() {
+		return ErrNoRows
+	}
+
+	votes := []models.Vote{}
+	for _, v := range poll.Votes {
+		if v.UserID != userID {
+			votes = append(votes, v)
+		}
+	}
+
+	seen := map[int64]bool{}
+	for i, boardGameID := range ballot {
+		if seen[boardGameID] {
+			continue
+		}
+		seen[boardGameID] = true
+
+		votes = append(votes, models.Vote{UserID: userID, UserName: userName, BoardGameID: boardGameID, Rank: i + 1})
+	}
+	poll.Votes = votes
+
+	return nil
+}
+
+func (m *MemoryDatabase) ClosePoll(eventID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	poll, ok := m.polls[eventID]
+	if !ok || !poll.IsOpen() {
+		return false, nil
+	}
+
+	poll.Status = models.PollClosed
+
+	return true, nil
+}
+
+func (m *MemoryDatabase) InsertPlay(play models.Play) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	play.ID = m.nextID()
+	play.PlayedAt = time.Unix(play.PlayedAt.Unix(), 0)
+
+	seen := map[int64]bool{}
+	scores := []models.PlayScore{}
+	for _, s := range play.Scores {
+		if !seen[s.UserID] {
+			seen[s.UserID] = true
+			scores = append(scores, s)
+		}
+	}
+	play.Scores = scores
+
+	m.plays[play.BoardGameID] = play
+
+	return play.ID, nil
+}
+
+func (m *MemoryDatabase) SelectPlay(boardGameID int64) (*models.Play, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	play, ok := m.plays[boardGameID]
+	if !ok {
+		return nil, ErrNoRows
+	}
+
+	play.Scores = append([]models.PlayScore{}, play.Scores...)
+	play.SortScores()
+
+	return &play, nil
+}
+
+func (m *MemoryDatabase) SelectChatPlays(chatID int64) ([]models.Play, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plays := []models.Play{}
+	for _, play := range m.plays {
+		if play.ChatID == chatID {
+			play.Scores = append([]models.PlayScore{}, play.Scores...)
+			play.SortScores()
+			plays = append(plays, play)
+		}
+	}
+
+	sort.Slice(plays, func(i, j int) bool {
+		if !plays[i].PlayedAt.Equal(plays[j].PlayedAt) {
+			return plays[i].PlayedAt.Before(plays[j].PlayedAt)
+		}
+		return plays[i].ID < plays[j].ID
+	})
+
+	return plays, nil
+}
+
+func (m *MemoryDatabase) InsertChat(chatID int64, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.chats[chatID] = language
+
+	return nil
+}
+
+func (m *MemoryDatabase) GetPreferredLanguage(chatID int64) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if language, ok := m.chats[chatID]; ok {
+		return language
+	}
+
+	return "en"
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+type Migration struct {
+	Version int
+	Name    string
+	Query   string
+}
+
+// LoadMigrations reads the embedded up-migrations of the given directory,
+// named like 0001_initial.up.sql, ordered by version.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(e.Name(), ".up.sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name: %s", e.Name())
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", e.Name(), err)
+		}
+
+		query, err := fs.ReadFile(migrationFiles, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    parts[1],
+			Query:   string(query),
+		})
+	}
+
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+func (d *Database) migrations() ([]Migration, error) {
+	if d.dialect == Postgres {
+		return LoadMigrations("migrations/postgres")
+	}
+
+	return LoadMigrations("migrations/sqlite")
+}
+
+func (d *Database) ensureMigrationsTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	_, err := d.db.Exec(query)
+	return err
+}
+
+// SchemaVersion returns the highest migration version applied to the database.
+func (d *Database) SchemaVersion() (int, error) {
+	if err := d.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+
+	var version int
+	if err := d.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// PendingMigrations returns the migrations not yet applied, failing when the
+// database has been migrated by a newer binary.
+func (d *Database) PendingMigrations() ([]Migration, error) {
+	migrations, err := d.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	if current > latest {
+		return nil, fmt.Errorf("%w: database is at version %d, latest known is %d", ErrSchemaTooNew, current, latest)
+	}
+
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// lockMigration serializes concurrent migrators sharing a Postgres database
+// and reports whether the migration has already been applied.
+func (d *Database) lockMigration(tx *sql.Tx, m Migration) (bool, error) {
+	if d.dialect == Postgres {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('schema_migrations'));`); err != nil {
+			return false, err
+		}
+	}
+
+	query, args := d.bind(`SELECT COUNT(*) FROM schema_migrations WHERE version = @version;`,
+		map[string]any{
+			"version": m.Version,
+		},
+	)
+
+	var count int
+	if err := tx.QueryRow(query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// manualColumns are the columns that the SQLite databases created before the
+// migrations already have, added by hand: their migration is only recorded.
+var manualColumns = map[int][2]string{
+	2: {"boardgames", "initiator_name"},
+}
+
+// columnExists reports whether the SQLite table has the column.
+func (d *Database) columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s);`, table))
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, kind string
+		var defaultValue sql.NullString
+
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// applied reports whether the changes of the migration are already in the
+// database, without being recorded.
+func (d *Database) applied(tx *sql.Tx, m Migration) (bool, error) {
+	column, ok := manualColumns[m.Version]
+	if d.dialect == Postgres || !ok {
+		return false, nil
+	}
+
+	return d.columnExists(tx, column[0], column[1])
+}
+
+// Migrate applies every pending migration, each one in its own transaction.
+func (d *Database) Migrate() error {
+	pending, err := d.PendingMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		log.Printf("applying migration %04d_%s", m.Version, m.Name)
+
+		tx, err := d.db.Begin()
+		if err != nil {
+			return err
+		}
+
+		applied, err := d.lockMigration(tx, m)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if applied {
+			// another instance applied it in the meantime
+			tx.Rollback()
+			continue
+		}
+
+		if applied, err = d.applied(tx, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if applied {
+			log.Printf("migration %04d_%s already applied by hand, recording it", m.Version, m.Name)
+		} else if _, err = tx.Exec(m.Query); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+
+		query, args := d.bind(`INSERT INTO schema_migrations (version, name) VALUES (@version, @name);`,
+			map[string]any{
+				"version": m.Version,
+				"name":    m.Name,
+			},
+		)
+
+		if _, err = tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	log.Println("database schema up to date")
+
+	return nil
+}
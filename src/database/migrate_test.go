@@ -0,0 +1,90 @@
+package database
+
+import (
+	"testing"
+)
+
+// baselineSchema is the schema created before the migrations, with the
+// initiator_name column added by hand as the deployments did.
+const baselineSchema = `CREATE TABLE chats (
+	chat_id INTEGER NOT NULL,
+	language TEXT NOT NULL DEFAULT 'en',
+	PRIMARY KEY(chat_id)
+	UNIQUE(chat_id) ON CONFLICT REPLACE
+);
+
+CREATE TABLE events (
+	id TEXT PRIMARY KEY,
+	chat_id INTEGER,
+	user_id INTEGER,
+	user_name TEXT,
+	name TEXT,
+	message_id INTEGER,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE boardgames (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	name TEXT,
+	max_players INTEGER,
+	message_id INTEGER,
+	bgg_id INTEGER,
+	bgg_name TEXT,
+	bgg_url TEXT,
+	bgg_image_url TEXT,
+	FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+
+CREATE TABLE participants (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	boardgame_id INTEGER,
+	user_id INTEGER,
+	user_name TEXT,
+	FOREIGN KEY(event_id) REFERENCES boardgames(events) ON DELETE CASCADE,
+	FOREIGN KEY(boardgame_id) REFERENCES boardgames(id) ON DELETE CASCADE,
+	UNIQUE(event_id, user_id) ON CONFLICT REPLACE
+);
+
+ALTER TABLE boardgames ADD COLUMN initiator_name TEXT;
+
+INSERT INTO events (id, chat_id, user_id, user_name, name, message_id) VALUES ('c3b8c1e4-8d1a-4c1e-9a53-2f1c0e7d9a10', 1, 10, 'alice', 'Game night', 100);
+INSERT INTO boardgames (event_id, name, max_players, initiator_name) VALUES ('c3b8c1e4-8d1a-4c1e-9a53-2f1c0e7d9a10', 'Catan', 4, 'alice');`
+
+func TestMigrateBaselineWithInitiatorName(t *testing.T) {
+	db := NewDatabase(t.TempDir())
+	t.Cleanup(db.Close)
+
+	if _, err := db.db.Exec(baselineSchema); err != nil {
+		t.Fatalf("failed to create the baseline schema: %v", err)
+	}
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migration, got %d", len(pending))
+	}
+
+	event := mustSelectEvent(t, db, "c3b8c1e4-8d1a-4c1e-9a53-2f1c0e7d9a10")
+	if len(event.BoardGames) != 1 || event.BoardGames[0].Name != "Catan" || event.BoardGames[0].InitiatorName == nil || *event.BoardGames[0].InitiatorName != "alice" {
+		t.Fatalf("unexpected migrated event: %+v", event)
+	}
+
+	// a database without the column still gets it from the migration
+	fresh := NewDatabase(t.TempDir())
+	t.Cleanup(fresh.Close)
+
+	if err = fresh.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	mustInsertBoardGame(t, fresh, mustInsertEvent(t, fresh, 1, "Game night"), "Azul", 4)
+}
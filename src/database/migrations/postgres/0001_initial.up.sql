@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS chats (
+	chat_id BIGINT NOT NULL PRIMARY KEY,
+	language TEXT NOT NULL DEFAULT 'en'
+);
+
+CREATE TABLE IF NOT EXISTS events (
+	id TEXT PRIMARY KEY,
+	chat_id BIGINT,
+	user_id BIGINT,
+	user_name TEXT,
+	name TEXT,
+	message_id BIGINT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS boardgames (
+	id BIGSERIAL PRIMARY KEY,
+	event_id TEXT REFERENCES events(id) ON DELETE CASCADE,
+	name TEXT,
+	max_players BIGINT,
+	message_id BIGINT,
+	bgg_id BIGINT,
+	bgg_name TEXT,
+	bgg_url TEXT,
+	bgg_image_url TEXT
+);
+
+CREATE TABLE IF NOT EXISTS participants (
+	id BIGSERIAL PRIMARY KEY,
+	event_id TEXT REFERENCES events(id) ON DELETE CASCADE,
+	boardgame_id BIGINT REFERENCES boardgames(id) ON DELETE CASCADE,
+	user_id BIGINT,
+	user_name TEXT,
+	UNIQUE(event_id, user_id)
+);
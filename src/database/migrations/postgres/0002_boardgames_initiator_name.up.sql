@@ -0,0 +1 @@
+ALTER TABLE boardgames ADD COLUMN IF NOT EXISTS initiator_name TEXT;
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS location TEXT;
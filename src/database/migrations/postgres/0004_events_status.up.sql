@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN status TEXT NOT NULL DEFAULT 'open';
+
+UPDATE events SET status = 'locked' WHERE name LIKE '%🔒%';
@@ -0,0 +1 @@
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS waitlisted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS join_mode TEXT NOT NULL DEFAULT 'single';
+
+ALTER TABLE participants DROP CONSTRAINT IF EXISTS participants_event_id_user_id_key;
+
+ALTER TABLE participants ADD CONSTRAINT participants_boardgame_id_user_id_key UNIQUE (boardgame_id, user_id);
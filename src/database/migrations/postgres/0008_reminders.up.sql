@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reminders (
+	event_id TEXT NOT NULL,
+	starts_at BIGINT NOT NULL,
+	offset_minutes INTEGER NOT NULL,
+	sent_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (event_id, starts_at, offset_minutes)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	user_id BIGINT PRIMARY KEY,
+	user_name TEXT,
+	chat_id BIGINT,
+	reminders BOOLEAN NOT NULL DEFAULT FALSE,
+	updated_at TIMESTAMPTZ NOT NULL
+);
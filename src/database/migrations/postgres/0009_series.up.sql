@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS series (
+	id BIGSERIAL PRIMARY KEY,
+	chat_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	user_name TEXT,
+	name TEXT NOT NULL,
+	frequency TEXT NOT NULL,
+	weekday INTEGER,
+	day INTEGER NOT NULL,
+	time TEXT NOT NULL,
+	location TEXT,
+	locked BOOLEAN NOT NULL DEFAULT FALSE,
+	status TEXT NOT NULL DEFAULT 'active',
+	last_starts_at BIGINT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS series_chat_id_idx ON series(chat_id);
+
+CREATE TABLE IF NOT EXISTS series_games (
+	series_id BIGINT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	PRIMARY KEY (series_id, position)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE boardgames ADD COLUMN IF NOT EXISTS bringer_id BIGINT;
+ALTER TABLE boardgames ADD COLUMN IF NOT EXISTS bringer_name TEXT;
+
+UPDATE boardgames SET bringer_name = initiator_name WHERE name <> '_PLAYER_COUNTER_' AND initiator_name IS NOT NULL AND initiator_name <> '';
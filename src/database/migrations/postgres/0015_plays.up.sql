@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS plays (
+	id BIGSERIAL PRIMARY KEY,
+	event_id TEXT NOT NULL,
+	boardgame_id BIGINT NOT NULL UNIQUE,
+	chat_id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	bgg_id BIGINT,
+	duration_minutes INTEGER,
+	notes TEXT,
+	user_id BIGINT NOT NULL,
+	user_name TEXT,
+	played_at BIGINT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS plays_chat_id_idx ON plays(chat_id);
+
+CREATE TABLE IF NOT EXISTS play_scores (
+	play_id BIGINT NOT NULL REFERENCES plays(id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL,
+	user_name TEXT,
+	score BIGINT,
+	winner BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (play_id, user_id)
+);
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS chats (
+	chat_id INTEGER NOT NULL,
+	language TEXT NOT NULL DEFAULT 'en',
+	PRIMARY KEY(chat_id)
+	UNIQUE(chat_id) ON CONFLICT REPLACE
+);
+
+CREATE TABLE IF NOT EXISTS events (
+	id TEXT PRIMARY KEY,
+	chat_id INTEGER,
+	user_id INTEGER,
+	user_name TEXT,
+	name TEXT,
+	message_id INTEGER,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS boardgames (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	name TEXT,
+	max_players INTEGER,
+	message_id INTEGER,
+	bgg_id INTEGER,
+	bgg_name TEXT,
+	bgg_url TEXT,
+	bgg_image_url TEXT,
+	FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS participants (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	boardgame_id INTEGER,
+	user_id INTEGER,
+	user_name TEXT,
+	FOREIGN KEY(event_id) REFERENCES boardgames(events) ON DELETE CASCADE,
+	FOREIGN KEY(boardgame_id) REFERENCES boardgames(id) ON DELETE CASCADE,
+	UNIQUE(event_id, user_id) ON CONFLICT REPLACE
+);
@@ -0,0 +1 @@
+ALTER TABLE boardgames ADD COLUMN initiator_name TEXT;
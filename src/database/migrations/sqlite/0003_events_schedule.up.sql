@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN starts_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN ends_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN location TEXT;
@@ -0,0 +1 @@
+ALTER TABLE participants ADD COLUMN waitlisted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,20 @@
+ALTER TABLE events ADD COLUMN join_mode TEXT NOT NULL DEFAULT 'single';
+
+CREATE TABLE participants_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id TEXT,
+	boardgame_id INTEGER,
+	user_id INTEGER,
+	user_name TEXT,
+	waitlisted BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE,
+	FOREIGN KEY(boardgame_id) REFERENCES boardgames(id) ON DELETE CASCADE,
+	UNIQUE(boardgame_id, user_id)
+);
+
+INSERT INTO participants_new (id, event_id, boardgame_id, user_id, user_name, waitlisted)
+SELECT id, event_id, boardgame_id, user_id, user_name, waitlisted FROM participants;
+
+DROP TABLE participants;
+
+ALTER TABLE participants_new RENAME TO participants;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id TEXT NOT NULL,
+	user_id INTEGER,
+	user_name TEXT,
+	action TEXT NOT NULL,
+	target TEXT,
+	before_value TEXT,
+	after_value TEXT,
+	source TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_event_id_idx ON audit_log(event_id, id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reminders (
+	event_id TEXT NOT NULL,
+	starts_at INTEGER NOT NULL,
+	offset_minutes INTEGER NOT NULL,
+	sent_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (event_id, starts_at, offset_minutes)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	user_id INTEGER PRIMARY KEY,
+	user_name TEXT,
+	chat_id INTEGER,
+	reminders BOOLEAN NOT NULL DEFAULT FALSE,
+	updated_at TIMESTAMP NOT NULL
+);
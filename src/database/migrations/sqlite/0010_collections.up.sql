@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS bgg_accounts (
+	user_id INTEGER PRIMARY KEY,
+	user_name TEXT,
+	bgg_username TEXT NOT NULL,
+	synced_at INTEGER,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS bgg_account_chats (
+	user_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	PRIMARY KEY (user_id, chat_id)
+);
+
+CREATE TABLE IF NOT EXISTS collections (
+	user_id INTEGER NOT NULL,
+	bgg_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	year INTEGER,
+	PRIMARY KEY (user_id, bgg_id)
+);
+
+CREATE INDEX IF NOT EXISTS collections_bgg_id ON collections (bgg_id);
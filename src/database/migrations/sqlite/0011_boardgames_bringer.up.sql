@@ -0,0 +1,4 @@
+ALTER TABLE boardgames ADD COLUMN bringer_id INTEGER;
+ALTER TABLE boardgames ADD COLUMN bringer_name TEXT;
+
+UPDATE boardgames SET bringer_name = initiator_name WHERE name <> '_PLAYER_COUNTER_' AND initiator_name IS NOT NULL AND initiator_name <> '';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS polls (
+	event_id TEXT PRIMARY KEY,
+	method TEXT NOT NULL,
+	winners INTEGER NOT NULL,
+	status TEXT NOT NULL DEFAULT 'open',
+	message_id INTEGER,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS votes (
+	event_id TEXT NOT NULL,
+	user_id INTEGER NOT NULL,
+	user_name TEXT,
+	boardgame_id INTEGER NOT NULL,
+	rank INTEGER NOT NULL,
+	PRIMARY KEY (event_id, user_id, boardgame_id)
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS event_organizers (
+	event_id TEXT NOT NULL,
+	user_id INTEGER NOT NULL,
+	user_name TEXT,
+	PRIMARY KEY (event_id, user_id)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN notify_full BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN notify_deleted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN notify_max_players BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN notify_seat BOOLEAN NOT NULL DEFAULT FALSE;
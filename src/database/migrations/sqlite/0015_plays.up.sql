@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS plays (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id TEXT NOT NULL,
+	boardgame_id INTEGER NOT NULL UNIQUE,
+	chat_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	bgg_id INTEGER,
+	duration_minutes INTEGER,
+	notes TEXT,
+	user_id INTEGER NOT NULL,
+	user_name TEXT,
+	played_at INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS plays_chat_id_idx ON plays(chat_id);
+
+CREATE TABLE IF NOT EXISTS play_scores (
+	play_id INTEGER NOT NULL REFERENCES plays(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	user_name TEXT,
+	score INTEGER,
+	winner BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (play_id, user_id)
+);
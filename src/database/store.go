@@ -0,0 +1,98 @@
+package database
+
+import (
+	"boardgame-night-bot/src/models"
+	"errors"
+	"time"
+)
+
+// Store is the persistence layer used by the telegram and web handlers.
+// Database implements it on top of SQLite or Postgres, MemoryDatabase keeps
+// everything in memory for tests.
+type Store interface {
+	InsertEvent(chatID, userID int64, userName, name string, messageID *int64, startsAt, endsAt *time.Time, location *string, status models.EventStatus) (string, error)
+	SelectEvent(chatID int64) (*models.Event, error)
+	SelectEventByEventID(eventID string) (*models.Event, error)
+	SelectEventByMessageID(chatID, messageID int64) (*models.Event, error)
+	SelectEventByCode(chatID int64, code string) (*models.Event, error)
+	SelectEventByBoardGameMessageID(chatID, messageID int64) (*models.Event, error)
+	SelectActiveEvents(chatID int64) ([]models.Event, error)
+	SelectEventSummaries(chatID int64, limit, offset int) ([]models.EventSummary, error)
+	CountEvents(chatID int64) (int, error)
+	UpdateEventMessageID(eventID string, messageID int64) error
+	UpdateEventDetails(eventID string, startsAt, endsAt *time.Time, location *string) error
+	UpdateEventStatus(eventID string, status models.EventStatus) error
+	UpdateEventJoinMode(eventID string, joinMode models.JoinMode) error
+
+	InsertBoardGame(eventID string, name string, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string, initiatorID *int64, initiatorName *string) (int64, error)
+	UpdateBoardGameMessageID(boardgameID, messageID int64) error
+	UpdateBoardGamePlayerNumber(chatID, messageID int64, maxPlayers int) error
+	UpdateBoardGamePlayerNumberByID(ID int64, maxPlayers int) error
+	UpdateBoardGameName(ID int64, name string) error
+	UpdateBoardGameBringer(ID int64, userID *int64, userName *string) error
+	UpdateBoardGameBGGInfo(chatID, messageID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error
+	UpdateBoardGameBGGInfoByID(ID int64, maxPlayers int, bggID *int64, bggName, bggUrl, bggImageUrl *string) error
+	DeleteBoardGameByID(ID int64) error
+	HasBoardGameWithMessageID(chatID, messageID int64) bool
+
+	InsertParticipant(eventID string, boardgameID, userID int64, userName string) (int64, error)
+	RemoveParticipant(eventID string, userID int64) error
+	RemoveParticipantFromBoardGame(eventID string, boardgameID, userID int64) error
+
+	InsertAuditEntry(entry models.AuditEntry) error
+	SelectAuditLog(eventID string, limit int) ([]models.AuditEntry, error)
+
+	SelectScheduledEvents() ([]models.Event, error)
+	ClaimReminder(eventID string, startsAt time.Time, offset time.Duration) (bool, error)
+
+	InsertSeries(series models.Series) (int64, error)
+	SelectSeries(chatID int64) ([]models.Series, error)
+	SelectActiveSeries() ([]models.Series, error)
+	SelectSeriesByID(ID int64) (*models.Series, error)
+	UpdateSeriesStatus(ID int64, status models.SeriesStatus) error
+	ClaimSeriesEvent(ID int64, startsAt time.Time) (bool, error)
+
+	UpsertUser(user models.User) error
+	SelectUser(userID int64) (*models.User, error)
+
+	LinkBGGAccount(account models.BGGAccount, chatID *int64) error
+	UnlinkBGGAccount(userID int64) error
+	SelectBGGAccount(userID int64) (*models.BGGAccount, error)
+	SelectBGGAccountsToSync(before time.Time) ([]models.BGGAccount, error)
+	ReplaceCollection(userID int64, games []models.CollectionGame, syncedAt time.Time) error
+	SelectChatCollection(chatID int64) ([]models.CollectionGame, error)
+
+	InsertEventOrganizer(eventID string, userID int64, userName string) error
+	DeleteEventOrganizer(eventID string, userID int64) error
+
+	InsertPoll(poll models.Poll) error
+	SelectPoll(eventID string) (*models.Poll, error)
+	UpdatePollMessageID(eventID string, messageID int64) error
+	ReplaceBallot(eventID string, userID int64, userName string, ballot []int64) error
+	ClosePoll(eventID string) (bool, error)
+
+	InsertPlay(play models.Play) (int64, error)
+	SelectPlay(boardGameID int64) (*models.Play, error)
+	SelectChatPlays(chatID int64) ([]models.Play, error)
+
+	InsertChat(chatID int64, language string) error
+	GetPreferredLanguage(chatID int64) string
+}
+
+var (
+	_ Store = (*Database)(nil)
+	_ Store = (*MemoryDatabase)(nil)
+)
+
+// KnownUser loads the users like SelectUser, the ones who never wrote to the
+// bot are nil instead of an error.
+func KnownUser(s Store) func(userID int64) (*models.User, error) {
+	return func(userID int64) (*models.User, error) {
+		user, err := s.SelectUser(userID)
+		if errors.Is(err, ErrNoRows) {
+			return nil, nil
+		}
+
+		return user, err
+	}
+}
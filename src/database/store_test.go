@@ -0,0 +1,1283 @@
+package database
+
+import (
+	"boardgame-night-bot/src/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSQLiteStore(t *testing.T) Store {
+	t.Helper()
+
+	db := NewDatabase(t.TempDir())
+	t.Cleanup(db.Close)
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db
+}
+
+func newMemoryStore(t *testing.T) Store {
+	return NewMemoryDatabase()
+}
+
+func TestSQLiteStore(t *testing.T) {
+	runStoreSuite(t, newSQLiteStore)
+}
+
+func TestMemoryStore(t *testing.T) {
+	runStoreSuite(t, newMemoryStore)
+}
+
+// runStoreSuite is the conformance suite every Store implementation must pass.
+func runStoreSuite(t *testing.T, newStore func(t *testing.T) Store) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s Store)
+	}{
+		{"Events", testEvents},
+		{"EventNotFound", testEventNotFound},
+		{"EventDetails", testEventDetails},
+		{"EventStatus", testEventStatus},
+		{"EventTargeting", testEventTargeting},
+		{"EventSummaries", testEventSummaries},
+		{"BoardGames", testBoardGames},
+		{"BoardGameUpdates", testBoardGameUpdates},
+		{"Participants", testParticipants},
+		{"Waitlist", testWaitlist},
+		{"MultipleTables", testMultipleTables},
+		{"AuditLog", testAuditLog},
+		{"Reminders", testReminders},
+		{"Users", testUsers},
+		{"Series", testSeries},
+		{"Collections", testCollections},
+		{"Bringers", testBringers},
+		{"Polls", testPolls},
+		{"Organizers", testOrganizers},
+		{"Plays", testPlays},
+		{"Chats", testChats},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStore(t))
+		})
+	}
+}
+
+func mustInsertEvent(t *testing.T, s Store, chatID int64, name string) string {
+	t.Helper()
+
+	eventID, err := s.InsertEvent(chatID, 10, "alice", name, nil, nil, nil, nil, models.StatusOpen)
+	if err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	return eventID
+}
+
+func mustInsertBoardGame(t *testing.T, s Store, eventID, name string, maxPlayers int) int64 {
+	t.Helper()
+
+	boardGameID, err := s.InsertBoardGame(eventID, name, maxPlayers, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("InsertBoardGame: %v", err)
+	}
+
+	return boardGameID
+}
+
+func mustSelectEvent(t *testing.T, s Store, eventID string) *models.Event {
+	t.Helper()
+
+	event, err := s.SelectEventByEventID(eventID)
+	if err != nil {
+		t.Fatalf("SelectEventByEventID: %v", err)
+	}
+
+	return event
+}
+
+func testEvents(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday 🔒")
+	mustInsertEvent(t, s, 2, "Other chat")
+
+	event, err := s.SelectEvent(1)
+	if err != nil {
+		t.Fatalf("SelectEvent: %v", err)
+	}
+
+	if event.ID != eventID || event.ChatID != 1 || event.UserID != 10 || event.UserName != "alice" || event.Name != "Friday 🔒" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	if event.Status != models.StatusOpen {
+		t.Errorf("expected event to be open, got %s", event.Status)
+	}
+
+	if event.MessageID != nil {
+		t.Errorf("expected no message id, got %d", *event.MessageID)
+	}
+
+	if err = s.UpdateEventMessageID(eventID, 42); err != nil {
+		t.Fatalf("UpdateEventMessageID: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if event.MessageID == nil || *event.MessageID != 42 {
+		t.Errorf("expected message id 42, got %v", event.MessageID)
+	}
+
+	if len(event.BoardGames) != 0 {
+		t.Errorf("expected no board games, got %d", len(event.BoardGames))
+	}
+}
+
+func testEventNotFound(t *testing.T, s Store) {
+	if _, err := s.SelectEvent(99); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows from SelectEvent, got %v", err)
+	}
+
+	if _, err := s.SelectEventByEventID("00000000-0000-0000-0000-000000000000"); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows from SelectEventByEventID, got %v", err)
+	}
+}
+
+func testEventDetails(t *testing.T, s Store) {
+	startsAt := time.Date(2026, 10, 23, 20, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(3 * time.Hour)
+	location := "Marco's place"
+
+	eventID, err := s.InsertEvent(1, 10, "alice", "Catan night", nil, &startsAt, nil, &location, models.StatusLocked)
+	if err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	event := mustSelectEvent(t, s, eventID)
+	if event.Status != models.StatusLocked || event.StartsAt == nil || !event.StartsAt.Equal(startsAt) || event.EndsAt != nil || event.Location == nil || *event.Location != location {
+		t.Fatalf("unexpected event details: %v %v %v", event.StartsAt, event.EndsAt, event.Location)
+	}
+
+	if err = s.UpdateEventDetails(eventID, &startsAt, &endsAt, nil); err != nil {
+		t.Fatalf("UpdateEventDetails: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if event.EndsAt == nil || !event.EndsAt.Equal(endsAt) || event.Location != nil {
+		t.Errorf("unexpected event details after update: %v %v", event.EndsAt, event.Location)
+	}
+
+	if err = s.UpdateEventDetails("00000000-0000-0000-0000-000000000000", nil, nil, nil); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown event, got %v", err)
+	}
+}
+
+func testEventStatus(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+
+	if err := s.UpdateEventStatus(eventID, models.StatusCancelled); err != nil {
+		t.Fatalf("UpdateEventStatus: %v", err)
+	}
+
+	if event := mustSelectEvent(t, s, eventID); event.Status != models.StatusCancelled || event.IsActive() {
+		t.Errorf("expected cancelled event, got %s", event.Status)
+	}
+
+	if err := s.UpdateEventStatus("00000000-0000-0000-0000-000000000000", models.StatusOpen); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown event, got %v", err)
+	}
+}
+
+func testEventTargeting(t *testing.T, s Store) {
+	firstID := mustInsertEvent(t, s, 1, "Friday")
+	secondID := mustInsertEvent(t, s, 1, "Saturday")
+	otherID := mustInsertEvent(t, s, 2, "Other chat")
+	closedID := mustInsertEvent(t, s, 1, "Closed")
+
+	if err := s.UpdateEventStatus(closedID, models.StatusClosed); err != nil {
+		t.Fatalf("UpdateEventStatus: %v", err)
+	}
+
+	events, err := s.SelectActiveEvents(1)
+	if err != nil {
+		t.Fatalf("SelectActiveEvents: %v", err)
+	}
+
+	if len(events) != 2 || events[0].ID != secondID || events[1].ID != firstID {
+		t.Fatalf("expected the two active events newest first, got %+v", events)
+	}
+
+	if err = s.UpdateEventMessageID(firstID, 42); err != nil {
+		t.Fatalf("UpdateEventMessageID: %v", err)
+	}
+
+	if err = s.UpdateEventMessageID(otherID, 42); err != nil {
+		t.Fatalf("UpdateEventMessageID: %v", err)
+	}
+
+	event, err := s.SelectEventByMessageID(1, 42)
+	if err != nil {
+		t.Fatalf("SelectEventByMessageID: %v", err)
+	}
+
+	if event.ID != firstID {
+		t.Errorf("expected event %s by message id, got %s", firstID, event.ID)
+	}
+
+	if _, err = s.SelectEventByMessageID(1, 43); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown message, got %v", err)
+	}
+
+	second := mustSelectEvent(t, s, secondID)
+	if event, err = s.SelectEventByCode(1, strings.ToUpper(second.Code())); err != nil {
+		t.Fatalf("SelectEventByCode: %v", err)
+	}
+
+	if event.ID != secondID {
+		t.Errorf("expected event %s by code, got %s", secondID, event.ID)
+	}
+
+	if _, err = s.SelectEventByCode(2, second.Code()); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for code of another chat, got %v", err)
+	}
+
+	boardGameID := mustInsertBoardGame(t, s, secondID, "Catan", 4)
+	if err = s.UpdateBoardGameMessageID(boardGameID, 7); err != nil {
+		t.Fatalf("UpdateBoardGameMessageID: %v", err)
+	}
+
+	if event, err = s.SelectEventByBoardGameMessageID(1, 7); err != nil {
+		t.Fatalf("SelectEventByBoardGameMessageID: %v", err)
+	}
+
+	if event.ID != secondID || len(event.BoardGames) != 1 {
+		t.Errorf("unexpected event by board game message: %+v", event)
+	}
+
+	if _, err = s.SelectEventByBoardGameMessageID(2, 7); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for board game of another chat, got %v", err)
+	}
+}
+
+func testEventSummaries(t *testing.T, s Store) {
+	closedID := mustInsertEvent(t, s, 1, "Closed")
+	firstID := mustInsertEvent(t, s, 1, "Friday")
+	secondID := mustInsertEvent(t, s, 1, "Saturday")
+	mustInsertEvent(t, s, 2, "Other chat")
+
+	if err := s.UpdateEventStatus(closedID, models.StatusClosed); err != nil {
+		t.Fatalf("UpdateEventStatus: %v", err)
+	}
+
+	counterID := mustInsertBoardGame(t, s, firstID, models.PLAYER_COUNTER, -1)
+	catanID := mustInsertBoardGame(t, s, firstID, "Catan", 1)
+	mustInsertBoardGame(t, s, firstID, "Azul", 4)
+	mustInsertParticipant(t, s, firstID, counterID, 100, "bob")
+	mustInsertParticipant(t, s, firstID, catanID, 101, "carol")
+	mustInsertParticipant(t, s, firstID, catanID, 102, "dave")
+
+	count, err := s.CountEvents(1)
+	if err != nil {
+		t.Fatalf("CountEvents: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("expected 3 events in chat 1, got %d", count)
+	}
+
+	page, err := s.SelectEventSummaries(1, 2, 0)
+	if err != nil {
+		t.Fatalf("SelectEventSummaries: %v", err)
+	}
+
+	if len(page) != 2 || page[0].ID != secondID || page[1].ID != firstID {
+		t.Fatalf("expected the active events newest first, got %+v", page)
+	}
+
+	if page[1].Games != 2 || page[1].Participants != 2 || len(page[1].BoardGames) != 0 {
+		t.Errorf("expected 2 games and 2 seated players without details, got %+v", page[1])
+	}
+
+	if page, err = s.SelectEventSummaries(1, 2, 2); err != nil {
+		t.Fatalf("SelectEventSummaries: %v", err)
+	}
+
+	if len(page) != 1 || page[0].ID != closedID || page[0].Status != models.StatusClosed {
+		t.Fatalf("expected the closed event on the last page, got %+v", page)
+	}
+}
+
+func testBoardGames(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+
+	bggID := int64(13)
+	bggName, bggUrl, bggImageUrl, initiator := "Catan", "https://boardgamegeek.com/boardgame/13", "https://img/13.png", "alice"
+
+	initiatorID := int64(10)
+	catanID, err := s.InsertBoardGame(eventID, "Catan", 4, &bggID, &bggName, &bggUrl, &bggImageUrl, &initiatorID, &initiator)
+	if err != nil {
+		t.Fatalf("InsertBoardGame: %v", err)
+	}
+
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+	secondAzulID := mustInsertBoardGame(t, s, eventID, "Azul", 2)
+
+	event := mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames) != 3 {
+		t.Fatalf("expected 3 board games, got %d", len(event.BoardGames))
+	}
+
+	order := []int64{azulID, secondAzulID, catanID}
+	for i, id := range order {
+		if event.BoardGames[i].ID != id {
+			t.Errorf("board game %d: expected id %d, got %d", i, id, event.BoardGames[i].ID)
+		}
+	}
+
+	catan := event.BoardGames[2]
+	if catan.MaxPlayers != 4 || catan.BggID == nil || *catan.BggID != bggID || *catan.BggName != bggName || *catan.BggUrl != bggUrl || *catan.BggImageUrl != bggImageUrl {
+		t.Errorf("unexpected bgg info: %+v", catan)
+	}
+
+	if catan.InitiatorName == nil || *catan.InitiatorName != initiator {
+		t.Errorf("expected initiator %s, got %v", initiator, catan.InitiatorName)
+	}
+
+	if !catan.IsBroughtBy(initiatorID) || *catan.BringerName != initiator {
+		t.Errorf("expected the initiator to bring the game, got %+v", catan)
+	}
+
+	if err = s.DeleteBoardGameByID(azulID); err != nil {
+		t.Fatalf("DeleteBoardGameByID: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames) != 2 {
+		t.Errorf("expected 2 board games after delete, got %d", len(event.BoardGames))
+	}
+}
+
+func testBoardGameUpdates(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	boardGameID := mustInsertBoardGame(t, s, eventID, "Catan", 4)
+
+	if s.HasBoardGameWithMessageID(1, 7) {
+		t.Errorf("expected no board game with message id 7")
+	}
+
+	if err := s.UpdateBoardGamePlayerNumber(1, 7, 6); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown message, got %v", err)
+	}
+
+	if err := s.UpdateBoardGameMessageID(boardGameID, 7); err != nil {
+		t.Fatalf("UpdateBoardGameMessageID: %v", err)
+	}
+
+	if !s.HasBoardGameWithMessageID(1, 7) {
+		t.Errorf("expected board game with message id 7")
+	}
+
+	if s.HasBoardGameWithMessageID(2, 7) {
+		t.Errorf("expected message id 7 to be scoped to its chat")
+	}
+
+	if err := s.UpdateBoardGamePlayerNumber(2, 7, 6); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for message of another chat, got %v", err)
+	}
+
+	if err := s.UpdateBoardGamePlayerNumber(1, 7, 6); err != nil {
+		t.Fatalf("UpdateBoardGamePlayerNumber: %v", err)
+	}
+
+	if bg := mustSelectEvent(t, s, eventID).BoardGames[0]; bg.MaxPlayers != 6 {
+		t.Errorf("expected 6 max players, got %d", bg.MaxPlayers)
+	}
+
+	bggID := int64(13)
+	bggName := "Catan"
+	if err := s.UpdateBoardGameBGGInfo(1, 7, 3, &bggID, &bggName, nil, nil); err != nil {
+		t.Fatalf("UpdateBoardGameBGGInfo: %v", err)
+	}
+
+	bg := mustSelectEvent(t, s, eventID).BoardGames[0]
+	if bg.MaxPlayers != 3 || bg.BggID == nil || *bg.BggID != 13 || bg.BggUrl != nil {
+		t.Errorf("unexpected board game after bgg update: %+v", bg)
+	}
+
+	if err := s.UpdateBoardGameBGGInfoByID(boardGameID, 5, nil, nil, nil, nil); err != nil {
+		t.Fatalf("UpdateBoardGameBGGInfoByID: %v", err)
+	}
+
+	bg = mustSelectEvent(t, s, eventID).BoardGames[0]
+	if bg.MaxPlayers != 5 || bg.BggID != nil || bg.BggName != nil {
+		t.Errorf("unexpected board game after unlink: %+v", bg)
+	}
+
+	if err := s.UpdateBoardGameBGGInfoByID(boardGameID+1000, 5, nil, nil, nil, nil); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown board game, got %v", err)
+	}
+
+	if err := s.UpdateBoardGamePlayerNumberByID(boardGameID, 2); err != nil {
+		t.Fatalf("UpdateBoardGamePlayerNumberByID: %v", err)
+	}
+
+	if err := s.UpdateBoardGameName(boardGameID, "Catan: Seafarers"); err != nil {
+		t.Fatalf("UpdateBoardGameName: %v", err)
+	}
+
+	bg = mustSelectEvent(t, s, eventID).BoardGames[0]
+	if bg.MaxPlayers != 2 || bg.Name != "Catan: Seafarers" {
+		t.Errorf("unexpected board game after rename: %+v", bg)
+	}
+
+	if err := s.UpdateBoardGamePlayerNumberByID(boardGameID+1000, 2); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown board game, got %v", err)
+	}
+
+	if err := s.UpdateBoardGameName(boardGameID+1000, "Azul"); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown board game, got %v", err)
+	}
+}
+
+func testParticipants(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	catanID := mustInsertBoardGame(t, s, eventID, "Catan", 4)
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+
+	for _, p := range []struct {
+		userID   int64
+		userName string
+	}{{2, "zoe"}, {1, "bob"}} {
+		if _, err := s.InsertParticipant(eventID, catanID, p.userID, p.userName); err != nil {
+			t.Fatalf("InsertParticipant: %v", err)
+		}
+	}
+
+	event := mustSelectEvent(t, s, eventID)
+	catan := event.BoardGames[1]
+	if len(catan.Participants) != 2 || catan.Participants[0].UserName != "bob" || catan.Participants[1].UserName != "zoe" {
+		t.Fatalf("unexpected participants: %+v", catan.Participants)
+	}
+
+	// joining another game moves the participant
+	if _, err := s.InsertParticipant(eventID, azulID, 1, "bob"); err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames[0].Participants) != 1 || len(event.BoardGames[1].Participants) != 1 {
+		t.Fatalf("expected participant to move to the other game: %+v", event.BoardGames)
+	}
+
+	if err := s.RemoveParticipant(eventID, 1); err != nil {
+		t.Fatalf("RemoveParticipant: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames[0].Participants) != 0 {
+		t.Errorf("expected participant to be removed: %+v", event.BoardGames[0].Participants)
+	}
+
+	if err := s.DeleteBoardGameByID(catanID); err != nil {
+		t.Fatalf("DeleteBoardGameByID: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames) != 1 || len(event.BoardGames[0].Participants) != 0 {
+		t.Errorf("unexpected event after delete: %+v", event.BoardGames)
+	}
+}
+
+func mustInsertParticipant(t *testing.T, s Store, eventID string, boardGameID, userID int64, userName string) {
+	t.Helper()
+
+	if _, err := s.InsertParticipant(eventID, boardGameID, userID, userName); err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+}
+
+func userNames(participants []models.Participant) []string {
+	names := []string{}
+	for _, p := range participants {
+		names = append(names, p.UserName)
+	}
+
+	return names
+}
+
+func testWaitlist(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 2)
+	catanID := mustInsertBoardGame(t, s, eventID, "Catan", 2)
+
+	mustInsertParticipant(t, s, eventID, catanID, 1, "ann")
+	mustInsertParticipant(t, s, eventID, catanID, 2, "bob")
+	mustInsertParticipant(t, s, eventID, catanID, 3, "zoe")
+	mustInsertParticipant(t, s, eventID, catanID, 4, "dan")
+
+	before := mustSelectEvent(t, s, eventID)
+	catan := before.BoardGames[1]
+	if got := strings.Join(userNames(catan.Participants), ","); got != "ann,bob" {
+		t.Fatalf("expected ann and bob seated, got %s", got)
+	}
+
+	if got := strings.Join(userNames(catan.Waitlist), ","); got != "zoe,dan" {
+		t.Fatalf("expected zoe then dan waiting, got %s", got)
+	}
+
+	if !catan.Waitlist[0].Waitlisted || catan.Participants[0].Waitlisted {
+		t.Errorf("unexpected waitlisted flags: %+v", catan)
+	}
+
+	// joining the same game again keeps the position in the waitlist
+	mustInsertParticipant(t, s, eventID, catanID, 3, "zoe")
+	if got := strings.Join(userNames(mustSelectEvent(t, s, eventID).BoardGames[1].Waitlist), ","); got != "zoe,dan" {
+		t.Errorf("expected waitlist order to be kept, got %s", got)
+	}
+
+	// leaving seats the first one waiting
+	if err := s.RemoveParticipant(eventID, 1); err != nil {
+		t.Fatalf("RemoveParticipant: %v", err)
+	}
+
+	after := mustSelectEvent(t, s, eventID)
+	catan = after.BoardGames[1]
+	if got := strings.Join(userNames(catan.Participants), ","); got != "bob,zoe" {
+		t.Errorf("expected zoe to be promoted, got %s", got)
+	}
+
+	promotions := models.Promotions(before, after)
+	if len(promotions) != 1 || promotions[0].Participant.UserID != 3 || promotions[0].BoardGame.ID != catanID {
+		t.Errorf("unexpected promotions: %+v", promotions)
+	}
+
+	// moving to another game frees the seat as well
+	mustInsertParticipant(t, s, eventID, azulID, 2, "bob")
+	catan = mustSelectEvent(t, s, eventID).BoardGames[1]
+	if got := strings.Join(userNames(catan.Participants), ","); got != "dan,zoe" || len(catan.Waitlist) != 0 {
+		t.Errorf("expected dan to be promoted, got %s waiting %d", got, len(catan.Waitlist))
+	}
+
+	// raising the number of players seats the waitlist
+	mustInsertParticipant(t, s, eventID, catanID, 5, "eve")
+	if err := s.UpdateBoardGameBGGInfoByID(catanID, 3, nil, nil, nil, nil); err != nil {
+		t.Fatalf("UpdateBoardGameBGGInfoByID: %v", err)
+	}
+
+	catan = mustSelectEvent(t, s, eventID).BoardGames[1]
+	if len(catan.Participants) != 3 || len(catan.Waitlist) != 0 {
+		t.Errorf("expected eve to be promoted: %+v", catan)
+	}
+
+	if _, err := s.InsertParticipant(eventID, catanID+1000, 6, "max"); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown game, got %v", err)
+	}
+}
+
+func testMultipleTables(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "All day")
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 1)
+	catanID := mustInsertBoardGame(t, s, eventID, "Catan", 4)
+
+	if event := mustSelectEvent(t, s, eventID); event.JoinMode != models.JoinSingle {
+		t.Errorf("expected single join mode by default, got %s", event.JoinMode)
+	}
+
+	if err := s.UpdateEventJoinMode(eventID, models.JoinMultiple); err != nil {
+		t.Fatalf("UpdateEventJoinMode: %v", err)
+	}
+
+	mustInsertParticipant(t, s, eventID, azulID, 1, "ann")
+	mustInsertParticipant(t, s, eventID, catanID, 1, "ann")
+	mustInsertParticipant(t, s, eventID, azulID, 2, "bob")
+	mustInsertParticipant(t, s, eventID, catanID, 2, "bob")
+
+	event := mustSelectEvent(t, s, eventID)
+	if event.JoinMode != models.JoinMultiple {
+		t.Errorf("expected multiple join mode, got %s", event.JoinMode)
+	}
+
+	azul, catan := event.BoardGames[0], event.BoardGames[1]
+	if len(azul.Participants) != 1 || len(azul.Waitlist) != 1 || len(catan.Participants) != 2 {
+		t.Fatalf("expected players at both tables: %+v", event.BoardGames)
+	}
+
+	// leaving a single game keeps the other ones
+	if err := s.RemoveParticipantFromBoardGame(eventID, azulID, 1); err != nil {
+		t.Fatalf("RemoveParticipantFromBoardGame: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	azul, catan = event.BoardGames[0], event.BoardGames[1]
+	if got := strings.Join(userNames(azul.Participants), ","); got != "bob" || len(azul.Waitlist) != 0 {
+		t.Errorf("expected bob to be promoted at azul, got %s", got)
+	}
+
+	if len(catan.Participants) != 2 {
+		t.Errorf("expected ann to stay at catan: %+v", catan.Participants)
+	}
+
+	if err := s.RemoveParticipant(eventID, 2); err != nil {
+		t.Fatalf("RemoveParticipant: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames[0].Participants) != 0 || strings.Join(userNames(event.BoardGames[1].Participants), ",") != "ann" {
+		t.Errorf("expected bob to leave every game: %+v", event.BoardGames)
+	}
+
+	// back to a single table, joining moves the player again
+	if err := s.UpdateEventJoinMode(eventID, models.JoinSingle); err != nil {
+		t.Fatalf("UpdateEventJoinMode: %v", err)
+	}
+
+	mustInsertParticipant(t, s, eventID, azulID, 1, "ann")
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.BoardGames[0].Participants) != 1 || len(event.BoardGames[1].Participants) != 0 {
+		t.Errorf("expected ann to move to azul: %+v", event.BoardGames)
+	}
+
+	if err := s.UpdateEventJoinMode("unknown", models.JoinMultiple); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown event, got %v", err)
+	}
+}
+
+func testAuditLog(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	otherID := mustInsertEvent(t, s, 1, "Saturday")
+
+	game := "Catan"
+	before := "4"
+	after := "6"
+	createdAt := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	entries := []models.AuditEntry{
+		{EventID: eventID, UserID: 10, UserName: "alice", Action: models.AuditGameAdded, Target: &game, After: &before, Source: models.SourceBot, CreatedAt: createdAt},
+		{EventID: otherID, UserID: 10, UserName: "alice", Action: models.AuditPlayerJoined, Target: &game, Source: models.SourceBot, CreatedAt: createdAt},
+		{EventID: eventID, UserID: 11, UserName: "bob", Action: models.AuditMaxPlayersChanged, Target: &game, Before: &before, After: &after, Source: models.SourceWeb, CreatedAt: createdAt.Add(time.Minute)},
+	}
+
+	for _, entry := range entries {
+		if err := s.InsertAuditEntry(entry); err != nil {
+			t.Fatalf("InsertAuditEntry: %v", err)
+		}
+	}
+
+	log, err := s.SelectAuditLog(eventID, 10)
+	if err != nil {
+		t.Fatalf("SelectAuditLog: %v", err)
+	}
+
+	if len(log) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(log))
+	}
+
+	latest := log[0]
+	if latest.Action != models.AuditMaxPlayersChanged || latest.UserName != "bob" || latest.Source != models.SourceWeb ||
+		latest.Target == nil || *latest.Target != "Catan" || latest.Before == nil || *latest.Before != "4" || latest.After == nil || *latest.After != "6" {
+		t.Errorf("unexpected latest entry: %+v", latest)
+	}
+
+	if !latest.CreatedAt.Equal(createdAt.Add(time.Minute)) {
+		t.Errorf("expected created at %s, got %s", createdAt.Add(time.Minute), latest.CreatedAt)
+	}
+
+	if log[1].Action != models.AuditGameAdded || log[1].Before != nil {
+		t.Errorf("unexpected first entry: %+v", log[1])
+	}
+
+	if log, err = s.SelectAuditLog(eventID, 1); err != nil || len(log) != 1 || log[0].Action != models.AuditMaxPlayersChanged {
+		t.Errorf("expected only the latest entry, got %+v (%v)", log, err)
+	}
+}
+
+func testReminders(t *testing.T, s Store) {
+	startsAt := time.Date(2026, 10, 23, 20, 0, 0, 0, time.UTC)
+
+	scheduledID, err := s.InsertEvent(1, 10, "alice", "Scheduled", nil, &startsAt, nil, nil, models.StatusOpen)
+	if err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	closedID, err := s.InsertEvent(1, 10, "alice", "Closed", nil, &startsAt, nil, nil, models.StatusClosed)
+	if err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	mustInsertEvent(t, s, 1, "Unscheduled")
+
+	events, err := s.SelectScheduledEvents()
+	if err != nil {
+		t.Fatalf("SelectScheduledEvents: %v", err)
+	}
+
+	if len(events) != 1 || events[0].ID != scheduledID || events[0].StartsAt == nil || !events[0].StartsAt.Equal(startsAt) {
+		t.Fatalf("expected only the scheduled event, got %+v", events)
+	}
+
+	if claimed, err := s.ClaimReminder(scheduledID, startsAt, 2*time.Hour); err != nil || !claimed {
+		t.Fatalf("expected first claim to succeed, got %v %v", claimed, err)
+	}
+
+	if claimed, err := s.ClaimReminder(scheduledID, startsAt, 2*time.Hour); err != nil || claimed {
+		t.Errorf("expected second claim to fail, got %v %v", claimed, err)
+	}
+
+	if claimed, err := s.ClaimReminder(scheduledID, startsAt, 24*time.Hour); err != nil || !claimed {
+		t.Errorf("expected claim of another offset to succeed, got %v %v", claimed, err)
+	}
+
+	// a rescheduled event is reminded again
+	if claimed, err := s.ClaimReminder(scheduledID, startsAt.Add(time.Hour), 2*time.Hour); err != nil || !claimed {
+		t.Errorf("expected claim after reschedule to succeed, got %v %v", claimed, err)
+	}
+
+	if claimed, err := s.ClaimReminder(closedID, startsAt, 2*time.Hour); err != nil || !claimed {
+		t.Errorf("expected claim of another event to succeed, got %v %v", claimed, err)
+	}
+}
+
+func testUsers(t *testing.T, s Store) {
+	if _, err := s.SelectUser(10); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown user, got %v", err)
+	}
+
+	chatID := int64(10)
+	if err := s.UpsertUser(models.User{ID: 10, UserName: "alice", ChatID: &chatID, Reminders: true}); err != nil {
+		t.Fatalf("UpsertUser: %v", err)
+	}
+
+	if err := s.UpsertUser(models.User{ID: 10, UserName: "alice_", ChatID: &chatID, NotifyFull: true, NotifySeat: true}); err != nil {
+		t.Fatalf("UpsertUser: %v", err)
+	}
+
+	user, err := s.SelectUser(10)
+	if err != nil {
+		t.Fatalf("SelectUser: %v", err)
+	}
+
+	if user.UserName != "alice_" || user.ChatID == nil || *user.ChatID != chatID || user.Reminders {
+		t.Errorf("unexpected user: %+v", user)
+	}
+
+	if !user.NotifyFull || user.NotifyDeleted || user.NotifyMaxPlayers || !user.NotifySeat {
+		t.Errorf("unexpected notifications: %+v", user)
+	}
+}
+
+func testSeries(t *testing.T, s Store) {
+	thursday := time.Thursday
+	location := "Bob's place"
+
+	weeklyID, err := s.InsertSeries(models.Series{
+		ChatID:    1,
+		UserID:    10,
+		UserName:  "alice",
+		Name:      "Thursday night",
+		Frequency: models.FrequencyWeekly,
+		Weekday:   &thursday,
+		Time:      "20:30",
+		Location:  &location,
+		Games:     []string{"Catan", "Azul"},
+		Locked:    true,
+	})
+	if err != nil {
+		t.Fatalf("InsertSeries: %v", err)
+	}
+
+	monthlyID, err := s.InsertSeries(models.Series{ChatID: 1, UserID: 10, UserName: "alice", Name: "Monthly", Frequency: models.FrequencyMonthly, Day: 15, Time: "18:00"})
+	if err != nil {
+		t.Fatalf("InsertSeries: %v", err)
+	}
+
+	if _, err = s.InsertSeries(models.Series{ChatID: 2, UserID: 10, UserName: "alice", Name: "Elsewhere", Frequency: models.FrequencyMonthly, Day: 1, Time: "18:00"}); err != nil {
+		t.Fatalf("InsertSeries: %v", err)
+	}
+
+	series, err := s.SelectSeriesByID(weeklyID)
+	if err != nil {
+		t.Fatalf("SelectSeriesByID: %v", err)
+	}
+
+	if series.Name != "Thursday night" || series.Weekday == nil || *series.Weekday != thursday || series.Time != "20:30" || !series.Locked || series.Status != models.SeriesActive ||
+		series.Location == nil || *series.Location != location || len(series.Games) != 2 || series.Games[0] != "Catan" || series.Games[1] != "Azul" || series.LastStartsAt != nil {
+		t.Fatalf("unexpected series: %+v", series)
+	}
+
+	if _, err = s.SelectSeriesByID(999); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown series, got %v", err)
+	}
+
+	inChat, err := s.SelectSeries(1)
+	if err != nil {
+		t.Fatalf("SelectSeries: %v", err)
+	}
+
+	if len(inChat) != 2 || inChat[0].ID != weeklyID || inChat[1].ID != monthlyID || len(inChat[1].Games) != 0 || inChat[1].Weekday != nil || inChat[1].Day != 15 {
+		t.Fatalf("unexpected series of the chat: %+v", inChat)
+	}
+
+	startsAt := time.Date(2026, 10, 22, 20, 30, 0, 0, time.UTC)
+	if claimed, err := s.ClaimSeriesEvent(weeklyID, startsAt); err != nil || !claimed {
+		t.Fatalf("expected first claim to succeed, got %v %v", claimed, err)
+	}
+
+	if claimed, err := s.ClaimSeriesEvent(weeklyID, startsAt); err != nil || claimed {
+		t.Errorf("expected second claim to fail, got %v %v", claimed, err)
+	}
+
+	if series = mustSelectSeries(t, s, weeklyID); series.LastStartsAt == nil || !series.LastStartsAt.Equal(startsAt) {
+		t.Errorf("unexpected last start: %v", series.LastStartsAt)
+	}
+
+	if err = s.UpdateSeriesStatus(weeklyID, models.SeriesPaused); err != nil {
+		t.Fatalf("UpdateSeriesStatus: %v", err)
+	}
+
+	if claimed, err := s.ClaimSeriesEvent(weeklyID, startsAt.AddDate(0, 0, 7)); err != nil || claimed {
+		t.Errorf("expected claim of a paused series to fail, got %v %v", claimed, err)
+	}
+
+	active, err := s.SelectActiveSeries()
+	if err != nil {
+		t.Fatalf("SelectActiveSeries: %v", err)
+	}
+
+	if len(active) != 2 {
+		t.Errorf("expected 2 active series, got %d", len(active))
+	}
+
+	if err = s.UpdateSeriesStatus(monthlyID, models.SeriesStopped); err != nil {
+		t.Fatalf("UpdateSeriesStatus: %v", err)
+	}
+
+	if inChat, err = s.SelectSeries(1); err != nil || len(inChat) != 1 || inChat[0].Status != models.SeriesPaused {
+		t.Errorf("expected only the paused series, got %+v %v", inChat, err)
+	}
+
+	if err = s.UpdateSeriesStatus(999, models.SeriesPaused); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown series, got %v", err)
+	}
+}
+
+func mustSelectSeries(t *testing.T, s Store, ID int64) *models.Series {
+	t.Helper()
+
+	series, err := s.SelectSeriesByID(ID)
+	if err != nil {
+		t.Fatalf("SelectSeriesByID: %v", err)
+	}
+
+	return series
+}
+
+func testCollections(t *testing.T, s Store) {
+	if _, err := s.SelectBGGAccount(10); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown account, got %v", err)
+	}
+
+	if err := s.ReplaceCollection(10, nil, time.Now()); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown account, got %v", err)
+	}
+
+	chatID := int64(100)
+	if err := s.LinkBGGAccount(models.BGGAccount{UserID: 10, UserName: "alice", BGGUsername: "alice_bgg"}, &chatID); err != nil {
+		t.Fatalf("LinkBGGAccount: %v", err)
+	}
+	if err := s.LinkBGGAccount(models.BGGAccount{UserID: 11, UserName: "bob", BGGUsername: "bob_bgg"}, nil); err != nil {
+		t.Fatalf("LinkBGGAccount: %v", err)
+	}
+
+	accounts, err := s.SelectBGGAccountsToSync(time.Now())
+	if err != nil {
+		t.Fatalf("SelectBGGAccountsToSync: %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("expected 2 accounts to sync, got %+v", accounts)
+	}
+
+	syncedAt := time.Now()
+	if err = s.ReplaceCollection(10, []models.CollectionGame{{BGGID: 13, Name: "Catan", Year: 1995}, {BGGID: 266192, Name: "Wingspan"}}, syncedAt); err != nil {
+		t.Fatalf("ReplaceCollection: %v", err)
+	}
+	if err = s.ReplaceCollection(11, []models.CollectionGame{{BGGID: 13, Name: "Catan"}}, syncedAt); err != nil {
+		t.Fatalf("ReplaceCollection: %v", err)
+	}
+
+	if accounts, _ = s.SelectBGGAccountsToSync(syncedAt.Add(-time.Minute)); len(accounts) != 0 {
+		t.Errorf("expected no account to sync, got %+v", accounts)
+	}
+
+	account, err := s.SelectBGGAccount(10)
+	if err != nil {
+		t.Fatalf("SelectBGGAccount: %v", err)
+	}
+	if account.BGGUsername != "alice_bgg" || account.SyncedAt == nil || account.SyncedAt.Unix() != syncedAt.Unix() {
+		t.Errorf("unexpected account: %+v", account)
+	}
+
+	// bob did not link in the chat nor join its events yet
+	games, err := s.SelectChatCollection(chatID)
+	if err != nil {
+		t.Fatalf("SelectChatCollection: %v", err)
+	}
+	if len(games) != 2 || games[0].Name != "Catan" || games[0].UserName != "alice" || games[0].Year != 1995 || games[1].Name != "Wingspan" {
+		t.Fatalf("unexpected collection: %+v", games)
+	}
+
+	eventID := mustInsertEvent(t, s, chatID, "Game night")
+	bggID := int64(13)
+	boardGameID, err := s.InsertBoardGame(eventID, "Catan", 4, &bggID, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("InsertBoardGame: %v", err)
+	}
+	otherID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+
+	if event := mustSelectEvent(t, s, eventID); len(event.BoardGames[1].Owners) != 0 {
+		t.Errorf("expected no owners before anyone joins, got %v", event.BoardGames[1].Owners)
+	}
+
+	mustInsertParticipant(t, s, eventID, boardGameID, 10, "alice")
+	mustInsertParticipant(t, s, eventID, otherID, 11, "bob")
+
+	event := mustSelectEvent(t, s, eventID)
+	if got := event.BoardGames[1].Owners; len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Errorf("unexpected owners of Catan: %v", got)
+	}
+	if got := event.BoardGames[0].Owners; len(got) != 0 {
+		t.Errorf("unexpected owners of Azul: %v", got)
+	}
+
+	if games, _ = s.SelectChatCollection(chatID); len(games) != 3 {
+		t.Errorf("expected the collection of bob after joining, got %+v", games)
+	}
+
+	// a different account drops the previous collection
+	if err = s.LinkBGGAccount(models.BGGAccount{UserID: 11, UserName: "bob", BGGUsername: "robert"}, nil); err != nil {
+		t.Fatalf("LinkBGGAccount: %v", err)
+	}
+	if account, _ = s.SelectBGGAccount(11); account.SyncedAt != nil {
+		t.Errorf("expected the new account to be synced again, got %+v", account)
+	}
+	if games, _ = s.SelectChatCollection(chatID); len(games) != 2 {
+		t.Errorf("expected the collection of bob to be dropped, got %+v", games)
+	}
+
+	if err = s.UnlinkBGGAccount(10); err != nil {
+		t.Fatalf("UnlinkBGGAccount: %v", err)
+	}
+	if err = s.UnlinkBGGAccount(10); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows unlinking twice, got %v", err)
+	}
+	if games, _ = s.SelectChatCollection(chatID); len(games) != 0 {
+		t.Errorf("expected an empty collection, got %+v", games)
+	}
+}
+
+func testBringers(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+
+	counterName := "alice"
+	if _, err := s.InsertBoardGame(eventID, models.PLAYER_COUNTER, -1, nil, nil, nil, nil, nil, &counterName); err != nil {
+		t.Fatalf("InsertBoardGame: %v", err)
+	}
+
+	// a game added without knowing who added it has nobody bringing it
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+
+	event := mustSelectEvent(t, s, eventID)
+	for _, bg := range event.BoardGames {
+		if bg.BringerID != nil || bg.BringerName != nil {
+			t.Errorf("expected no bringer for %s, got %+v", bg.Name, bg)
+		}
+	}
+
+	if event.BoardGames[1].IsGame() || event.BoardGames[1].IsUnclaimed() || !event.BoardGames[0].IsUnclaimed() {
+		t.Errorf("expected only Azul to be unclaimed: %+v", event.BoardGames)
+	}
+
+	bob, bobName := int64(11), "bob"
+	if err := s.UpdateBoardGameBringer(azulID, &bob, &bobName); err != nil {
+		t.Fatalf("UpdateBoardGameBringer: %v", err)
+	}
+
+	azul := mustSelectEvent(t, s, eventID).BoardGames[0]
+	if !azul.IsBroughtBy(bob) || azul.IsUnclaimed() || *azul.BringerName != bobName {
+		t.Errorf("expected bob to bring Azul, got %+v", azul)
+	}
+
+	if err := s.UpdateBoardGameBringer(azulID, nil, &bobName); err != nil {
+		t.Fatalf("UpdateBoardGameBringer: %v", err)
+	}
+
+	if azul = mustSelectEvent(t, s, eventID).BoardGames[0]; !azul.IsUnclaimed() || azul.BringerID != nil {
+		t.Errorf("expected Azul to be unclaimed, got %+v", azul)
+	}
+
+	if err := s.UpdateBoardGameBringer(9999, &bob, &bobName); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown game, got %v", err)
+	}
+}
+
+func testPolls(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+	catanID := mustInsertBoardGame(t, s, eventID, "Catan", 4)
+
+	if _, err := s.SelectPoll(eventID); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows before the vote, got %v", err)
+	}
+
+	if err := s.ReplaceBallot(eventID, 10, "alice", []int64{azulID}); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows voting without a poll, got %v", err)
+	}
+
+	if err := s.InsertPoll(models.Poll{EventID: eventID, Method: models.VoteRanked, Winners: 1}); err != nil {
+		t.Fatalf("InsertPoll: %v", err)
+	}
+
+	if err := s.ReplaceBallot(eventID, 10, "alice", []int64{catanID, azulID}); err != nil {
+		t.Fatalf("ReplaceBallot: %v", err)
+	}
+
+	if err := s.ReplaceBallot(eventID, 11, "bob", []int64{azulID}); err != nil {
+		t.Fatalf("ReplaceBallot: %v", err)
+	}
+
+	// a new ballot replaces the previous one
+	if err := s.ReplaceBallot(eventID, 11, "bob", []int64{azulID, catanID}); err != nil {
+		t.Fatalf("ReplaceBallot: %v", err)
+	}
+
+	if err := s.UpdatePollMessageID(eventID, 42); err != nil {
+		t.Fatalf("UpdatePollMessageID: %v", err)
+	}
+
+	poll, err := s.SelectPoll(eventID)
+	if err != nil {
+		t.Fatalf("SelectPoll: %v", err)
+	}
+
+	if !poll.IsOpen() || poll.Method != models.VoteRanked || poll.Winners != 1 || poll.MessageID == nil || *poll.MessageID != 42 {
+		t.Errorf("unexpected poll %+v", poll)
+	}
+
+	if ballot := poll.Ballot(10); len(ballot) != 2 || ballot[0] != catanID || ballot[1] != azulID {
+		t.Errorf("expected alice to rank Catan then Azul, got %v", ballot)
+	}
+
+	if ballot := poll.Ballot(11); len(ballot) != 2 || ballot[0] != azulID {
+		t.Errorf("expected bob to rank Azul first, got %v", ballot)
+	}
+
+	if poll.Voters() != 2 {
+		t.Errorf("expected 2 voters, got %d", poll.Voters())
+	}
+
+	if err := s.ReplaceBallot(eventID, 10, "alice", []int64{}); err != nil {
+		t.Fatalf("ReplaceBallot: %v", err)
+	}
+
+	if poll, _ = s.SelectPoll(eventID); poll.Voters() != 1 {
+		t.Errorf("expected the empty ballot to remove the votes, got %+v", poll.Votes)
+	}
+
+	closed, err := s.ClosePoll(eventID)
+	if err != nil || !closed {
+		t.Fatalf("ClosePoll: %v %v", closed, err)
+	}
+
+	if closed, err = s.ClosePoll(eventID); err != nil || closed {
+		t.Errorf("expected the poll to be closed once, got %v %v", closed, err)
+	}
+
+	if err := s.ReplaceBallot(eventID, 10, "alice", []int64{azulID}); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows voting on a closed poll, got %v", err)
+	}
+
+	// starting the vote again drops the old ballots
+	if err := s.InsertPoll(models.Poll{EventID: eventID, Method: models.VoteApproval, Winners: 2}); err != nil {
+		t.Fatalf("InsertPoll: %v", err)
+	}
+
+	if poll, _ = s.SelectPoll(eventID); !poll.IsOpen() || len(poll.Votes) != 0 || poll.MessageID != nil || poll.Method != models.VoteApproval {
+		t.Errorf("expected a new empty poll, got %+v", poll)
+	}
+
+	if err := s.UpdatePollMessageID("missing", 1); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows for unknown poll, got %v", err)
+	}
+}
+
+func testOrganizers(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+
+	event := mustSelectEvent(t, s, eventID)
+	if len(event.Organizers) != 0 {
+		t.Fatalf("expected no co-organizers, got %+v", event.Organizers)
+	}
+
+	if err := s.InsertEventOrganizer(eventID, 12, "carol"); err != nil {
+		t.Fatalf("InsertEventOrganizer: %v", err)
+	}
+
+	if err := s.InsertEventOrganizer(eventID, 11, "bob"); err != nil {
+		t.Fatalf("InsertEventOrganizer: %v", err)
+	}
+
+	// naming them again only updates the name
+	if err := s.InsertEventOrganizer(eventID, 11, "bobby"); err != nil {
+		t.Fatalf("InsertEventOrganizer: %v", err)
+	}
+
+	event = mustSelectEvent(t, s, eventID)
+	if len(event.Organizers) != 2 || event.Organizers[0].UserName != "bobby" || event.Organizers[1].UserID != 12 {
+		t.Fatalf("unexpected co-organizers %+v", event.Organizers)
+	}
+
+	if !event.IsOrganizer(11) || event.IsOrganizer(13) {
+		t.Errorf("expected only the named users to be organizers: %+v", event.Organizers)
+	}
+
+	if err := s.UpdateEventStatus(eventID, models.StatusLocked); err != nil {
+		t.Fatalf("UpdateEventStatus: %v", err)
+	}
+
+	if event = mustSelectEvent(t, s, eventID); !event.CanEdit(12) || event.CanEdit(13) {
+		t.Errorf("expected the co-organizers to edit the locked event")
+	}
+
+	if err := s.DeleteEventOrganizer(eventID, 12); err != nil {
+		t.Fatalf("DeleteEventOrganizer: %v", err)
+	}
+
+	if err := s.DeleteEventOrganizer(eventID, 12); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows removing a user twice, got %v", err)
+	}
+
+	if event = mustSelectEvent(t, s, eventID); len(event.Organizers) != 1 || event.CanEdit(12) {
+		t.Errorf("expected carol to be removed, got %+v", event.Organizers)
+	}
+}
+
+func testPlays(t *testing.T, s Store) {
+	eventID := mustInsertEvent(t, s, 1, "Friday")
+	catanID := mustInsertBoardGame(t, s, eventID, "Catan", 4)
+
+	if _, err := s.SelectPlay(catanID); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows before the result, got %v", err)
+	}
+
+	bggID := int64(13)
+	duration := 90
+	forty, thirty := int64(40), int64(30)
+	playedAt := time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+
+	play := models.Play{
+		EventID:         eventID,
+		BoardGameID:     catanID,
+		ChatID:          1,
+		Name:            "Catan",
+		BggID:           &bggID,
+		DurationMinutes: &duration,
+		UserID:          10,
+		UserName:        "alice",
+		PlayedAt:        playedAt,
+		Scores: []models.PlayScore{
+			{UserID: 11, UserName: "bob", Score: &thirty},
+			{UserID: 12, UserName: "carol"},
+			{UserID: 10, UserName: "alice", Score: &forty, Winner: true},
+		},
+	}
+
+	if _, err := s.InsertPlay(play); err != nil {
+		t.Fatalf("InsertPlay: %v", err)
+	}
+
+	got, err := s.SelectPlay(catanID)
+	if err != nil {
+		t.Fatalf("SelectPlay: %v", err)
+	}
+
+	if got.Name != "Catan" || got.ChatID != 1 || got.BggID == nil || *got.BggID != bggID || got.DurationMinutes == nil || *got.DurationMinutes != 90 || got.Notes != nil || !got.PlayedAt.Equal(playedAt) {
+		t.Fatalf("unexpected play %+v", got)
+	}
+
+	// the winners first, then by score, the players without one last
+	if len(got.Scores) != 3 || got.Scores[0].UserID != 10 || got.Scores[1].UserID != 11 || got.Scores[2].Score != nil {
+		t.Fatalf("unexpected scores %+v", got.Scores)
+	}
+
+	// recording the result again replaces it
+	notes := "rematch"
+	play.DurationMinutes = nil
+	play.Notes = &notes
+	play.Scores = []models.PlayScore{{UserID: 11, UserName: "bob", Winner: true}}
+
+	if _, err = s.InsertPlay(play); err != nil {
+		t.Fatalf("InsertPlay: %v", err)
+	}
+
+	if got, err = s.SelectPlay(catanID); err != nil {
+		t.Fatalf("SelectPlay: %v", err)
+	}
+
+	if got.DurationMinutes != nil || got.Notes == nil || *got.Notes != notes || len(got.Scores) != 1 || !got.Scores[0].Winner {
+		t.Errorf("unexpected replaced play %+v", got)
+	}
+
+	azulID := mustInsertBoardGame(t, s, eventID, "Azul", 4)
+	earlier := models.Play{
+		EventID:     eventID,
+		BoardGameID: azulID,
+		ChatID:      1,
+		Name:        "Azul",
+		UserID:      10,
+		UserName:    "alice",
+		PlayedAt:    playedAt.Add(-time.Hour),
+		Scores:      []models.PlayScore{{UserID: 10, UserName: "alice", Winner: true}, {UserID: 11, UserName: "bob"}},
+	}
+
+	if _, err = s.InsertPlay(earlier); err != nil {
+		t.Fatalf("InsertPlay: %v", err)
+	}
+
+	other := earlier
+	other.ChatID = 2
+	other.BoardGameID = mustInsertBoardGame(t, s, mustInsertEvent(t, s, 2, "Other"), "Azul", 4)
+	if _, err = s.InsertPlay(other); err != nil {
+		t.Fatalf("InsertPlay: %v", err)
+	}
+
+	// the plays of the chat, from the first one played
+	plays, err := s.SelectChatPlays(1)
+	if err != nil {
+		t.Fatalf("SelectChatPlays: %v", err)
+	}
+
+	if len(plays) != 2 || plays[0].Name != "Azul" || plays[1].Name != "Catan" || len(plays[0].Scores) != 2 || plays[0].Scores[0].UserID != 10 {
+		t.Errorf("unexpected chat plays %+v", plays)
+	}
+}
+
+func testChats(t *testing.T, s Store) {
+	if lang := s.GetPreferredLanguage(1); lang != "en" {
+		t.Errorf("expected default language en, got %s", lang)
+	}
+
+	if err := s.InsertChat(1, "it"); err != nil {
+		t.Fatalf("InsertChat: %v", err)
+	}
+
+	if err := s.InsertChat(1, "de"); err != nil {
+		t.Fatalf("InsertChat: %v", err)
+	}
+
+	if lang := s.GetPreferredLanguage(1); lang != "de" {
+		t.Errorf("expected language de, got %s", lang)
+	}
+}
@@ -7,16 +7,19 @@ import (
 	"boardgame-night-bot/src/telegram"
 	"boardgame-night-bot/src/web"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 
 	"time"
+	_ "time/tzdata"
 
 	"github.com/BurntSushi/toml"
 	"github.com/fzerorubigd/gobgg"
@@ -60,6 +63,91 @@ func InitHealthCheck(url string) {
 	log.Println("cron job started...")
 }
 
+func backup(db *database.Database, dir string, keep int) func() {
+	return func() {
+		path, err := db.Backup(dir)
+		if err != nil {
+			log.Println("error backing up database:", err)
+			return
+		}
+
+		log.Println("database backed up to", path)
+
+		if err = database.PruneBackups(dir, keep); err != nil {
+			log.Println("error removing old backups:", err)
+		}
+	}
+}
+
+func InitBackup(db *database.Database, dir, schedule string, keep int) {
+	if schedule == "off" {
+		log.Println("database backups are disabled")
+		return
+	}
+
+	c := cron.New()
+	_, err := c.AddFunc(schedule, backup(db, dir, keep))
+	if err != nil {
+		log.Println("error scheduling backup job:", err)
+		return
+	}
+
+	c.Start()
+	log.Printf("backup job scheduled %s in %s, keeping %d backups", schedule, dir, keep)
+}
+
+func InitReminders(t telegram.Telegram, reminders string) {
+	if reminders == "off" {
+		log.Println("event reminders are disabled")
+		return
+	}
+
+	offsets, err := models.ParseReminderOffsets(reminders)
+	if err != nil {
+		log.Fatal("the REMINDERS is not a valid list of durations: ", err)
+	}
+
+	c := cron.New()
+	_, err = c.AddFunc("@every 1m", func() {
+		t.SendReminders(offsets)
+	})
+	if err != nil {
+		log.Println("error scheduling reminders job:", err)
+		return
+	}
+
+	c.Start()
+	log.Printf("reminders job scheduled %v before the events", offsets)
+}
+
+func InitSeries(t telegram.Telegram) {
+	defer t.CreateSeriesEvents()
+
+	c := cron.New()
+	_, err := c.AddFunc("@every 10m", t.CreateSeriesEvents)
+	if err != nil {
+		log.Println("error scheduling series job:", err)
+		return
+	}
+
+	c.Start()
+	log.Printf("series job scheduled, events are created %d days ahead", t.SeriesDaysAhead)
+}
+
+func InitCollections(t telegram.Telegram, refresh time.Duration) {
+	c := cron.New()
+	_, err := c.AddFunc("@every 1h", func() {
+		t.SyncCollections(refresh)
+	})
+	if err != nil {
+		log.Println("error scheduling collections job:", err)
+		return
+	}
+
+	c.Start()
+	log.Printf("collections job scheduled, refreshed every %v", refresh)
+}
+
 func StringOrDefault(s, defaultValue string) string {
 	if s == "" {
 		return defaultValue
@@ -67,9 +155,40 @@ func StringOrDefault(s, defaultValue string) string {
 	return s
 }
 
+func OpenDatabase(databaseUrl, dbPath string) *database.Database {
+	if databaseUrl != "" {
+		log.Println("using postgres database")
+		return database.NewPostgresDatabase(databaseUrl)
+	}
+
+	log.Println("using sqlite database in", dbPath)
+	return database.NewDatabase(dbPath)
+}
+
+func PrintPendingMigrations(db *database.Database) {
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("no pending migrations")
+		return
+	}
+
+	for _, m := range pending {
+		fmt.Printf("%04d_%s\n", m.Version, m.Name)
+	}
+}
+
 func main() {
 	var err error
 
+	pendingMigrations := flag.Bool("pending-migrations", false, "print the pending database migrations and exit")
+	backupNow := flag.Bool("backup", false, "back up the sqlite database and exit")
+	restore := flag.String("restore", "", "validate the given backup and restore it as the sqlite database, the bot must be stopped")
+	flag.Parse()
+
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
 
@@ -90,6 +209,50 @@ func main() {
 		log.Default().Printf("warn loading .env file: %v", err)
 	}
 
+	if tz := os.Getenv("TZ"); tz != "" {
+		// time.Local is initialized before the .env file is loaded
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Fatal("the TZ is not a valid time zone: ", err)
+		}
+		time.Local = loc
+	}
+
+	dbPath := StringOrDefault(os.Getenv("DB_PATH"), "./archive")
+	databaseUrl := os.Getenv("DATABASE_URL")
+
+	backupDir := StringOrDefault(os.Getenv("BACKUP_DIR"), filepath.Join(dbPath, "backups"))
+	backupSchedule := StringOrDefault(os.Getenv("BACKUP_SCHEDULE"), "@daily")
+	backupRetention, err := strconv.Atoi(StringOrDefault(os.Getenv("BACKUP_RETENTION"), "7"))
+	if err != nil || backupRetention < 1 {
+		log.Fatal("the BACKUP_RETENTION is not a valid positive number")
+	}
+
+	if *pendingMigrations {
+		db := OpenDatabase(databaseUrl, dbPath)
+		defer db.Close()
+
+		PrintPendingMigrations(db)
+		return
+	}
+
+	if *restore != "" {
+		if err = database.RestoreBackup(*restore, dbPath); err != nil {
+			log.Fatal("failed to restore backup: ", err)
+		}
+
+		log.Println("backup restored in", dbPath)
+		return
+	}
+
+	if *backupNow {
+		db := OpenDatabase(databaseUrl, dbPath)
+		defer db.Close()
+
+		backup(db, backupDir, backupRetention)()
+		return
+	}
+
 	botToken := os.Getenv("TOKEN")
 	if botToken == "" {
 		log.Fatal("the TOKEN is not set in .env file")
@@ -100,53 +263,111 @@ func main() {
 		log.Fatal("the BOT_NAME is not set in .env file")
 	}
 
+	seriesDaysAhead, err := strconv.Atoi(StringOrDefault(os.Getenv("SERIES_DAYS_AHEAD"), strconv.Itoa(models.DefaultSeriesDaysAhead)))
+	if err != nil || seriesDaysAhead < 0 {
+		log.Fatal("the SERIES_DAYS_AHEAD is not a valid number of days")
+	}
+
+	collectionRefresh, err := time.ParseDuration(StringOrDefault(os.Getenv("BGG_COLLECTION_REFRESH"), models.DefaultCollectionRefresh.String()))
+	if err != nil || collectionRefresh <= 0 {
+		log.Fatal("the BGG_COLLECTION_REFRESH is not a valid duration")
+	}
+
+	editInterval, err := time.ParseDuration(StringOrDefault(os.Getenv("EDIT_INTERVAL"), models.DefaultEditInterval.String()))
+	if err != nil || editInterval < 0 {
+		log.Fatal("the EDIT_INTERVAL is not a valid duration")
+	}
+
 	healthCheckUrl := os.Getenv("HEALTH_CHECK_URL")
 	InitHealthCheck(healthCheckUrl)
 
 	baseUrl := os.Getenv("BASE_URL")
+	webhook, err := strconv.ParseBool(StringOrDefault(os.Getenv("WEBHOOK"), "false"))
+	if err != nil {
+		log.Fatal("the WEBHOOK is not a valid boolean")
+	}
+
+	if webhook && !strings.HasPrefix(baseUrl, "https://") {
+		log.Fatal("the BASE_URL must be an https url to receive the updates with a webhook")
+	}
+
 	portString := os.Getenv("PORT")
 	port, err := strconv.Atoi(portString)
 	if err != nil {
 		log.Fatal("the PORT is not set in .env file or is not a valid number")
 	}
 
-	dbPath := StringOrDefault(os.Getenv("DB_PATH"), "./archive")
-
-	db := database.NewDatabase(dbPath)
+	db := OpenDatabase(databaseUrl, dbPath)
 
 	defer db.Close()
 
 	log.Println("database connection established.")
 
-	db.CreateTables()
+	if err = db.Migrate(); err != nil {
+		log.Fatal("failed to migrate database: ", err)
+	}
+
+	if databaseUrl == "" {
+		InitBackup(db, backupDir, backupSchedule, backupRetention)
+	}
+
+	allowedUpdates := []string{"message", "callback_query", "inline_query", "chosen_inline_result"}
+
+	// the updates are polled unless WEBHOOK is set, then Telegram sends them
+	// to the web server
+	var poller telebot.Poller = &telebot.LongPoller{
+		Timeout:        10 * time.Second,
+		AllowedUpdates: allowedUpdates,
+	}
+
+	webhookSecret := ""
+	if webhook {
+		webhookSecret = StringOrDefault(os.Getenv("WEBHOOK_SECRET"), web.NewWebhookSecret())
+		poller = web.NewWebhookPoller(baseUrl, webhookSecret, allowedUpdates)
+	}
 
 	bot, err := telebot.NewBot(telebot.Settings{
 		Token:     botToken,
 		ParseMode: telebot.ModeHTML,
-		Poller: &telebot.LongPoller{
-			Timeout:        10 * time.Second,
-			AllowedUpdates: []string{"message", "callback_query"},
-		},
+		Poller:    poller,
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if !webhook {
+		// getUpdates fails while a webhook left by a webhook run is set
+		if err = bot.RemoveWebhook(); err != nil {
+			log.Println("failed to delete the webhook:", err)
+		}
+	}
+
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 	bgg := gobgg.NewBGGClient(gobgg.SetClient(client))
+	bggCache := models.NewBGGCache(bgg, models.DefaultBGGCacheTTL)
+	edits := models.NewEditQueue(bot, editInterval)
+	admins := models.NewAdminCache(bot, models.DefaultAdminCacheTTL)
 
 	telegram := telegram.Telegram{
-		Bot:            bot,
-		DB:             db,
-		BGG:            bgg,
-		LanguageBundle: bundle,
-		LanguagePack:   lp,
-		BaseUrl:        baseUrl,
-		BotName:        botName,
+		Bot:             bot,
+		DB:              db,
+		BGG:             bgg,
+		BGGCache:        bggCache,
+		Admins:          admins,
+		LanguageBundle:  bundle,
+		LanguagePack:    lp,
+		BaseUrl:         baseUrl,
+		BotName:         botName,
+		SeriesDaysAhead: seriesDaysAhead,
+		Edits:           edits,
 	}
 
+	InitReminders(telegram, StringOrDefault(os.Getenv("REMINDERS"), models.DefaultReminders))
+	InitSeries(telegram)
+	InitCollections(telegram, collectionRefresh)
+
 	log.Println("bot started")
 
 	bot.Handle("/start", telegram.Start)
@@ -154,8 +375,33 @@ func main() {
 	bot.Handle("/create", telegram.CreateGame)
 	bot.Handle("/add_game", telegram.AddGame)
 	bot.Handle("/language", telegram.SetLanguage)
+	bot.Handle("/lock", telegram.Lock)
+	bot.Handle("/unlock", telegram.Unlock)
+	bot.Handle("/close", telegram.Close)
+	bot.Handle("/cancel", telegram.Cancel)
+	bot.Handle("/join_mode", telegram.SetJoinMode)
+	bot.Handle("/log", telegram.Log)
+	bot.Handle("/events", telegram.Events)
+	bot.Handle("/remove_game", telegram.RemoveGame)
+	bot.Handle("/rename_game", telegram.RenameGame)
+	bot.Handle("/set_players", telegram.SetPlayers)
+	bot.Handle("/reminders", telegram.Reminders)
+	bot.Handle("/notifications", telegram.Notifications)
+	bot.Handle("/recurring", telegram.Recurring)
+	bot.Handle("/link_bgg", telegram.LinkBGG)
+	bot.Handle("/vote", telegram.Vote)
+	bot.Handle("/organizer", telegram.Organizer)
+	bot.Handle("/result", telegram.Result)
+	bot.Handle("/leaderboard", telegram.Leaderboard)
+
+	bot.Handle(telebot.OnQuery, telegram.InlineSearch)
+	bot.Handle(telebot.OnInlineResult, telegram.ChosenInlineResult)
 
 	bot.Handle(telebot.OnText, func(c telebot.Context) error {
+		if telegram.IsInlineGameMessage(c.Message()) {
+			return telegram.InlineGameMessage(c)
+		}
+
 		if c.Message().ReplyTo == nil {
 			return nil
 		}
@@ -175,6 +421,26 @@ func main() {
 			return telegram.CallbackAddPlayer(c)
 		case string(models.Cancel):
 			return telegram.CallbackRemovePlayer(c)
+		case string(models.PickEvent):
+			return telegram.CallbackPickEvent(c)
+		case string(models.LeaveGame):
+			return telegram.CallbackLeaveGame(c)
+		case string(models.EventsPage):
+			return telegram.CallbackEventsPage(c)
+		case string(models.PickGame):
+			return telegram.CallbackPickGame(c)
+		case string(models.AddBGGGame):
+			return telegram.CallbackAddBGGGame(c)
+		case string(models.PickBGG):
+			return telegram.CallbackPickBGG(c)
+		case string(models.BringGame):
+			return telegram.CallbackBringGame(c)
+		case string(models.CastVote):
+			return telegram.CallbackCastVote(c)
+		case string(models.CloseVote):
+			return telegram.CallbackCloseVote(c)
+		case string(models.Notify):
+			return telegram.CallbackNotify(c)
 		}
 
 		return c.Reply("invalid action")
@@ -182,7 +448,7 @@ func main() {
 
 	go func() {
 		log.Println("server started")
-		web.StartServer(port, db, bgg, bot, bundle, baseUrl, botName)
+		web.StartServer(port, db, bgg, bggCache, admins, bot, edits, bundle, baseUrl, botName, webhookSecret)
 		log.Println("server stopped")
 	}()
 	go func() {
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+// DefaultAdminCacheTTL is how long the answer of getChatMember is reused.
+const DefaultAdminCacheTTL = 10 * time.Minute
+
+type adminKey struct {
+	chatID int64
+	userID int64
+}
+
+type cachedAdmin struct {
+	admin   bool
+	expires time.Time
+}
+
+// AdminCache remembers who administers each chat, so that checking the rights
+// of a user does not ask Telegram at every click.
+type AdminCache struct {
+	Bot *telebot.Bot
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	members map[adminKey]cachedAdmin
+}
+
+func NewAdminCache(bot *telebot.Bot, ttl time.Duration) *AdminCache {
+	return &AdminCache{
+		Bot:     bot,
+		ttl:     ttl,
+		members: map[adminKey]cachedAdmin{},
+	}
+}
+
+// IsAdmin reports whether the user is the owner or an administrator of the
+// chat. Private chats have no administrators, and a failed request is answered
+// with false without being cached.
+func (c *AdminCache) IsAdmin(chatID, userID int64) bool {
+	if chatID > 0 {
+		return false
+	}
+
+	key := adminKey{chatID: chatID, userID: userID}
+	now := time.Now()
+
+	c.mu.Lock()
+	if m, ok := c.members[key]; ok && now.Before(m.expires) {
+		c.mu.Unlock()
+		return m.admin
+	}
+	c.mu.Unlock()
+
+	member, err := c.Bot.ChatMemberOf(&telebot.Chat{ID: chatID}, &telebot.User{ID: userID})
+	if err != nil {
+		log.Printf("Failed to get member %d of chat %d: %v", userID, chatID, err)
+		return false
+	}
+
+	admin := member.Role == telebot.Creator || member.Role == telebot.Administrator
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, m := range c.members {
+		if now.After(m.expires) {
+			delete(c.members, k)
+		}
+	}
+	c.members[key] = cachedAdmin{admin: admin, expires: now.Add(c.ttl)}
+
+	return admin
+}
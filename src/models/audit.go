@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type AuditAction string
+
+const (
+	AuditEventCreated      AuditAction = "event_created"
+	AuditEventUpdated      AuditAction = "event_updated"
+	AuditStatusChanged     AuditAction = "status_changed"
+	AuditJoinModeChanged   AuditAction = "join_mode_changed"
+	AuditGameAdded         AuditAction = "game_added"
+	AuditGameDeleted       AuditAction = "game_deleted"
+	AuditGameRenamed       AuditAction = "game_renamed"
+	AuditMaxPlayersChanged AuditAction = "max_players_changed"
+	AuditBGGLinked         AuditAction = "bgg_linked"
+	AuditBGGUnlinked       AuditAction = "bgg_unlinked"
+	AuditBringerChanged    AuditAction = "bringer_changed"
+	AuditVoteStarted       AuditAction = "vote_started"
+	AuditVoteClosed        AuditAction = "vote_closed"
+	AuditOrganizerAdded    AuditAction = "organizer_added"
+	AuditOrganizerRemoved  AuditAction = "organizer_removed"
+	AuditResultRecorded    AuditAction = "result_recorded"
+	AuditPlayerJoined      AuditAction = "player_joined"
+	AuditPlayerWaitlisted  AuditAction = "player_waitlisted"
+	AuditPlayerLeft        AuditAction = "player_left"
+	AuditPlayerPromoted    AuditAction = "player_promoted"
+)
+
+// MessageID is the localization key describing the action, event_created
+// becomes AuditEventCreated.
+func (a AuditAction) MessageID() string {
+	id := "Audit"
+	for _, part := range strings.Split(string(a), "_") {
+		if part == "bgg" {
+			id += "BGG"
+			continue
+		}
+
+		if part != "" {
+			id += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return id
+}
+
+type AuditSource string
+
+const (
+	SourceBot AuditSource = "bot"
+	SourceWeb AuditSource = "web"
+)
+
+// AuditEntry records who changed what in an event. Target is the game the
+// action is about, Before and After the values it changed.
+type AuditEntry struct {
+	ID        int64       `json:"id"`
+	EventID   string      `json:"event_id"`
+	UserID    int64       `json:"user_id"`
+	UserName  string      `json:"user_name"`
+	Action    AuditAction `json:"action"`
+	Target    *string     `json:"target"`
+	Before    *string     `json:"before"`
+	After     *string     `json:"after"`
+	Source    AuditSource `json:"source"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewAuditEntry builds an entry, leaving the empty values unset.
+func NewAuditEntry(eventID string, userID int64, userName string, source AuditSource, action AuditAction, target, before, after string) AuditEntry {
+	orNil := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+
+		return &s
+	}
+
+	return AuditEntry{
+		EventID:  eventID,
+		UserID:   userID,
+		UserName: userName,
+		Action:   action,
+		Target:   orNil(target),
+		Before:   orNil(before),
+		After:    orNil(after),
+		Source:   source,
+	}
+}
+
+// AuditLogSize is the number of entries shown by /log and the web view.
+const AuditLogSize = 50
+
+// Format renders the entry on a single line of plain text, in the local time
+// zone.
+func (a AuditEntry) Format(localizer *i18n.Localizer) string {
+	value := func(s *string) string {
+		if s == nil || *s == "" {
+			return "–"
+		}
+
+		return *s
+	}
+
+	before, after := value(a.Before), value(a.After)
+	switch a.Action {
+	case AuditStatusChanged:
+		before = localizer.MustLocalizeMessage(&i18n.Message{ID: StatusMessageID(EventStatus(before))})
+		after = localizer.MustLocalizeMessage(&i18n.Message{ID: StatusMessageID(EventStatus(after))})
+	case AuditJoinModeChanged:
+		before = localizer.MustLocalizeMessage(&i18n.Message{ID: JoinModeMessageID(JoinMode(before))})
+		after = localizer.MustLocalizeMessage(&i18n.Message{ID: JoinModeMessageID(JoinMode(after))})
+	case AuditVoteStarted:
+		after = localizer.MustLocalizeMessage(&i18n.Message{ID: VoteMethodMessageID(VoteMethod(after))})
+	}
+
+	target := a.Target
+	if target != nil && *target == PLAYER_COUNTER {
+		name := localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
+		target = &name
+	}
+
+	userName := a.UserName
+	if userName == "" {
+		userName = fmt.Sprintf("user_%d", a.UserID)
+	}
+
+	action := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: a.Action.MessageID(),
+		},
+		TemplateData: map[string]string{
+			"Target": value(target),
+			"Before": before,
+			"After":  after,
+		},
+	})
+
+	return fmt.Sprintf("%s · %s (%s) %s", a.CreatedAt.Local().Format(EventTimeLayout), userName, a.Source, action)
+}
@@ -0,0 +1,286 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fzerorubigd/gobgg"
+)
+
+// DefaultBGGCacheTTL is how long the answers of BoardGameGeek are reused.
+const DefaultBGGCacheTTL = 6 * time.Hour
+
+// BGGUrl is the page of a game on BoardGameGeek.
+func BGGUrl(id int64) string {
+	return fmt.Sprintf("https://boardgamegeek.com/boardgame/%d", id)
+}
+
+type cachedSearch struct {
+	results []gobgg.SearchResult
+	expires time.Time
+}
+
+type cachedThing struct {
+	thing   gobgg.ThingResult
+	expires time.Time
+}
+
+// BGGCache keeps the searches and the games fetched from BoardGameGeek, so
+// that the inline queries sent while typing do not hit its rate limit.
+type BGGCache struct {
+	BGG *gobgg.BGG
+
+	mu       sync.Mutex
+	ttl      time.Duration
+	searches map[string]cachedSearch
+	things   map[int64]cachedThing
+}
+
+func NewBGGCache(bgg *gobgg.BGG, ttl time.Duration) *BGGCache {
+	return &BGGCache{
+		BGG:      bgg,
+		ttl:      ttl,
+		searches: map[string]cachedSearch{},
+		things:   map[int64]cachedThing{},
+	}
+}
+
+// prune drops the expired entries, it must be called with the lock held.
+func (c *BGGCache) prune(now time.Time) {
+	for query, s := range c.searches {
+		if now.After(s.expires) {
+			delete(c.searches, query)
+		}
+	}
+
+	for id, t := range c.things {
+		if now.After(t.expires) {
+			delete(c.things, id)
+		}
+	}
+}
+
+// Search returns the board games matching the query.
+func (c *BGGCache) Search(ctx context.Context, query string) ([]gobgg.SearchResult, error) {
+	key := strings.ToLower(strings.TrimSpace(query))
+	now := time.Now()
+
+	c.mu.Lock()
+	if s, ok := c.searches[key]; ok && now.Before(s.expires) {
+		c.mu.Unlock()
+		return s.results, nil
+	}
+	c.mu.Unlock()
+
+	results, err := c.BGG.Search(ctx, query, gobgg.SearchTypes(gobgg.BoardGameType, gobgg.BoardGameExpansionType))
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prune(now)
+	c.searches[key] = cachedSearch{results: results, expires: now.Add(c.ttl)}
+
+	return results, nil
+}
+
+// GetThings returns the games with the given ids, in the same order, only
+// the ones not cached are fetched. Unknown ids are left out.
+func (c *BGGCache) GetThings(ctx context.Context, ids ...int64) ([]gobgg.ThingResult, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	missing := []int64{}
+	for _, id := range ids {
+		if t, ok := c.things[id]; !ok || now.After(t.expires) {
+			missing = append(missing, id)
+		}
+	}
+	c.mu.Unlock()
+
+	var fetched []gobgg.ThingResult
+	if len(missing) > 0 {
+		var err error
+		if fetched, err = c.BGG.GetThings(ctx, gobgg.GetThingIDs(missing...)); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prune(now)
+	for _, t := range fetched {
+		c.things[t.ID] = cachedThing{thing: t, expires: now.Add(c.ttl)}
+	}
+
+	things := []gobgg.ThingResult{}
+	for _, id := range ids {
+		if t, ok := c.things[id]; ok {
+			things = append(things, t.thing)
+		}
+	}
+
+	return things, nil
+}
+
+// GameInfo returns the number of players, name, page and image of a game,
+// the name typed by the user is kept when BoardGameGeek has none.
+func (c *BGGCache) GameInfo(ctx context.Context, id int64, gameName string) (*int, *string, *string, *string, error) {
+	var bgName, bgImageUrl *string
+	var maxPlayers *int
+	url := BGGUrl(id)
+
+	things, err := c.GetThings(ctx, id)
+	if err != nil {
+		log.Printf("Failed to get game %d: %v", id, err)
+		return nil, nil, nil, nil, err
+	}
+
+	if len(things) > 0 {
+		maxPlayers = &things[0].MaxPlayers
+		if things[0].Name != "" {
+			bgName = &things[0].Name
+		} else {
+			bgName = &gameName
+		}
+		if things[0].Image != "" {
+			bgImageUrl = &things[0].Image
+		}
+	}
+
+	return maxPlayers, bgName, &url, bgImageUrl, nil
+}
+
+// BGGPickerSize is the number of games offered when a name matches several
+// games of BoardGameGeek.
+const BGGPickerSize = 5
+
+// BGGMatch is a game of BoardGameGeek matching the name typed by a user.
+type BGGMatch struct {
+	ID        int64
+	Name      string
+	Year      int
+	Expansion bool
+	Exact     bool
+	Thumbnail string
+	Owners    []string
+}
+
+// Label is the name of the game followed by its year.
+func (m BGGMatch) Label() string {
+	if m.Year > 0 {
+		return fmt.Sprintf("%s (%d)", m.Name, m.Year)
+	}
+
+	return m.Name
+}
+
+// RankBGGResults merges the results of a search, where an expansion can be
+// listed both as a game and as an expansion, and sorts them: the games named
+// exactly like the query first, then the base games, then the names starting
+// with the query, the oldest entries first.
+func RankBGGResults(query string, results []gobgg.SearchResult) []BGGMatch {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	byID := map[int64]*BGGMatch{}
+	matches := []*BGGMatch{}
+	for _, r := range results {
+		if r.Type != gobgg.BoardGameType && r.Type != gobgg.BoardGameExpansionType {
+			continue
+		}
+
+		m, ok := byID[r.ID]
+		if !ok {
+			m = &BGGMatch{ID: r.ID, Name: r.Name, Year: r.YearPublished}
+			byID[r.ID] = m
+			matches = append(matches, m)
+		}
+
+		if r.Type == gobgg.BoardGameExpansionType {
+			m.Expansion = true
+		}
+
+		for _, name := range append([]string{r.Name}, r.AlternateNames...) {
+			if strings.ToLower(strings.TrimSpace(name)) == query {
+				m.Exact = true
+			}
+		}
+	}
+
+	prefix := func(m *BGGMatch) bool {
+		return strings.HasPrefix(strings.ToLower(m.Name), query)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Exact != b.Exact:
+			return a.Exact
+		case a.Expansion != b.Expansion:
+			return !a.Expansion
+		case prefix(a) != prefix(b):
+			return prefix(a)
+		}
+
+		return a.ID < b.ID
+	})
+
+	ranked := make([]BGGMatch, len(matches))
+	for i, m := range matches {
+		ranked[i] = *m
+	}
+
+	return ranked
+}
+
+// PickBGGMatch returns the game to use without asking the user, when the
+// search found a single game or a single base game with the exact name.
+func PickBGGMatch(matches []BGGMatch) (BGGMatch, bool) {
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+
+	exact := []BGGMatch{}
+	for _, m := range matches {
+		if m.Exact && !m.Expansion {
+			exact = append(exact, m)
+		}
+	}
+
+	if len(exact) == 1 {
+		return exact[0], true
+	}
+
+	return BGGMatch{}, false
+}
+
+// AddThumbnails fills the thumbnails of the matches, one request fetches
+// all of them.
+func (c *BGGCache) AddThumbnails(ctx context.Context, matches []BGGMatch) {
+	ids := make([]int64, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+
+	things, err := c.GetThings(ctx, ids...)
+	if err != nil {
+		log.Printf("Failed to get games %v: %v", ids, err)
+		return
+	}
+
+	for i := range matches {
+		for _, t := range things {
+			if t.ID == matches[i].ID {
+				matches[i].Thumbnail = t.Thumbnail
+			}
+		}
+	}
+}
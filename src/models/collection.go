@@ -0,0 +1,117 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCollectionRefresh is how often the collections of the linked
+// BoardGameGeek accounts are fetched again, when BGG_COLLECTION_REFRESH is
+// not set.
+const DefaultCollectionRefresh = 24 * time.Hour
+
+// BGGAccount is the BoardGameGeek account linked by a user with /link_bgg,
+// SyncedAt is when their collection was last fetched.
+type BGGAccount struct {
+	UserID      int64      `json:"user_id"`
+	UserName    string     `json:"user_name"`
+	BGGUsername string     `json:"bgg_username"`
+	SyncedAt    *time.Time `json:"synced_at"`
+}
+
+// CollectionGame is a game owned by a user in their BoardGameGeek
+// collection.
+type CollectionGame struct {
+	UserID   int64  `json:"user_id"`
+	UserName string `json:"user_name"`
+	BGGID    int64  `json:"bgg_id"`
+	Name     string `json:"name"`
+	Year     int    `json:"year"`
+}
+
+// MatchCollection returns the games of the collections whose name contains
+// the query, with their owners, ranked like RankBGGResults.
+func MatchCollection(query string, games []CollectionGame) []BGGMatch {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []BGGMatch{}
+	}
+
+	byID := map[int64]*BGGMatch{}
+	matches := []*BGGMatch{}
+	for _, g := range games {
+		name := strings.ToLower(g.Name)
+		if !strings.Contains(name, query) {
+			continue
+		}
+
+		m, ok := byID[g.BGGID]
+		if !ok {
+			m = &BGGMatch{ID: g.BGGID, Name: g.Name, Year: g.Year, Exact: name == query}
+			byID[g.BGGID] = m
+			matches = append(matches, m)
+		}
+
+		m.Owners = append(m.Owners, g.UserName)
+	}
+
+	prefix := func(m *BGGMatch) bool {
+		return strings.HasPrefix(strings.ToLower(m.Name), query)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Exact != b.Exact:
+			return a.Exact
+		case prefix(a) != prefix(b):
+			return prefix(a)
+		}
+
+		return a.Name < b.Name
+	})
+
+	ranked := make([]BGGMatch, len(matches))
+	for i, m := range matches {
+		ranked[i] = *m
+	}
+
+	return ranked
+}
+
+// PickCollectionMatch returns the game of the collections to use without
+// asking the user, when a single one has the exact name.
+func PickCollectionMatch(matches []BGGMatch) (BGGMatch, bool) {
+	exact := []BGGMatch{}
+	for _, m := range matches {
+		if m.Exact {
+			exact = append(exact, m)
+		}
+	}
+
+	if len(exact) == 1 {
+		return exact[0], true
+	}
+
+	return BGGMatch{}, false
+}
+
+// MergeMatches lists the games owned in the chat first, then the other games
+// found on BoardGameGeek.
+func MergeMatches(owned, found []BGGMatch) []BGGMatch {
+	merged := append([]BGGMatch{}, owned...)
+
+	seen := map[int64]bool{}
+	for _, m := range owned {
+		seen[m.ID] = true
+	}
+
+	for _, m := range found {
+		if !seen[m.ID] {
+			merged = append(merged, m)
+		}
+	}
+
+	return merged
+}
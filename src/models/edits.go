@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+// DefaultEditInterval is the pause between two edits in the same chat, when
+// EDIT_INTERVAL is not set.
+const DefaultEditInterval = time.Second
+
+// Render returns the content of a message, it is called right before the
+// message is edited so that the latest state is shown.
+type Render func() (string, *telebot.ReplyMarkup, error)
+
+// chatEdits are the messages of a chat waiting to be edited, each with the
+// last render queued for it.
+type chatEdits struct {
+	order   []int
+	pending map[int]Render
+}
+
+// EditQueue edits the messages one chat at a time, waiting the interval
+// between two edits of a chat and what Telegram asks after a 429. The edits
+// queued for a message while it waits are merged into a single one.
+type EditQueue struct {
+	Bot *telebot.Bot
+
+	mu       sync.Mutex
+	interval time.Duration
+	chats    map[int64]*chatEdits
+	// edit sends the edit to Telegram, it is replaced in the tests.
+	edit func(msg *telebot.Message, body string, markup *telebot.ReplyMarkup) error
+}
+
+func NewEditQueue(bot *telebot.Bot, interval time.Duration) *EditQueue {
+	return &EditQueue{
+		Bot:      bot,
+		interval: interval,
+		chats:    map[int64]*chatEdits{},
+		edit: func(msg *telebot.Message, body string, markup *telebot.ReplyMarkup) error {
+			_, err := bot.Edit(msg, body, markup, telebot.NoPreview)
+			return err
+		},
+	}
+}
+
+// Enqueue schedules the edit of a message, replacing the one already waiting
+// for it. The first edit of an idle chat is sent right away.
+func (q *EditQueue) Enqueue(chatID int64, messageID int, render Render) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	chat, ok := q.chats[chatID]
+	if !ok {
+		chat = &chatEdits{pending: map[int]Render{}}
+		q.chats[chatID] = chat
+		go q.run(chatID, chat)
+	}
+
+	if _, ok := chat.pending[messageID]; !ok {
+		chat.order = append(chat.order, messageID)
+	}
+	chat.pending[messageID] = render
+}
+
+// next pops the first message waiting in the chat, the chat is forgotten
+// when nothing is left.
+func (q *EditQueue) next(chatID int64, chat *chatEdits) (int, Render, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(chat.order) == 0 {
+		delete(q.chats, chatID)
+		return 0, nil, false
+	}
+
+	messageID := chat.order[0]
+	chat.order = chat.order[1:]
+	render := chat.pending[messageID]
+	delete(chat.pending, messageID)
+
+	return messageID, render, true
+}
+
+// retry queues the message again in front of the others, unless a newer edit
+// is already waiting for it.
+func (q *EditQueue) retry(chat *chatEdits, messageID int, render Render) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := chat.pending[messageID]; ok {
+		return
+	}
+
+	chat.order = append([]int{messageID}, chat.order...)
+	chat.pending[messageID] = render
+}
+
+func (q *EditQueue) run(chatID int64, chat *chatEdits) {
+	for {
+		messageID, render, ok := q.next(chatID, chat)
+		if !ok {
+			return
+		}
+
+		body, markup, err := render()
+		if err != nil {
+			log.Printf("failed to render message %d of chat %d: %v", messageID, chatID, err)
+			continue
+		}
+
+		err = q.edit(&telebot.Message{
+			ID:   messageID,
+			Chat: &telebot.Chat{ID: chatID},
+		}, body, markup)
+
+		var flood telebot.FloodError
+		switch {
+		case errors.As(err, &flood):
+			log.Printf("too many edits in chat %d, retrying in %ds", chatID, flood.RetryAfter)
+			q.retry(chat, messageID, render)
+			time.Sleep(time.Duration(flood.RetryAfter) * time.Second)
+			continue
+		case err != nil && !strings.Contains(err.Error(), MessageUnchangedErrorMessage):
+			log.Println("failed to edit message", err)
+		}
+
+		time.Sleep(q.interval)
+	}
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+// editCall is an edit sent by the queue, it waits for the result the test
+// sends back.
+type editCall struct {
+	messageID int
+	body      string
+	result    chan error
+}
+
+func newTestEditQueue() (*EditQueue, chan editCall) {
+	calls := make(chan editCall)
+
+	q := NewEditQueue(nil, 0)
+	q.edit = func(msg *telebot.Message, body string, markup *telebot.ReplyMarkup) error {
+		call := editCall{messageID: msg.ID, body: body, result: make(chan error)}
+		calls <- call
+		return <-call.result
+	}
+
+	return q, calls
+}
+
+func renderBody(body string) Render {
+	return func() (string, *telebot.ReplyMarkup, error) {
+		return body, nil, nil
+	}
+}
+
+// expectEdit waits for the next edit, checks it and answers it with err.
+func expectEdit(t *testing.T, calls chan editCall, messageID int, body string, err error) {
+	t.Helper()
+
+	select {
+	case call := <-calls:
+		if call.messageID != messageID || call.body != body {
+			t.Errorf("expected %q on message %d, got %q on message %d", body, messageID, call.body, call.messageID)
+		}
+		call.result <- err
+	case <-time.After(time.Second):
+		t.Fatalf("expected %q on message %d, nothing was edited", body, messageID)
+	}
+}
+
+// expectIdle waits for the chat to be forgotten, with nothing else edited.
+func expectIdle(t *testing.T, q *EditQueue, calls chan editCall, chatID int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		q.mu.Lock()
+		_, busy := q.chats[chatID]
+		q.mu.Unlock()
+
+		if !busy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("chat %d is still queued", chatID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case call := <-calls:
+		t.Errorf("unexpected %q on message %d", call.body, call.messageID)
+		call.result <- nil
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestEditQueueMergesBurst(t *testing.T) {
+	q, calls := newTestEditQueue()
+
+	// the first edit of an idle chat is sent right away, the burst queued
+	// while it is sent is merged by message
+	q.Enqueue(1, 10, renderBody("a1"))
+	select {
+	case call := <-calls:
+		if call.messageID != 10 || call.body != "a1" {
+			t.Fatalf("expected a1 on message 10, got %q on message %d", call.body, call.messageID)
+		}
+
+		q.Enqueue(1, 10, renderBody("a2"))
+		q.Enqueue(1, 11, renderBody("b1"))
+		q.Enqueue(1, 10, renderBody("a3"))
+		call.result <- nil
+	case <-time.After(time.Second):
+		t.Fatal("nothing was edited")
+	}
+
+	expectEdit(t, calls, 10, "a3", nil)
+	expectEdit(t, calls, 11, "b1", nil)
+	expectIdle(t, q, calls, 1)
+
+	// once drained the chat starts again with the next edit
+	q.Enqueue(1, 10, renderBody("a4"))
+	expectEdit(t, calls, 10, "a4", nil)
+	expectIdle(t, q, calls, 1)
+}
+
+func TestEditQueueRetriesFlood(t *testing.T) {
+	type edit struct {
+		messageID int
+		body      string
+	}
+
+	tests := []struct {
+		name   string
+		queued []edit
+		edits  []edit
+	}{
+		{
+			name:   "the message is edited again before the others",
+			queued: []edit{{11, "b1"}},
+			edits:  []edit{{10, "a1"}, {11, "b1"}},
+		},
+		{
+			name:   "a newer render replaces the one that failed",
+			queued: []edit{{10, "a2"}, {11, "b1"}},
+			edits:  []edit{{10, "a2"}, {11, "b1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, calls := newTestEditQueue()
+
+			// the edits are queued while Telegram answers the first one
+			// with a 429
+			q.Enqueue(1, 10, renderBody("a1"))
+			select {
+			case call := <-calls:
+				for _, e := range tt.queued {
+					q.Enqueue(1, e.messageID, renderBody(e.body))
+				}
+				call.result <- telebot.FloodError{RetryAfter: 0}
+			case <-time.After(time.Second):
+				t.Fatal("nothing was edited")
+			}
+
+			for _, e := range tt.edits {
+				expectEdit(t, calls, e.messageID, e.body, nil)
+			}
+			expectIdle(t, q, calls, 1)
+		})
+	}
+}
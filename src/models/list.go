@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"gopkg.in/telebot.v3"
+)
+
+// EventsPageSize is the number of events shown on each page of /events.
+const EventsPageSize = 5
+
+// WebLink opens the web app page of the event, through the bot mini app in
+// groups as web app buttons only work in private chats.
+func (e Event) WebLink(baseUrl, botName string) string {
+	if e.ChatID > 0 {
+		return fmt.Sprintf("%s/events/%s/", baseUrl, e.ID)
+	}
+
+	return fmt.Sprintf("https://t.me/%s/home?startapp=%s", botName, e.ID)
+}
+
+// MessageLink points to a message of a public chat or of a supergroup, it is
+// empty for the other chats as Telegram has no link to their messages.
+func MessageLink(chatID int64, chatUsername string, messageID int64) string {
+	if chatUsername != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", chatUsername, messageID)
+	}
+
+	if id := strconv.FormatInt(chatID, 10); strings.HasPrefix(id, "-100") {
+		return fmt.Sprintf("https://t.me/c/%s/%d", strings.TrimPrefix(id, "-100"), messageID)
+	}
+
+	return ""
+}
+
+// FormatEventList renders a page of the events of a chat, page starts at 0.
+func FormatEventList(localizer *i18n.Localizer, summaries []EventSummary, page, pages int, baseUrl, botName, chatUsername string) (string, *telebot.ReplyMarkup) {
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	if len(summaries) == 0 {
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "NoEvents"}), markup
+	}
+
+	msg := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "EventListTitle",
+		},
+		TemplateData: map[string]string{
+			"Page":  strconv.Itoa(page + 1),
+			"Pages": strconv.Itoa(pages),
+		},
+	}) + "\n"
+
+	for _, s := range summaries {
+		title := html.EscapeString(s.Name)
+		if s.IsLocked() && !strings.Contains(title, "🔒") {
+			title += " 🔒"
+		}
+
+		msg += "\n📆 <b>" + title + "</b> <code>#" + s.Code() + "</code>\n"
+		if banner := s.Banner(localizer); banner != "" {
+			msg += "<i>" + banner + "</i>\n"
+		}
+		if s.StartsAt != nil {
+			msg += "🕗 " + s.FormatWhen() + "\n"
+		}
+		if s.Location != nil && *s.Location != "" {
+			msg += "📍 " + html.EscapeString(*s.Location) + "\n"
+		}
+
+		msg += localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "EventListSummary",
+			},
+			TemplateData: map[string]string{
+				"Games":        strconv.Itoa(s.Games),
+				"Participants": strconv.Itoa(s.Participants),
+			},
+		}) + "\n"
+
+		links := []string{}
+		if s.MessageID != nil {
+			if link := MessageLink(s.ChatID, chatUsername, *s.MessageID); link != "" {
+				links = append(links, fmt.Sprintf("<a href='%s'>%s</a>", link, localizer.MustLocalizeMessage(&i18n.Message{ID: "EventListMessage"})))
+			}
+		}
+		links = append(links, fmt.Sprintf("<a href='%s'>%s</a>", s.WebLink(baseUrl, botName), localizer.MustLocalizeMessage(&i18n.Message{ID: "EventListWeb"})))
+
+		msg += strings.Join(links, " · ") + "\n"
+	}
+
+	row := []telebot.InlineButton{}
+	if page > 0 {
+		row = append(row, telebot.InlineButton{
+			Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "NewerEvents"}),
+			Unique: string(EventsPage),
+			Data:   strconv.Itoa(page - 1),
+		})
+	}
+	if page < pages-1 {
+		row = append(row, telebot.InlineButton{
+			Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "OlderEvents"}),
+			Unique: string(EventsPage),
+			Data:   strconv.Itoa(page + 1),
+		})
+	}
+	if len(row) > 0 {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, row)
+	}
+
+	return msg, markup
+}
@@ -2,11 +2,14 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"log"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fzerorubigd/gobgg"
@@ -17,6 +20,10 @@ import (
 
 const PLAYER_COUNTER = "_PLAYER_COUNTER_"
 
+// EventCodeLength is the number of leading characters of the event id shown
+// as its short code, they never include the first dash of the uuid.
+const EventCodeLength = 6
+
 type Event struct {
 	ID         string
 	ChatID     int64
@@ -24,8 +31,151 @@ type Event struct {
 	UserName   string
 	MessageID  *int64
 	Name       string
+	StartsAt   *time.Time
+	EndsAt     *time.Time
+	Location   *string
+	Status     EventStatus
+	JoinMode   JoinMode
 	BoardGames []BoardGame
-	Locked     bool
+	Organizers []Organizer
+}
+
+// JoinMode tells whether a player can sit at one or several tables of an
+// event.
+type JoinMode string
+
+const (
+	JoinSingle   JoinMode = "single"
+	JoinMultiple JoinMode = "multiple"
+)
+
+func (m JoinMode) IsValid() bool {
+	return m == JoinSingle || m == JoinMultiple
+}
+
+// JoinModeMessageID is the localization key describing the join mode.
+func JoinModeMessageID(m JoinMode) string {
+	if m == JoinMultiple {
+		return "JoinModeMultiple"
+	}
+
+	return "JoinModeSingle"
+}
+
+type EventStatus string
+
+const (
+	StatusOpen      EventStatus = "open"
+	StatusLocked    EventStatus = "locked"
+	StatusClosed    EventStatus = "closed"
+	StatusCancelled EventStatus = "cancelled"
+)
+
+var statusTransitions = map[EventStatus][]EventStatus{
+	StatusOpen:      {StatusLocked, StatusClosed, StatusCancelled},
+	StatusLocked:    {StatusOpen, StatusClosed, StatusCancelled},
+	StatusClosed:    {},
+	StatusCancelled: {},
+}
+
+func (s EventStatus) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+func (s EventStatus) CanTransitionTo(to EventStatus) bool {
+	for _, next := range statusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StatusMessageID is the localization key describing the status.
+func StatusMessageID(s EventStatus) string {
+	switch s {
+	case StatusLocked:
+		return "StatusLocked"
+	case StatusClosed:
+		return "StatusClosed"
+	case StatusCancelled:
+		return "StatusCancelled"
+	}
+
+	return "StatusOpen"
+}
+
+// Code is the short identifier used to pick an event when a chat has
+// several of them.
+func (e Event) Code() string {
+	if len(e.ID) < EventCodeLength {
+		return e.ID
+	}
+
+	return e.ID[:EventCodeLength]
+}
+
+// IsLocked reports whether only the creator can change the event.
+func (e Event) IsLocked() bool {
+	return e.Status == StatusLocked
+}
+
+// IsActive reports whether players can still join and games can be changed.
+func (e Event) IsActive() bool {
+	return e.Status == StatusOpen || e.Status == StatusLocked
+}
+
+// IsOrganizer reports whether the user created the event or was named one of
+// its co-organizers.
+func (e Event) IsOrganizer(userID int64) bool {
+	if e.UserID == userID {
+		return true
+	}
+
+	for _, o := range e.Organizers {
+		if o.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanEdit reports whether the user can add, update or delete games, only
+// the organizers can change a locked event.
+func (e Event) CanEdit(userID int64) bool {
+	return e.Status == StatusOpen || (e.Status == StatusLocked && e.IsOrganizer(userID))
+}
+
+// EventSummary is an event without its games, with the number of games and
+// of seated players.
+type EventSummary struct {
+	Event
+	Games        int `json:"games"`
+	Participants int `json:"participants"`
+}
+
+type UpdateEventStatusRequest struct {
+	Status   EventStatus `json:"status" binding:"required"`
+	UserID   int64       `json:"user_id" binding:"required"`
+	UserName string      `json:"user_name"`
+}
+
+type UpdateEventRequest struct {
+	StartsAt string   `json:"starts_at" form:"starts_at"`
+	EndsAt   string   `json:"ends_at" form:"ends_at"`
+	Location string   `json:"location" form:"location"`
+	JoinMode JoinMode `json:"join_mode" form:"join_mode"`
+	UserID   int64    `json:"user_id" form:"user_id"`
+	UserName string   `json:"user_name" form:"user_name"`
+}
+
+type LeaveGameRequest struct {
+	GameID   int64  `json:"game_id" binding:"required"`
+	UserID   int64  `json:"user_id" binding:"required"`
+	UserName string `json:"user_name"`
 }
 
 type AddPlayerRequest struct {
@@ -34,17 +184,38 @@ type AddPlayerRequest struct {
 	UserName string `json:"user_name" binding:"required"`
 }
 
+type BringGameRequest struct {
+	GameID   int64  `json:"game_id" binding:"required"`
+	UserID   int64  `json:"user_id" binding:"required"`
+	UserName string `json:"user_name" binding:"required"`
+}
+
+type VoteRequest struct {
+	GameID   int64  `json:"game_id"`
+	UserID   int64  `json:"user_id" binding:"required"`
+	UserName string `json:"user_name" binding:"required"`
+}
+
+type CloseVoteRequest struct {
+	UserID   int64  `json:"user_id" binding:"required"`
+	UserName string `json:"user_name"`
+}
+
 type BoardGame struct {
-	ID           int64         `json:"id"`
-	Name         string        `json:"name"`
-	MaxPlayers   int64         `json:"max_players"`
-	Participants []Participant `json:"participants"`
-	BggID        *int64        `json:"bgg_id"`
-	BggName      *string       `json:"bgg_name"`
-	BggUrl       *string       `json:"bgg_url"`
-	BggImageUrl  *string       `json:"bgg_image_url"`
-	InitiatorName  *string       `json:"initiator_name"`
-	
+	ID            int64         `json:"id"`
+	Name          string        `json:"name"`
+	MaxPlayers    int64         `json:"max_players"`
+	Participants  []Participant `json:"participants"`
+	BggID         *int64        `json:"bgg_id"`
+	BggName       *string       `json:"bgg_name"`
+	BggUrl        *string       `json:"bgg_url"`
+	BggImageUrl   *string       `json:"bgg_image_url"`
+	InitiatorName *string       `json:"initiator_name"`
+	BringerID     *int64        `json:"bringer_id"`
+	BringerName   *string       `json:"bringer_name"`
+	Waitlist      []Participant `json:"waitlist"`
+	MessageID     *int64        `json:"message_id"`
+	Owners        []string      `json:"owners"`
 }
 
 type AddGameRequest struct {
@@ -52,41 +223,229 @@ type AddGameRequest struct {
 	MaxPlayers *int    `json:"max_players" form:"max_players"`
 	BggUrl     *string `json:"bgg_url" form:"bgg_url"`
 	UserID     int64   `json:"user_id" form:"user_id"`
+	UserName   *string `json:"user_name" form:"user_name"`
+	NoBGG      bool    `json:"no_bgg" form:"no_bgg"`
 }
 
 type UpdateGameRequest struct {
 	MaxPlayers *int    `json:"max_players" form:"max_players"`
 	BggUrl     *string `json:"bgg_url" form:"bgg_url"`
 	UserID     int64   `json:"user_id" form:"user_id"`
+	UserName   string  `json:"user_name" form:"user_name"`
 	Unlink     string  `json:"unlink" form:"unlink"`
 }
 
-type Participant struct {
-	ID       int64  `json:"id"`
+// Organizer is a user named by the creator of an event to manage it with
+// them.
+type Organizer struct {
 	UserID   int64  `json:"user_id"`
 	UserName string `json:"user_name"`
 }
 
+type Participant struct {
+	ID         int64  `json:"id"`
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	Waitlisted bool   `json:"waitlisted"`
+}
+
+// Mention links the name of the participant to their Telegram profile.
+func (p Participant) Mention() string {
+	return fmt.Sprintf("<a href='tg://user?id=%d'>%s</a>", p.UserID, html.EscapeString(p.UserName))
+}
+
+// IsFull reports whether new players go to the waitlist.
+func (bg BoardGame) IsFull() bool {
+	return bg.MaxPlayers != -1 && len(bg.Participants) >= int(bg.MaxPlayers)
+}
+
+// IsGame reports whether this is an actual game rather than the counter of
+// the players of the event.
+func (bg BoardGame) IsGame() bool {
+	return bg.Name != PLAYER_COUNTER
+}
+
+// IsUnclaimed reports whether nobody said they bring the game.
+func (bg BoardGame) IsUnclaimed() bool {
+	return bg.IsGame() && (bg.BringerName == nil || *bg.BringerName == "")
+}
+
+// IsBroughtBy reports whether the user brings the game.
+func (bg BoardGame) IsBroughtBy(userID int64) bool {
+	return bg.BringerID != nil && *bg.BringerID == userID
+}
+
+// FormatBringer tells who brings the game, or flags that nobody does yet.
+func (bg BoardGame) FormatBringer(localizer *i18n.Localizer) string {
+	if bg.IsUnclaimed() {
+		return "⚠️ " + localizer.MustLocalizeMessage(&i18n.Message{ID: "NobodyBrings"})
+	}
+
+	return "🎒 " + localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "BroughtBy",
+		},
+		TemplateData: map[string]string{
+			"Name": *bg.BringerName,
+		},
+	})
+}
+
+// IsSeated reports whether the user has a seat at the table.
+func (bg BoardGame) IsSeated(userID int64) bool {
+	for _, p := range bg.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsWaiting reports whether the user is on the waitlist of the game.
+func (bg BoardGame) IsWaiting(userID int64) bool {
+	for _, p := range bg.Waitlist {
+		if p.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DisplayName is the name of the game, with the player counter localized.
+func (bg BoardGame) DisplayName(localizer *i18n.Localizer) string {
+	if bg.Name == PLAYER_COUNTER {
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
+	}
+
+	return bg.Name
+}
+
+// BoardGame returns the game of the event with the given id, nil when missing.
+func (e Event) BoardGame(id int64) *BoardGame {
+	for i := range e.BoardGames {
+		if e.BoardGames[i].ID == id {
+			return &e.BoardGames[i]
+		}
+	}
+
+	return nil
+}
+
+// BoardGameByMessageID returns the game announced by the given message.
+func (e Event) BoardGameByMessageID(messageID int64) *BoardGame {
+	for i := range e.BoardGames {
+		if bg := e.BoardGames[i]; bg.MessageID != nil && *bg.MessageID == messageID {
+			return &e.BoardGames[i]
+		}
+	}
+
+	return nil
+}
+
+// JoinedBoardGames returns the games the user is seated at or waiting for.
+func (e Event) JoinedBoardGames(userID int64) []BoardGame {
+	joined := []BoardGame{}
+	for _, bg := range e.BoardGames {
+		if bg.IsSeated(userID) || bg.IsWaiting(userID) {
+			joined = append(joined, bg)
+		}
+	}
+
+	return joined
+}
+
+// Promotion is a player moved from the waitlist to the table of a game.
+type Promotion struct {
+	BoardGame   BoardGame
+	Participant Participant
+}
+
+// Promotions compares two snapshots of the same event and returns the players
+// that were waiting before and are seated after.
+func Promotions(before, after *Event) []Promotion {
+	waiting := map[int64]map[int64]bool{}
+	for _, bg := range before.BoardGames {
+		waiting[bg.ID] = map[int64]bool{}
+		for _, p := range bg.Waitlist {
+			waiting[bg.ID][p.UserID] = true
+		}
+	}
+
+	promotions := []Promotion{}
+	for _, bg := range after.BoardGames {
+		for _, p := range bg.Participants {
+			if waiting[bg.ID][p.UserID] {
+				promotions = append(promotions, Promotion{BoardGame: bg, Participant: p})
+			}
+		}
+	}
+
+	return promotions
+}
+
+// FormatPromotion tells a player they got a seat, mentioning them.
+func FormatPromotion(localizer *i18n.Localizer, p Promotion) string {
+	name := p.BoardGame.DisplayName(localizer)
+
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "PlayerPromoted",
+		},
+		TemplateData: map[string]string{
+			"User": p.Participant.Mention(),
+			"Name": name,
+		},
+	})
+}
+
+// SendPromotions announces in the chat the players seated from a waitlist,
+// each promotion is recorded with audit.
+func SendPromotions(bot *telebot.Bot, localizer *i18n.Localizer, before, after *Event, source AuditSource, audit func(AuditEntry)) {
+	for _, p := range Promotions(before, after) {
+		log.Printf("User %s (%d) promoted from the waitlist of game %d", p.Participant.UserName, p.Participant.UserID, p.BoardGame.ID)
+
+		audit(NewAuditEntry(after.ID, p.Participant.UserID, p.Participant.UserName, source, AuditPlayerPromoted, p.BoardGame.Name, "", ""))
+
+		if _, err := bot.Send(&telebot.Chat{ID: after.ChatID}, FormatPromotion(localizer, p)); err != nil {
+			log.Println("failed to notify promotion:", err)
+		}
+	}
+}
+
 // create enum with value add_player
 type EventAction string
 
 const (
-	AddPlayer EventAction = "$add_player"
-	Cancel    EventAction = "$cancel"
+	AddPlayer  EventAction = "$add_player"
+	Cancel     EventAction = "$cancel"
+	PickEvent  EventAction = "$pick_event"
+	LeaveGame  EventAction = "$leave_game"
+	EventsPage EventAction = "$events_page"
+	PickGame   EventAction = "$pick_game"
+	AddBGGGame EventAction = "$add_bgg"
+	PickBGG    EventAction = "$pick_bgg"
+	BringGame  EventAction = "$bring"
+	CastVote   EventAction = "$vote"
+	CloseVote  EventAction = "$close_vote"
+	Notify     EventAction = "$notify"
 )
 
-func (e Event) FormatBG(localizer *i18n.Localizer, baseUrl string, botName string, bg BoardGame) (string, telebot.InlineButton, error) {
+func (e Event) FormatBG(localizer *i18n.Localizer, baseUrl string, botName string, bg BoardGame) (string, []telebot.InlineButton, error) {
 	msg := ""
 
 	complete := ""
-	isComplete := len(bg.Participants) == int(bg.MaxPlayers)
-	if isComplete {
+	if bg.IsFull() {
 		complete = "🚫"
 	}
 
 	link := ""
 	if bg.BggUrl != nil && bg.BggName != nil && *bg.BggUrl != "" && *bg.BggName != "" {
-		link = fmt.Sprintf(" %s -- <a href='%s'>%s</a>\n", *bg.InitiatorName, *bg.BggUrl, *bg.BggName)
+		link = fmt.Sprintf(" <a href='%s'>%s</a>\n", *bg.BggUrl, *bg.BggName)
+		if bg.InitiatorName != nil && *bg.InitiatorName != "" {
+			link = fmt.Sprintf(" %s -- <a href='%s'>%s</a>\n", *bg.InitiatorName, *bg.BggUrl, *bg.BggName)
+		}
 	}
 
 	name := bg.Name
@@ -104,6 +463,25 @@ func (e Event) FormatBG(localizer *i18n.Localizer, baseUrl string, botName strin
 	for _, p := range bg.Participants {
 		msg += " - " + p.UserName + "\n"
 	}
+	if len(bg.Waitlist) > 0 {
+		msg += "⏳ " + localizer.MustLocalizeMessage(&i18n.Message{ID: "Waitlist"}) + "\n"
+		for i, p := range bg.Waitlist {
+			msg += fmt.Sprintf(" %d. %s\n", i+1, p.UserName)
+		}
+	}
+	if bg.IsGame() {
+		msg += bg.FormatBringer(localizer) + "\n"
+	}
+	if len(bg.Owners) > 0 {
+		msg += "🏠 " + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "OwnedBy",
+			},
+			TemplateData: map[string]string{
+				"Names": strings.Join(bg.Owners, ", "),
+			},
+		}) + "\n"
+	}
 	msg += "\n"
 
 	joinT := localizer.MustLocalize(&i18n.LocalizeConfig{
@@ -117,6 +495,15 @@ func (e Event) FormatBG(localizer *i18n.Localizer, baseUrl string, botName strin
 
 	if bg.Name == PLAYER_COUNTER {
 		joinT = localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
+	} else if bg.IsFull() {
+		joinT = localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "JoinWaitlist",
+			},
+			TemplateData: map[string]string{
+				"Name": bg.Name,
+			},
+		})
 	}
 
 	btn := telebot.InlineButton{
@@ -125,15 +512,64 @@ func (e Event) FormatBG(localizer *i18n.Localizer, baseUrl string, botName strin
 		Data:   fmt.Sprintf("%s|%d", e.ID, bg.ID),
 	}
 
-	return msg, btn, nil
+	leave := telebot.InlineButton{
+		Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "LeaveGame"}),
+		Unique: string(LeaveGame),
+		Data:   fmt.Sprintf("%s|%d", e.ID, bg.ID),
+	}
+
+	if !bg.IsGame() {
+		return msg, []telebot.InlineButton{btn, leave}, nil
+	}
+
+	bring := telebot.InlineButton{
+		Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "BringIt"}),
+		Unique: string(BringGame),
+		Data:   fmt.Sprintf("%s|%d", e.ID, bg.ID),
+	}
+
+	return msg, []telebot.InlineButton{btn, leave, bring}, nil
 }
 
 func (e Event) FormatMsg(localizer *i18n.Localizer, baseUrl string, botName string) (string, *telebot.ReplyMarkup) {
-	btns := []telebot.InlineButton{}
+	rows := [][]telebot.InlineButton{}
+
+	title := e.Name
+	if e.IsLocked() && !strings.Contains(title, "🔒") {
+		title += " 🔒"
+	}
+
+	msg := "📆 <b>" + title + "</b> <code>#" + e.Code() + "</code>\n"
+	if banner := e.Banner(localizer); banner != "" {
+		msg += "<b>" + banner + "</b>\n"
+	}
+	if e.StartsAt != nil {
+		msg += "🕗 " + e.FormatWhen() + "\n"
+	}
+	if e.Location != nil && *e.Location != "" {
+		msg += "📍 " + html.EscapeString(*e.Location) + "\n"
+	}
+	if e.JoinMode == JoinMultiple {
+		msg += "🎲🎲 " + localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinModeMultiple"}) + "\n"
+	}
+	if len(e.Organizers) > 0 {
+		names := []string{}
+		for _, o := range e.Organizers {
+			names = append(names, html.EscapeString(o.UserName))
+		}
 
-	msg := "📆 <b>" + e.Name + "</b>\n\n"
+		msg += "👑 " + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "CoOrganizers",
+			},
+			TemplateData: map[string]string{
+				"Names": strings.Join(names, ", "),
+			},
+		}) + "\n"
+	}
+	msg += "\n"
 	for _, bg := range e.BoardGames {
-		bgMsg, btn, err := e.FormatBG(localizer, baseUrl, botName)
+		bgMsg, row, err := e.FormatBG(localizer, baseUrl, botName, bg)
 		if err != nil {
 			log.Printf("Failed to format board game: %v", err)
 			continue
@@ -141,8 +577,9 @@ func (e Event) FormatMsg(localizer *i18n.Localizer, baseUrl string, botName stri
 
 		msg += bgMsg
 
-		btns = append(btns, btn)
-
+		if e.IsActive() {
+			rows = append(rows, row)
+		}
 	}
 
 	msg += localizer.MustLocalize(&i18n.LocalizeConfig{
@@ -154,13 +591,20 @@ func (e Event) FormatMsg(localizer *i18n.Localizer, baseUrl string, botName stri
 		},
 	})
 
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	if !e.IsActive() {
+		return msg, markup
+	}
+
 	btn := telebot.InlineButton{
 		Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "NotComing"}),
 		Unique: string(Cancel),
 		Data:   e.ID,
 	}
 
-	btns = append(btns, btn)
+	rows = append(rows, []telebot.InlineButton{btn})
 
 	if e.ChatID > 0 {
 		btn2 := telebot.InlineButton{
@@ -169,25 +613,133 @@ func (e Event) FormatMsg(localizer *i18n.Localizer, baseUrl string, botName stri
 				URL: fmt.Sprintf("%s/events/%s/", baseUrl, e.ID),
 			},
 		}
-		btns = append(btns, btn2)
+		rows = append(rows, []telebot.InlineButton{btn2})
 	} else {
 		btn2 := telebot.InlineButton{
 			Text: localizer.MustLocalizeMessage(&i18n.Message{ID: "AddGame"}),
 			URL:  fmt.Sprintf("https://t.me/%s/home?startapp=%s", botName, e.ID),
 		}
-		btns = append(btns, btn2)
+		rows = append(rows, []telebot.InlineButton{btn2})
 
 	}
 
-	markup := &telebot.ReplyMarkup{}
-	markup.InlineKeyboard = [][]telebot.InlineButton{}
-	for _, btn := range btns {
-		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{btn})
-	}
+	markup.InlineKeyboard = rows
 
 	return msg, markup
 }
 
+// Banner describes a closed or cancelled event.
+func (e Event) Banner(localizer *i18n.Localizer) string {
+	switch e.Status {
+	case StatusClosed:
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "EventClosedBanner"})
+	case StatusCancelled:
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "EventCancelledBanner"})
+	}
+
+	return ""
+}
+
+// FormatWhen renders the start, and the end when known, in the local time zone.
+func (e Event) FormatWhen() string {
+	if e.StartsAt == nil {
+		return ""
+	}
+
+	startsAt := e.StartsAt.Local()
+	when := startsAt.Format(EventTimeLayout)
+	if e.EndsAt != nil {
+		endsAt := e.EndsAt.Local()
+		if endsAt.Format(time.DateOnly) == startsAt.Format(time.DateOnly) {
+			when += " - " + endsAt.Format("15:04")
+		} else {
+			when += " - " + endsAt.Format(EventTimeLayout)
+		}
+	}
+
+	return when
+}
+
+const EventTimeLayout = "2006-01-02 15:04"
+
+var ErrInvalidEventTime = errors.New("invalid event time")
+
+// ParseEventTime parses a date, optionally followed by a time, in the local
+// time zone. Both the /create format and the HTML datetime-local format are
+// accepted.
+func ParseEventTime(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{EventTimeLayout, "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, ErrInvalidEventTime
+}
+
+// ParseEventWhen parses "2026-10-23 20:00" or a range such as
+// "2026-10-23 20:00 - 23:00" or "2026-10-23 20:00 - 2026-10-24 01:00".
+func ParseEventWhen(s string) (*time.Time, *time.Time, error) {
+	parts := strings.SplitN(s, " - ", 2)
+
+	startsAt, err := ParseEventTime(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(parts) == 1 {
+		return startsAt, nil, nil
+	}
+
+	end := strings.TrimSpace(parts[1])
+	if clock, err := time.Parse("15:04", end); err == nil {
+		endsAt := time.Date(startsAt.Year(), startsAt.Month(), startsAt.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+		if endsAt.Before(*startsAt) {
+			endsAt = endsAt.AddDate(0, 0, 1)
+		}
+
+		return startsAt, &endsAt, nil
+	}
+
+	endsAt, err := ParseEventTime(end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if endsAt.Before(*startsAt) {
+		return nil, nil, ErrInvalidEventTime
+	}
+
+	return startsAt, endsAt, nil
+}
+
+// ParseCreateArgs splits the /create argument "name | when | location", where
+// when and location are optional.
+func ParseCreateArgs(text string) (string, *time.Time, *time.Time, *string, error) {
+	parts := strings.Split(text, "|")
+	name := strings.TrimSpace(parts[0])
+
+	var startsAt, endsAt *time.Time
+	var location *string
+	var err error
+
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+		if startsAt, endsAt, err = ParseEventWhen(parts[1]); err != nil {
+			return "", nil, nil, nil, err
+		}
+	}
+
+	if len(parts) > 2 {
+		l := strings.TrimSpace(strings.Join(parts[2:], "|"))
+		if l != "" {
+			location = &l
+		}
+	}
+
+	return name, startsAt, endsAt, location, nil
+}
+
 func ExtractBoardGameID(inputURL string) (int64, bool) {
 	parsedURL, err := url.Parse(inputURL)
 	if err != nil {
@@ -200,7 +752,7 @@ func ExtractBoardGameID(inputURL string) (int64, bool) {
 	}
 
 	// Define regex to extract the ID
-	pattern := `^/boardgame/(\d+)/[a-zA-Z0-9-]+$`
+	pattern := `^/boardgame/(\d+)(/[a-zA-Z0-9-]+)?$`
 	re := regexp.MustCompile(pattern)
 	matches := re.FindStringSubmatch(parsedURL.Path)
 
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"html"
+	"log"
+	"strconv"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"gopkg.in/telebot.v3"
+)
+
+// NotificationKind is a change of a game a player can be told about in
+// private.
+type NotificationKind string
+
+const (
+	// NotifyGameFull is sent to the seated players when the last seat of
+	// their game is taken.
+	NotifyGameFull NotificationKind = "full"
+	// NotifyGameDeleted is sent to the players of a game removed from the
+	// event.
+	NotifyGameDeleted NotificationKind = "deleted"
+	// NotifyMaxPlayers is sent to the players of a game whose number of seats
+	// changed.
+	NotifyMaxPlayers NotificationKind = "max_players"
+	// NotifySeat is sent to a player who got a seat from the waitlist or lost
+	// it.
+	NotifySeat NotificationKind = "seat"
+)
+
+// NotificationKinds are listed in the order of the preference buttons.
+var NotificationKinds = []NotificationKind{NotifyGameFull, NotifyGameDeleted, NotifyMaxPlayers, NotifySeat}
+
+func ParseNotificationKind(s string) (NotificationKind, bool) {
+	for _, k := range NotificationKinds {
+		if string(k) == s {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+// NotificationKindMessageID is the localization key naming the kind.
+func NotificationKindMessageID(k NotificationKind) string {
+	switch k {
+	case NotifyGameFull:
+		return "NotificationGameFull"
+	case NotifyGameDeleted:
+		return "NotificationGameDeleted"
+	case NotifyMaxPlayers:
+		return "NotificationMaxPlayers"
+	}
+
+	return "NotificationSeat"
+}
+
+// Wants reports whether the user asked to be told about the kind of change,
+// which needs their private chat.
+func (u User) Wants(k NotificationKind) bool {
+	if u.ChatID == nil {
+		return false
+	}
+
+	switch k {
+	case NotifyGameFull:
+		return u.NotifyFull
+	case NotifyGameDeleted:
+		return u.NotifyDeleted
+	case NotifyMaxPlayers:
+		return u.NotifyMaxPlayers
+	case NotifySeat:
+		return u.NotifySeat
+	}
+
+	return false
+}
+
+// SetNotification turns the kind of change on or off.
+func (u *User) SetNotification(k NotificationKind, on bool) {
+	switch k {
+	case NotifyGameFull:
+		u.NotifyFull = on
+	case NotifyGameDeleted:
+		u.NotifyDeleted = on
+	case NotifyMaxPlayers:
+		u.NotifyMaxPlayers = on
+	case NotifySeat:
+		u.NotifySeat = on
+	}
+}
+
+// SetNotifications turns every kind of change on or off.
+func (u *User) SetNotifications(on bool) {
+	for _, k := range NotificationKinds {
+		u.SetNotification(k, on)
+	}
+}
+
+// Notification is a change of a game to tell a player about, Seated tells
+// whether they have a seat after it.
+type Notification struct {
+	UserID    int64
+	Kind      NotificationKind
+	BoardGame BoardGame
+	Seated    bool
+}
+
+// Notifications compares the event before and after a change made by the
+// actor, returning at most one notification per player and game. The actor
+// is not told about their own change.
+func Notifications(before, after *Event, actorID int64) []Notification {
+	notifications := []Notification{}
+	notified := map[int64]map[int64]bool{}
+
+	add := func(userID int64, kind NotificationKind, bg BoardGame, seated bool) {
+		if userID == actorID {
+			return
+		}
+		if notified[bg.ID] == nil {
+			notified[bg.ID] = map[int64]bool{}
+		}
+		if notified[bg.ID][userID] {
+			return
+		}
+
+		notified[bg.ID][userID] = true
+		notifications = append(notifications, Notification{UserID: userID, Kind: kind, BoardGame: bg, Seated: seated})
+	}
+
+	// the most relevant change of a game comes first, so that it is the one
+	// kept for each player
+	for _, old := range before.BoardGames {
+		if !old.IsGame() || after.BoardGame(old.ID) != nil {
+			continue
+		}
+
+		for _, p := range append(append([]Participant{}, old.Participants...), old.Waitlist...) {
+			add(p.UserID, NotifyGameDeleted, old, false)
+		}
+	}
+
+	for _, bg := range after.BoardGames {
+		old := before.BoardGame(bg.ID)
+		if !bg.IsGame() || old == nil {
+			continue
+		}
+
+		for _, p := range bg.Participants {
+			if old.IsWaiting(p.UserID) {
+				add(p.UserID, NotifySeat, bg, true)
+			}
+		}
+
+		for _, p := range old.Participants {
+			if !bg.IsSeated(p.UserID) {
+				add(p.UserID, NotifySeat, bg, false)
+			}
+		}
+
+		if old.MaxPlayers != bg.MaxPlayers {
+			for _, p := range append(append([]Participant{}, bg.Participants...), bg.Waitlist...) {
+				add(p.UserID, NotifyMaxPlayers, bg, bg.IsSeated(p.UserID))
+			}
+		}
+
+		if !old.IsFull() && bg.IsFull() {
+			for _, p := range bg.Participants {
+				add(p.UserID, NotifyGameFull, bg, true)
+			}
+		}
+	}
+
+	return notifications
+}
+
+// FormatNotification is the private message telling the player about the
+// change of their game.
+func (e Event) FormatNotification(localizer *i18n.Localizer, n Notification) string {
+	messageID := "NotifyGameFull"
+	switch n.Kind {
+	case NotifyGameDeleted:
+		messageID = "NotifyGameDeleted"
+	case NotifyMaxPlayers:
+		messageID = "NotifyMaxPlayers"
+		if n.BoardGame.MaxPlayers == -1 {
+			messageID = "NotifyNoMaxPlayers"
+		}
+	case NotifySeat:
+		messageID = "NotifySeatLost"
+		if n.Seated {
+			messageID = "NotifySeatTaken"
+		}
+	}
+
+	msg := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: messageID,
+		},
+		TemplateData: map[string]string{
+			"Name":       html.EscapeString(n.BoardGame.Name),
+			"Event":      html.EscapeString(e.Name),
+			"MaxPlayers": strconv.FormatInt(n.BoardGame.MaxPlayers, 10),
+		},
+	})
+
+	if n.Kind == NotifyMaxPlayers && n.BoardGame.IsWaiting(n.UserID) {
+		msg += "\n" + localizer.MustLocalizeMessage(&i18n.Message{ID: "NotifyStillWaiting"})
+	}
+
+	return msg
+}
+
+// SendNotifications tells in private the players who opted in about the
+// changes of their games, leaving out the one who made them. selectUser
+// returns nil for the players who never wrote to the bot.
+func SendNotifications(bot *telebot.Bot, localizer *i18n.Localizer, before, after *Event, actorID int64, selectUser func(userID int64) (*User, error)) {
+	users := map[int64]*User{}
+
+	for _, n := range Notifications(before, after, actorID) {
+		user, ok := users[n.UserID]
+		if !ok {
+			var err error
+			if user, err = selectUser(n.UserID); err != nil {
+				log.Println("failed to load user:", err)
+			}
+			users[n.UserID] = user
+		}
+
+		if user == nil || !user.Wants(n.Kind) {
+			continue
+		}
+
+		if _, err := bot.Send(&telebot.Chat{ID: *user.ChatID}, after.FormatNotification(localizer, n), telebot.NoPreview); err != nil {
+			log.Println("failed to send notification:", err)
+		}
+	}
+}
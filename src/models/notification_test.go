@@ -0,0 +1,123 @@
+package models
+
+import (
+	"testing"
+)
+
+func participants(ids ...int64) []Participant {
+	list := []Participant{}
+	for _, id := range ids {
+		list = append(list, Participant{UserID: id})
+	}
+
+	return list
+}
+
+func TestNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  []BoardGame
+		after   []BoardGame
+		actorID int64
+		expect  []Notification
+	}{
+		{
+			name:   "nothing changed",
+			before: []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 4, Participants: participants(1, 2)}},
+			after:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 4, Participants: participants(1, 2)}},
+			expect: []Notification{},
+		},
+		{
+			name: "a deleted game is told to its players and its waitlist, not to the actor",
+			before: []BoardGame{
+				{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 2), Waitlist: participants(3)},
+			},
+			after:   []BoardGame{},
+			actorID: 1,
+			expect: []Notification{
+				{UserID: 2, Kind: NotifyGameDeleted, BoardGame: BoardGame{ID: 1}},
+				{UserID: 3, Kind: NotifyGameDeleted, BoardGame: BoardGame{ID: 1}},
+			},
+		},
+		{
+			name: "the deleted game comes first, the full one is told to its own players",
+			before: []BoardGame{
+				{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1)},
+				{ID: 2, Name: "Brass", MaxPlayers: 2, Participants: participants(2)},
+			},
+			after: []BoardGame{
+				{ID: 2, Name: "Brass", MaxPlayers: 2, Participants: participants(2, 1)},
+			},
+			actorID: 9,
+			expect: []Notification{
+				{UserID: 1, Kind: NotifyGameDeleted, BoardGame: BoardGame{ID: 1}},
+				{UserID: 2, Kind: NotifyGameFull, BoardGame: BoardGame{ID: 2}, Seated: true},
+				{UserID: 1, Kind: NotifyGameFull, BoardGame: BoardGame{ID: 2}, Seated: true},
+			},
+		},
+		{
+			name:    "the actor filling the game is not told",
+			before:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1)}},
+			after:   []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 2)}},
+			actorID: 2,
+			expect: []Notification{
+				{UserID: 1, Kind: NotifyGameFull, BoardGame: BoardGame{ID: 1}, Seated: true},
+			},
+		},
+		{
+			name:    "the seat of the player who left goes to the waitlist, the game was already full",
+			before:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 2), Waitlist: participants(3)}},
+			after:   []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 3)}},
+			actorID: 2,
+			expect: []Notification{
+				{UserID: 3, Kind: NotifySeat, BoardGame: BoardGame{ID: 1}, Seated: true},
+			},
+		},
+		{
+			name:    "fewer seats: the removed player loses the seat, the others are told the new seats",
+			before:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 4, Participants: participants(1, 2, 3)}},
+			after:   []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 2), Waitlist: participants(3)}},
+			actorID: 1,
+			expect: []Notification{
+				{UserID: 3, Kind: NotifySeat, BoardGame: BoardGame{ID: 1}},
+				{UserID: 2, Kind: NotifyMaxPlayers, BoardGame: BoardGame{ID: 1}, Seated: true},
+			},
+		},
+		{
+			name:    "more seats: the waitlist is seated",
+			before:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 1, Participants: participants(1), Waitlist: participants(2, 3)}},
+			after:   []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: participants(1, 2), Waitlist: participants(3)}},
+			actorID: 1,
+			expect: []Notification{
+				{UserID: 2, Kind: NotifySeat, BoardGame: BoardGame{ID: 1}, Seated: true},
+				{UserID: 3, Kind: NotifyMaxPlayers, BoardGame: BoardGame{ID: 1}},
+			},
+		},
+		{
+			name:    "the counter of the players is not a game",
+			before:  []BoardGame{{ID: 1, Name: PLAYER_COUNTER, MaxPlayers: -1, Participants: participants(1, 2)}},
+			after:   []BoardGame{},
+			actorID: 9,
+			expect:  []Notification{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := &Event{BoardGames: tt.before}, &Event{BoardGames: tt.after}
+
+			got := Notifications(before, after, tt.actorID)
+			if len(got) != len(tt.expect) {
+				t.Fatalf("expected %+v, got %+v", tt.expect, got)
+			}
+
+			for i, n := range got {
+				expected := tt.expect[i]
+				if n.UserID != expected.UserID || n.Kind != expected.Kind || n.BoardGame.ID != expected.BoardGame.ID || n.Seated != expected.Seated {
+					t.Errorf("notification %d: expected user %d %s of game %d (seated %v), got user %d %s of game %d (seated %v)",
+						i, expected.UserID, expected.Kind, expected.BoardGame.ID, expected.Seated, n.UserID, n.Kind, n.BoardGame.ID, n.Seated)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Play is the result of a game of an event, recorded once it has been
+// played. The name and BoardGameGeek id of the game are kept, so that the
+// play still counts after the game is removed from the event.
+type Play struct {
+	ID              int64       `json:"id"`
+	EventID         string      `json:"event_id"`
+	BoardGameID     int64       `json:"boardgame_id"`
+	ChatID          int64       `json:"chat_id"`
+	Name            string      `json:"name"`
+	BggID           *int64      `json:"bgg_id"`
+	DurationMinutes *int        `json:"duration_minutes"`
+	Notes           *string     `json:"notes"`
+	UserID          int64       `json:"user_id"`
+	UserName        string      `json:"user_name"`
+	PlayedAt        time.Time   `json:"played_at"`
+	Scores          []PlayScore `json:"scores"`
+}
+
+// PlayScore is a player of a play, Score is nil for the games without
+// points.
+type PlayScore struct {
+	UserID   int64  `json:"user_id"`
+	UserName string `json:"user_name"`
+	Score    *int64 `json:"score"`
+	Winner   bool   `json:"winner"`
+}
+
+// NewPlay starts the play of a game of the event, recorded by the user. It
+// is dated when the event started, or now if it has not started yet.
+func NewPlay(event *Event, bg *BoardGame, userID int64, userName string, now time.Time) Play {
+	playedAt := now
+	if event.StartsAt != nil && event.StartsAt.Before(now) {
+		playedAt = *event.StartsAt
+	}
+
+	return Play{
+		EventID:     event.ID,
+		BoardGameID: bg.ID,
+		ChatID:      event.ChatID,
+		Name:        bg.Name,
+		BggID:       bg.BggID,
+		UserID:      userID,
+		UserName:    userName,
+		PlayedAt:    playedAt,
+		Scores:      []PlayScore{},
+	}
+}
+
+// ResultRequest records the result of a game from the web page.
+type ResultRequest struct {
+	UserID          int64       `json:"user_id" binding:"required"`
+	UserName        string      `json:"user_name" binding:"required"`
+	DurationMinutes *int        `json:"duration_minutes"`
+	Notes           *string     `json:"notes"`
+	Scores          []PlayScore `json:"scores"`
+}
+
+// ResultEntry is a player named in /result, Winner is set when the name is
+// marked with *. Text is the name with the score, for the names ending with
+// a number.
+type ResultEntry struct {
+	Name   string
+	Text   string
+	Score  *int64
+	Winner bool
+}
+
+var ErrInvalidResult = errors.New("invalid result")
+
+// ParseResult reads the arguments of /result: the players separated by
+// commas, each followed by their score if any, then the duration and the
+// notes, separated by |. The duration is in minutes or like 1h30m.
+func ParseResult(text string) ([]ResultEntry, *int, *string, error) {
+	parts := strings.SplitN(text, "|", 3)
+
+	entries := []ResultEntry{}
+	for _, field := range strings.Split(parts[0], ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		entry := ResultEntry{}
+		if strings.HasPrefix(field, "*") {
+			entry.Winner = true
+			field = strings.TrimSpace(field[1:])
+		}
+
+		words := strings.Fields(field)
+		entry.Text = strings.TrimPrefix(strings.Join(words, " "), "@")
+		if len(words) > 1 {
+			if score, err := strconv.ParseInt(words[len(words)-1], 10, 64); err == nil {
+				entry.Score = &score
+				words = words[:len(words)-1]
+			}
+		}
+
+		entry.Name = strings.TrimPrefix(strings.Join(words, " "), "@")
+		if entry.Name == "" {
+			return nil, nil, nil, ErrInvalidResult
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil, nil, ErrInvalidResult
+	}
+
+	var duration *int
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+		minutes, err := ParsePlayDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		duration = &minutes
+	}
+
+	var notes *string
+	if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+		n := strings.TrimSpace(parts[2])
+		notes = &n
+	}
+
+	return entries, duration, notes, nil
+}
+
+// ParsePlayDuration reads a duration in minutes, or like 1h30m.
+func ParsePlayDuration(s string) (int, error) {
+	if minutes, err := strconv.Atoi(s); err == nil {
+		if minutes <= 0 {
+			return 0, ErrInvalidResult
+		}
+		return minutes, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute {
+		return 0, ErrInvalidResult
+	}
+
+	return int(d.Minutes()), nil
+}
+
+// Participant returns the player of the event with the given id, seated or
+// waiting at any game, nil when missing.
+func (e Event) Participant(userID int64) *Participant {
+	for _, bg := range e.BoardGames {
+		for _, p := range append(append([]Participant{}, bg.Participants...), bg.Waitlist...) {
+			if p.UserID == userID {
+				return &p
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveResult matches the names of the entries with the players of the
+// game, then with the other players of the event. A name ending with a number
+// is taken whole, without a score, when a player has it. It returns the first
+// name matching nobody.
+func ResolveResult(event *Event, bg *BoardGame, entries []ResultEntry) ([]PlayScore, string) {
+	candidates := append([]Participant{}, bg.Participants...)
+	for _, other := range event.BoardGames {
+		candidates = append(candidates, other.Participants...)
+		candidates = append(candidates, other.Waitlist...)
+	}
+
+	scores := []PlayScore{}
+	seen := map[int64]bool{}
+	find := func(name string) *Participant {
+		for i, p := range candidates {
+			if strings.EqualFold(p.UserName, name) {
+				return &candidates[i]
+			}
+		}
+
+		return nil
+	}
+
+	for _, entry := range entries {
+		var player *Participant
+		if entry.Score != nil {
+			if player = find(entry.Text); player != nil {
+				entry.Score = nil
+			}
+		}
+
+		if player == nil {
+			player = find(entry.Name)
+		}
+
+		if player == nil {
+			return nil, entry.Name
+		}
+
+		if seen[player.UserID] {
+			continue
+		}
+		seen[player.UserID] = true
+
+		scores = append(scores, PlayScore{UserID: player.UserID, UserName: player.UserName, Score: entry.Score, Winner: entry.Winner})
+	}
+
+	return MarkWinners(scores), ""
+}
+
+// MarkWinners makes the players with the highest score the winners, unless
+// some winners are already marked.
+func MarkWinners(scores []PlayScore) []PlayScore {
+	var best *int64
+	for _, s := range scores {
+		if s.Winner {
+			return scores
+		}
+		if s.Score != nil && (best == nil || *s.Score > *best) {
+			best = s.Score
+		}
+	}
+
+	if best == nil {
+		return scores
+	}
+
+	for i, s := range scores {
+		scores[i].Winner = s.Score != nil && *s.Score == *best
+	}
+
+	return scores
+}
+
+// CanRecordResult reports whether the user can record the result of the
+// game: its players and the organizers can, also once the event is closed.
+func (e Event) CanRecordResult(bg BoardGame, userID int64) bool {
+	return e.Status != StatusCancelled && bg.IsGame() && (bg.IsSeated(userID) || e.IsOrganizer(userID))
+}
+
+// SortScores orders the players from the winners to the lowest score, the
+// ones without a score last.
+func (p *Play) SortScores() {
+	sort.SliceStable(p.Scores, func(i, j int) bool {
+		a, b := p.Scores[i], p.Scores[j]
+		switch {
+		case a.Winner != b.Winner:
+			return a.Winner
+		case (a.Score == nil) != (b.Score == nil):
+			return a.Score != nil
+		case a.Score != nil && *a.Score != *b.Score:
+			return *a.Score > *b.Score
+		}
+
+		return a.UserName < b.UserName
+	})
+}
+
+// Winners returns the names of the winners of the play.
+func (p Play) Winners() []string {
+	names := []string{}
+	for _, s := range p.Scores {
+		if s.Winner {
+			names = append(names, s.UserName)
+		}
+	}
+
+	return names
+}
+
+// Score returns the score of the user in the play, if they played it.
+func (p Play) Score(userID int64) *PlayScore {
+	for i, s := range p.Scores {
+		if s.UserID == userID {
+			return &p.Scores[i]
+		}
+	}
+
+	return nil
+}
+
+// FormatPlayDuration renders the minutes of a play like FormatDuration, without
+// rounding them.
+func FormatPlayDuration(minutes int) string {
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// Duration renders how long the play lasted, empty when it is unknown.
+func (p Play) Duration() string {
+	if p.DurationMinutes == nil {
+		return ""
+	}
+
+	return FormatPlayDuration(*p.DurationMinutes)
+}
+
+// ResultScores lists the players of the recorded play, then the other
+// players seated at the game, for the form recording its result.
+func (bg BoardGame) ResultScores(play *Play) []PlayScore {
+	scores := []PlayScore{}
+	seen := map[int64]bool{}
+	if play != nil {
+		for _, s := range play.Scores {
+			seen[s.UserID] = true
+			scores = append(scores, s)
+		}
+	}
+
+	for _, p := range bg.Participants {
+		if !seen[p.UserID] {
+			scores = append(scores, PlayScore{UserID: p.UserID, UserName: p.UserName})
+		}
+	}
+
+	return scores
+}
+
+// FormatPlay renders the result of the play, its scores must be sorted.
+func (p Play) FormatPlay(localizer *i18n.Localizer, eventName string) string {
+	msg := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "PlayResult",
+		},
+		TemplateData: map[string]string{
+			"Name":  html.EscapeString(p.Name),
+			"Event": html.EscapeString(eventName),
+		},
+	}) + "\n"
+
+	for _, s := range p.Scores {
+		line := "▫️ " + html.EscapeString(s.UserName)
+		if s.Winner {
+			line = "🏆 <b>" + html.EscapeString(s.UserName) + "</b>"
+		}
+		if s.Score != nil {
+			line += fmt.Sprintf(" · %d", *s.Score)
+		}
+
+		msg += line + "\n"
+	}
+
+	if p.DurationMinutes != nil {
+		msg += "\n⏱ " + p.Duration()
+	}
+
+	if p.Notes != nil && *p.Notes != "" {
+		msg += "\n📝 " + html.EscapeString(*p.Notes)
+	}
+
+	return strings.TrimRight(msg, "\n")
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entries  []ResultEntry
+		duration *int
+		notes    *string
+		err      error
+	}{
+		{
+			name: "scores, duration and notes",
+			text: "alice 42, bob 37 | 1h30m | close game",
+			entries: []ResultEntry{
+				{Name: "alice", Text: "alice 42", Score: score(42)},
+				{Name: "bob", Text: "bob 37", Score: score(37)},
+			},
+			duration: intPtr(90),
+			notes:    strPtr("close game"),
+		},
+		{
+			name: "winners marked with * and names with @",
+			text: "*carol, @alice 3, * @bob",
+			entries: []ResultEntry{
+				{Name: "carol", Text: "carol", Winner: true},
+				{Name: "alice", Text: "alice 3", Score: score(3)},
+				{Name: "bob", Text: "bob", Winner: true},
+			},
+		},
+		{
+			name: "a trailing number is read as the score",
+			text: "Player 2, Player 3 10",
+			entries: []ResultEntry{
+				{Name: "Player", Text: "Player 2", Score: score(2)},
+				{Name: "Player 3", Text: "Player 3 10", Score: score(10)},
+			},
+		},
+		{
+			name: "a number alone is a name",
+			text: "42 | 90",
+			entries: []ResultEntry{
+				{Name: "42", Text: "42"},
+			},
+			duration: intPtr(90),
+		},
+		{
+			name:    "empty duration and notes",
+			text:    "alice, bob |  | ",
+			entries: []ResultEntry{{Name: "alice", Text: "alice"}, {Name: "bob", Text: "bob"}},
+		},
+		{name: "no players", text: " , | 90", err: ErrInvalidResult},
+		{name: "only a mark", text: "alice, *", err: ErrInvalidResult},
+		{name: "zero duration", text: "alice | 0", err: ErrInvalidResult},
+		{name: "duration below a minute", text: "alice | 30s", err: ErrInvalidResult},
+		{name: "invalid duration", text: "alice | long", err: ErrInvalidResult},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, duration, notes, err := ParseResult(tt.text)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if len(entries) != len(tt.entries) {
+				t.Fatalf("expected %d entries, got %+v", len(tt.entries), entries)
+			}
+
+			for i, e := range entries {
+				expected := tt.entries[i]
+				if e.Name != expected.Name || e.Text != expected.Text || e.Winner != expected.Winner || !equalInt64(e.Score, expected.Score) {
+					t.Errorf("entry %d: expected %+v, got %+v", i, expected, e)
+				}
+			}
+
+			if (duration == nil) != (tt.duration == nil) || (duration != nil && *duration != *tt.duration) {
+				t.Errorf("expected duration %v, got %v", tt.duration, duration)
+			}
+
+			if (notes == nil) != (tt.notes == nil) || (notes != nil && *notes != *tt.notes) {
+				t.Errorf("expected notes %v, got %v", tt.notes, notes)
+			}
+		})
+	}
+}
+
+func TestParsePlayDuration(t *testing.T) {
+	tests := []struct {
+		text    string
+		minutes int
+		valid   bool
+	}{
+		{text: "90", minutes: 90, valid: true},
+		{text: "1h30m", minutes: 90, valid: true},
+		{text: "2h", minutes: 120, valid: true},
+		{text: "1m30s", minutes: 1, valid: true},
+		{text: "0", valid: false},
+		{text: "-5", valid: false},
+		{text: "30s", valid: false},
+		{text: "soon", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			minutes, err := ParsePlayDuration(tt.text)
+			if tt.valid && (err != nil || minutes != tt.minutes) {
+				t.Errorf("expected %d minutes, got %d (%v)", tt.minutes, minutes, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidResult) {
+				t.Errorf("expected ErrInvalidResult, got %d (%v)", minutes, err)
+			}
+		})
+	}
+}
+
+func TestResolveResult(t *testing.T) {
+	catan := BoardGame{ID: 1, Name: "Catan", Participants: []Participant{
+		{UserID: 1, UserName: "Alice"},
+		{UserID: 2, UserName: "bob"},
+		{UserID: 3, UserName: "Player 2"},
+	}}
+	azul := BoardGame{ID: 2, Name: "Azul", Participants: []Participant{{UserID: 4, UserName: "dave"}}, Waitlist: []Participant{{UserID: 5, UserName: "erin"}}}
+	event := &Event{BoardGames: []BoardGame{catan, azul}}
+
+	tests := []struct {
+		name    string
+		text    string
+		scores  []PlayScore
+		unknown string
+	}{
+		{
+			name:   "the highest score wins",
+			text:   "alice 42, BOB 37",
+			scores: []PlayScore{{UserID: 1, UserName: "Alice", Score: score(42), Winner: true}, {UserID: 2, UserName: "bob", Score: score(37)}},
+		},
+		{
+			name: "tied top scores all win",
+			text: "alice 10, bob 10, dave 5",
+			scores: []PlayScore{
+				{UserID: 1, UserName: "Alice", Score: score(10), Winner: true},
+				{UserID: 2, UserName: "bob", Score: score(10), Winner: true},
+				{UserID: 4, UserName: "dave", Score: score(5)},
+			},
+		},
+		{
+			name:   "marked winners are kept",
+			text:   "alice 10, *bob 3",
+			scores: []PlayScore{{UserID: 1, UserName: "Alice", Score: score(10)}, {UserID: 2, UserName: "bob", Score: score(3), Winner: true}},
+		},
+		{
+			name:   "no scores and no marks make no winner",
+			text:   "alice, bob",
+			scores: []PlayScore{{UserID: 1, UserName: "Alice"}, {UserID: 2, UserName: "bob"}},
+		},
+		{
+			name:   "a name ending with a number without a score",
+			text:   "Player 2, alice 4",
+			scores: []PlayScore{{UserID: 3, UserName: "Player 2"}, {UserID: 1, UserName: "Alice", Score: score(4), Winner: true}},
+		},
+		{
+			name:   "a name ending with a number with a score",
+			text:   "Player 2 7, alice 4",
+			scores: []PlayScore{{UserID: 3, UserName: "Player 2", Score: score(7), Winner: true}, {UserID: 1, UserName: "Alice", Score: score(4)}},
+		},
+		{
+			name:   "duplicate names keep the first",
+			text:   "bob 3, alice 5, Bob 9",
+			scores: []PlayScore{{UserID: 2, UserName: "bob", Score: score(3)}, {UserID: 1, UserName: "Alice", Score: score(5), Winner: true}},
+		},
+		{
+			name:   "players of the other games of the event",
+			text:   "erin 2, dave 1",
+			scores: []PlayScore{{UserID: 5, UserName: "erin", Score: score(2), Winner: true}, {UserID: 4, UserName: "dave", Score: score(1)}},
+		},
+		{
+			name:    "unknown players",
+			text:    "alice 3, frank 2, gina 1",
+			unknown: "frank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, _, _, err := ParseResult(tt.text)
+			if err != nil {
+				t.Fatalf("ParseResult: %v", err)
+			}
+
+			scores, unknown := ResolveResult(event, &catan, entries)
+			if unknown != tt.unknown {
+				t.Fatalf("expected unknown %q, got %q", tt.unknown, unknown)
+			}
+
+			if len(scores) != len(tt.scores) {
+				t.Fatalf("expected %+v, got %+v", tt.scores, scores)
+			}
+
+			for i, s := range scores {
+				expected := tt.scores[i]
+				if s.UserID != expected.UserID || s.UserName != expected.UserName || s.Winner != expected.Winner || !equalInt64(s.Score, expected.Score) {
+					t.Errorf("player %d: expected %+v, got %+v", i, expected, s)
+				}
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func equalInt64(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,273 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// InitialRating is the rating of a player before their first play.
+	InitialRating = 1500.0
+	// RatingK is the most a rating moves after a play.
+	RatingK = 32.0
+	// LeaderboardSize is the number of players shown by /leaderboard.
+	LeaderboardSize = 10
+)
+
+// Rating is the Elo rating of a player, computed from the plays of a chat.
+type Rating struct {
+	Rank     int     `json:"rank"`
+	UserID   int64   `json:"user_id"`
+	UserName string  `json:"user_name"`
+	Rating   float64 `json:"rating"`
+	Plays    int     `json:"plays"`
+	Wins     int     `json:"wins"`
+}
+
+// Points is the rating rounded for display.
+func (r Rating) Points() int64 {
+	return int64(math.Round(r.Rating))
+}
+
+// GameRatings are the ratings of the players of one game.
+type GameRatings struct {
+	Name    string   `json:"name"`
+	Plays   int      `json:"plays"`
+	Ratings []Rating `json:"ratings"`
+}
+
+// GameKey identifies the game of a play across events: its BoardGameGeek id
+// when linked, its name otherwise.
+func (p Play) GameKey() string {
+	if p.BggID != nil {
+		return fmt.Sprintf("bgg:%d", *p.BggID)
+	}
+
+	return strings.ToLower(strings.TrimSpace(p.Name))
+}
+
+// beats compares two players of a play: 1 when a beat b, 0.5 for a draw and 0
+// when b beat a. Winners beat the others, the scores break the ties.
+func beats(a, b PlayScore) float64 {
+	switch {
+	case a.Winner != b.Winner:
+		if a.Winner {
+			return 1
+		}
+		return 0
+	case a.Score == nil || b.Score == nil || *a.Score == *b.Score:
+		return 0.5
+	case *a.Score > *b.Score:
+		return 1
+	}
+
+	return 0
+}
+
+// ComputeRatings replays the plays from the first one, each play being a
+// round of games between every pair of its players. The ratings are never
+// stored, so they always reflect the results as they are now. They are
+// sorted from the best player.
+func ComputeRatings(plays []Play) []Rating {
+	sorted := append([]Play{}, plays...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].PlayedAt.Equal(sorted[j].PlayedAt) {
+			return sorted[i].PlayedAt.Before(sorted[j].PlayedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	ratings := map[int64]*Rating{}
+	for _, play := range sorted {
+		for _, s := range play.Scores {
+			r, ok := ratings[s.UserID]
+			if !ok {
+				r = &Rating{UserID: s.UserID, Rating: InitialRating}
+				ratings[s.UserID] = r
+			}
+
+			r.UserName = s.UserName
+			r.Plays++
+			if s.Winner {
+				r.Wins++
+			}
+		}
+
+		if len(play.Scores) < 2 {
+			continue
+		}
+
+		// every pair is compared with the ratings before the play, the K
+		// factor is shared so that a play moves a rating as much as a duel
+		k := RatingK / float64(len(play.Scores)-1)
+		deltas := make([]float64, len(play.Scores))
+		for i, a := range play.Scores {
+			for j, b := range play.Scores {
+				if i == j {
+					continue
+				}
+
+				expected := 1 / (1 + math.Pow(10, (ratings[b.UserID].Rating-ratings[a.UserID].Rating)/400))
+				deltas[i] += k * (beats(a, b) - expected)
+			}
+		}
+
+		for i, s := range play.Scores {
+			ratings[s.UserID].Rating += deltas[i]
+		}
+	}
+
+	result := []Rating{}
+	for _, r := range ratings {
+		result = append(result, *r)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.Points() != b.Points():
+			return a.Points() > b.Points()
+		case a.Wins != b.Wins:
+			return a.Wins > b.Wins
+		case a.UserName != b.UserName:
+			return a.UserName < b.UserName
+		}
+
+		return a.UserID < b.UserID
+	})
+
+	for i := range result {
+		result[i].Rank = i + 1
+	}
+
+	return result
+}
+
+// gamePlays are the plays of a game, named as in its latest play.
+type gamePlays struct {
+	name  string
+	plays []Play
+}
+
+// groupByGame splits the plays by game, the most played games first.
+func groupByGame(plays []Play) []gamePlays {
+	byGame := map[string]*gamePlays{}
+	keys := []string{}
+	for _, p := range plays {
+		g, ok := byGame[p.GameKey()]
+		if !ok {
+			g = &gamePlays{}
+			byGame[p.GameKey()] = g
+			keys = append(keys, p.GameKey())
+		}
+
+		g.plays = append(g.plays, p)
+	}
+
+	games := []gamePlays{}
+	for _, key := range keys {
+		g := byGame[key]
+		latest := g.plays[0]
+		for _, p := range g.plays {
+			if p.PlayedAt.After(latest.PlayedAt) || (p.PlayedAt.Equal(latest.PlayedAt) && p.ID > latest.ID) {
+				latest = p
+			}
+		}
+
+		g.name = latest.Name
+		games = append(games, *g)
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if len(games[i].plays) != len(games[j].plays) {
+			return len(games[i].plays) > len(games[j].plays)
+		}
+		return strings.ToLower(games[i].name) < strings.ToLower(games[j].name)
+	})
+
+	return games
+}
+
+// ComputeGameRatings computes the ratings of each game played, the most
+// played games first.
+func ComputeGameRatings(plays []Play) []GameRatings {
+	games := []GameRatings{}
+	for _, g := range groupByGame(plays) {
+		games = append(games, GameRatings{Name: g.name, Plays: len(g.plays), Ratings: ComputeRatings(g.plays)})
+	}
+
+	return games
+}
+
+// PlaysOfGame returns the plays of the game with the given name, or else of
+// the most played game whose name contains it, with the name of the game.
+func PlaysOfGame(plays []Play, name string) ([]Play, string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	games := groupByGame(plays)
+
+	for _, g := range games {
+		if strings.ToLower(g.name) == name {
+			return g.plays, g.name
+		}
+	}
+
+	for _, g := range games {
+		if strings.Contains(strings.ToLower(g.name), name) {
+			return g.plays, g.name
+		}
+	}
+
+	return nil, ""
+}
+
+// FormatLeaderboard renders the best players, the title names the game when
+// the ratings are of a single one.
+func FormatLeaderboard(localizer *i18n.Localizer, game string, ratings []Rating) string {
+	var msg string
+	if game == "" {
+		msg = localizer.MustLocalizeMessage(&i18n.Message{ID: "LeaderboardTitle"})
+	} else {
+		msg = localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "LeaderboardGameTitle",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(game),
+			},
+		})
+	}
+	msg += "\n"
+
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, r := range ratings {
+		if i == LeaderboardSize {
+			break
+		}
+
+		rank := fmt.Sprintf("%d.", r.Rank)
+		if i < len(medals) {
+			rank = medals[i]
+		}
+
+		msg += "\n" + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "LeaderboardLine",
+			},
+			TemplateData: map[string]string{
+				"Rank":   rank,
+				"Name":   html.EscapeString(r.UserName),
+				"Rating": strconv.FormatInt(r.Points(), 10),
+				"Plays":  strconv.Itoa(r.Plays),
+				"Wins":   strconv.Itoa(r.Wins),
+			},
+		})
+	}
+
+	return msg
+}
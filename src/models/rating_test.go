@@ -0,0 +1,188 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func score(v int64) *int64 {
+	return &v
+}
+
+// ratingOf returns the rating of the user, failing when they are missing.
+func ratingOf(t *testing.T, ratings []Rating, userID int64) Rating {
+	t.Helper()
+
+	for _, r := range ratings {
+		if r.UserID == userID {
+			return r
+		}
+	}
+
+	t.Fatalf("user %d not rated", userID)
+	return Rating{}
+}
+
+func TestComputeRatings(t *testing.T) {
+	start := time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		scores  []PlayScore
+		ratings map[int64]float64
+	}{
+		{
+			name:    "the winner of a duel takes 16 points",
+			scores:  []PlayScore{{UserID: 1, UserName: "alice", Score: score(40), Winner: true}, {UserID: 2, UserName: "bob", Score: score(30)}},
+			ratings: map[int64]float64{1: 1516, 2: 1484},
+		},
+		{
+			name:    "players without scores draw",
+			scores:  []PlayScore{{UserID: 1, UserName: "alice"}, {UserID: 2, UserName: "bob"}, {UserID: 3, UserName: "carol"}},
+			ratings: map[int64]float64{1: 1500, 2: 1500, 3: 1500},
+		},
+		{
+			name:    "the winners draw among them and beat the others",
+			scores:  []PlayScore{{UserID: 1, UserName: "alice", Winner: true}, {UserID: 2, UserName: "bob", Winner: true}, {UserID: 3, UserName: "carol"}},
+			ratings: map[int64]float64{1: 1508, 2: 1508, 3: 1484},
+		},
+		{
+			name: "the scores rank the players who did not win",
+			scores: []PlayScore{
+				{UserID: 1, UserName: "alice", Score: score(50), Winner: true},
+				{UserID: 2, UserName: "bob", Score: score(40)},
+				{UserID: 3, UserName: "carol", Score: score(30)},
+			},
+			ratings: map[int64]float64{1: 1516, 2: 1500, 3: 1484},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratings := ComputeRatings([]Play{{ID: 1, Name: "Catan", PlayedAt: start, Scores: tt.scores}})
+
+			total := 0.0
+			for userID, expected := range tt.ratings {
+				r := ratingOf(t, ratings, userID)
+				if math.Abs(r.Rating-expected) > 1e-9 {
+					t.Errorf("user %d: expected %v, got %v", userID, expected, r.Rating)
+				}
+				if r.Plays != 1 {
+					t.Errorf("user %d: expected 1 play, got %d", userID, r.Plays)
+				}
+				total += r.Rating
+			}
+
+			// a play only moves points between its players
+			if math.Abs(total-InitialRating*float64(len(tt.scores))) > 1e-9 {
+				t.Errorf("expected a zero-sum play, the total is %v", total)
+			}
+		})
+	}
+}
+
+func TestComputeRatingsIsZeroSum(t *testing.T) {
+	start := time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+	plays := []Play{
+		{ID: 1, PlayedAt: start, Scores: []PlayScore{{UserID: 1, Winner: true}, {UserID: 2}, {UserID: 3}, {UserID: 4}}},
+		{ID: 2, PlayedAt: start.Add(time.Hour), Scores: []PlayScore{{UserID: 2, Score: score(7), Winner: true}, {UserID: 3, Score: score(3)}}},
+		{ID: 3, PlayedAt: start.Add(2 * time.Hour), Scores: []PlayScore{{UserID: 1}, {UserID: 3, Score: score(12), Winner: true}, {UserID: 4, Score: score(9)}}},
+	}
+
+	total := 0.0
+	ratings := ComputeRatings(plays)
+	for _, r := range ratings {
+		total += r.Rating
+	}
+
+	if len(ratings) != 4 || math.Abs(total-4*InitialRating) > 1e-9 {
+		t.Errorf("expected the ratings of 4 players to sum to %v, got %v for %d", 4*InitialRating, total, len(ratings))
+	}
+
+	if r := ratingOf(t, ratings, 3); r.Plays != 3 || r.Wins != 1 {
+		t.Errorf("expected 3 plays and 1 win, got %+v", r)
+	}
+
+	for i, r := range ratings {
+		if r.Rank != i+1 || (i > 0 && r.Points() > ratings[i-1].Points()) {
+			t.Errorf("unexpected order %+v", ratings)
+		}
+	}
+}
+
+func TestComputeRatingsIsRecomputable(t *testing.T) {
+	start := time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+	plays := []Play{}
+	for i := 0; i < 20; i++ {
+		a, b, c := int64(i%4+1), int64((i+1)%4+1), int64((i+2)%4+1)
+		plays = append(plays, Play{
+			ID:       int64(i + 1),
+			PlayedAt: start.Add(time.Duration(i/2) * time.Hour),
+			Scores: []PlayScore{
+				{UserID: a, Score: score(int64(i % 7)), Winner: i%3 == 0},
+				{UserID: b, Score: score(int64(i % 5))},
+				{UserID: c},
+			},
+		})
+	}
+
+	expected := ComputeRatings(plays)
+
+	shuffled := append([]Play{}, plays...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	got := ComputeRatings(shuffled)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d ratings, got %d", len(expected), len(got))
+	}
+
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("place %d: expected %+v, got %+v", i+1, expected[i], got[i])
+		}
+	}
+}
+
+func TestPlaysOfGame(t *testing.T) {
+	bggCatan := int64(13)
+	start := time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+	plays := []Play{
+		{ID: 1, Name: "Catan", BggID: &bggCatan, PlayedAt: start},
+		{ID: 2, Name: "Catan (2020)", BggID: &bggCatan, PlayedAt: start.Add(time.Hour)},
+		{ID: 3, Name: "Catan Junior", PlayedAt: start},
+		{ID: 4, Name: "Azul", PlayedAt: start},
+		{ID: 5, Name: "azul ", PlayedAt: start.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		game   string
+		ids    []int64
+	}{
+		{name: "exact name, any case", search: "CATAN JUNIOR", game: "Catan Junior", ids: []int64{3}},
+		{name: "exact name before substring", search: "catan (2020)", game: "Catan (2020)", ids: []int64{1, 2}},
+		{name: "substring of the most played game", search: "cat", game: "Catan (2020)", ids: []int64{1, 2}},
+		{name: "same name without BoardGameGeek id", search: "azul", game: "azul ", ids: []int64{4, 5}},
+		{name: "no match", search: "dune", game: "", ids: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, game := PlaysOfGame(plays, tt.search)
+			if game != tt.game || len(got) != len(tt.ids) {
+				t.Fatalf("expected %q with %d plays, got %q with %d", tt.game, len(tt.ids), game, len(got))
+			}
+
+			for i, p := range got {
+				if p.ID != tt.ids[i] {
+					t.Errorf("expected play %d, got %d", tt.ids[i], p.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// DefaultReminders are sent when REMINDERS is not set.
+const DefaultReminders = "24h,2h"
+
+// ParseReminderOffsets reads a comma separated list of durations before the
+// start of the events, like 24h,2h or 30m, returned longest first.
+func ParseReminderOffsets(s string) ([]time.Duration, error) {
+	offsets := []time.Duration{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		offset, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if offset < time.Minute {
+			return nil, fmt.Errorf("reminder %s is shorter than a minute", part)
+		}
+
+		offsets = append(offsets, offset)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] > offsets[j]
+	})
+
+	return offsets, nil
+}
+
+// DueReminders returns the offsets whose time has come for an event not
+// started yet.
+func DueReminders(startsAt, now time.Time, offsets []time.Duration) []time.Duration {
+	due := []time.Duration{}
+	if !now.Before(startsAt) {
+		return due
+	}
+
+	for _, offset := range offsets {
+		if !now.Before(startsAt.Add(-offset)) {
+			due = append(due, offset)
+		}
+	}
+
+	return due
+}
+
+// FormatDuration renders the time left before an event, rounded to five
+// minutes, like 2h or 1h 55m.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(5 * time.Minute)
+	if d < 5*time.Minute {
+		d = 5 * time.Minute
+	}
+
+	hours, minutes := int(d/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func (e Event) reminderTitle(localizer *i18n.Localizer, now time.Time) string {
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "ReminderTitle",
+		},
+		TemplateData: map[string]string{
+			"Name": html.EscapeString(e.Name),
+			"In":   FormatDuration(e.StartsAt.Sub(now)),
+			"When": e.FormatWhen(),
+		},
+	})
+}
+
+// FormatReminder is posted in the chat of the event, mentioning the players
+// seated at each game.
+func (e Event) FormatReminder(localizer *i18n.Localizer, now time.Time) string {
+	msg := e.reminderTitle(localizer, now) + "\n"
+	if e.Location != nil && *e.Location != "" {
+		msg += "📍 " + html.EscapeString(*e.Location) + "\n"
+	}
+
+	for _, bg := range e.BoardGames {
+		if len(bg.Participants) == 0 {
+			continue
+		}
+
+		mentions := []string{}
+		for _, p := range bg.Participants {
+			mentions = append(mentions, p.Mention())
+		}
+
+		msg += "\n🎲 <b>" + html.EscapeString(bg.DisplayName(localizer)) + "</b>: " + strings.Join(mentions, ", ")
+	}
+
+	if unclaimed := e.UnclaimedGames(); len(unclaimed) > 0 {
+		msg += "\n\n⚠️ " + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "UnclaimedGames",
+			},
+			TemplateData: map[string]string{
+				"Games": html.EscapeString(strings.Join(unclaimed, ", ")),
+			},
+		})
+	}
+
+	return msg
+}
+
+// UnclaimedGames returns the names of the games nobody said they bring.
+func (e Event) UnclaimedGames() []string {
+	names := []string{}
+	for _, bg := range e.BoardGames {
+		if bg.IsUnclaimed() {
+			names = append(names, bg.Name)
+		}
+	}
+
+	return names
+}
+
+// FormatPrivateReminder is sent to a player who opted in, listing the games
+// they are seated at.
+func (e Event) FormatPrivateReminder(localizer *i18n.Localizer, now time.Time, userID int64) string {
+	msg := e.reminderTitle(localizer, now) + "\n"
+	if e.Location != nil && *e.Location != "" {
+		msg += "📍 " + html.EscapeString(*e.Location) + "\n"
+	}
+
+	for _, bg := range e.BoardGames {
+		if bg.IsSeated(userID) {
+			msg += "\n🎲 " + html.EscapeString(bg.DisplayName(localizer))
+		}
+	}
+
+	for _, bg := range e.BoardGames {
+		if bg.IsBroughtBy(userID) {
+			msg += "\n\n🎒 " + localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID: "RememberToBring",
+				},
+				TemplateData: map[string]string{
+					"Name": html.EscapeString(bg.Name),
+				},
+			})
+		}
+	}
+
+	return msg
+}
@@ -0,0 +1,318 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// DefaultSeriesDaysAhead is how many days before it starts the next event of
+// a series is created, when SERIES_DAYS_AHEAD is not set.
+const DefaultSeriesDaysAhead = 6
+
+// SeriesTimeLayout is the time of the day the events of a series start.
+const SeriesTimeLayout = "15:04"
+
+// LastWeek is the week of a monthly series on the last weekday of the month.
+const LastWeek = -1
+
+type SeriesFrequency string
+
+const (
+	FrequencyWeekly  SeriesFrequency = "weekly"
+	FrequencyMonthly SeriesFrequency = "monthly"
+)
+
+type SeriesStatus string
+
+const (
+	SeriesActive  SeriesStatus = "active"
+	SeriesPaused  SeriesStatus = "paused"
+	SeriesStopped SeriesStatus = "stopped"
+)
+
+// Series is the template of a recurring event. A weekly series has a
+// Weekday, a monthly one either a Day of the month, or a Weekday and the
+// week of the month in Day, 1 to 4 or LastWeek.
+type Series struct {
+	ID           int64
+	ChatID       int64
+	UserID       int64
+	UserName     string
+	Name         string
+	Frequency    SeriesFrequency
+	Weekday      *time.Weekday
+	Day          int
+	Time         string
+	Location     *string
+	Games        []string
+	Locked       bool
+	Status       SeriesStatus
+	LastStartsAt *time.Time
+}
+
+var ErrInvalidSeriesRule = errors.New("invalid series rule")
+
+var weekOrdinals = map[string]int{
+	"first":  1,
+	"second": 2,
+	"third":  3,
+	"fourth": 4,
+	"last":   LastWeek,
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), s) {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
+
+// ParseSeriesRule reads the rule of a series, one of
+// weekly thursday 20:30, monthly 15 20:30 or monthly first friday 20:30.
+func ParseSeriesRule(s string, series *Series) error {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) < 3 {
+		return ErrInvalidSeriesRule
+	}
+
+	clock := fields[len(fields)-1]
+	if _, err := time.Parse(SeriesTimeLayout, clock); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSeriesRule, err)
+	}
+
+	switch {
+	case fields[0] == string(FrequencyWeekly) && len(fields) == 3:
+		weekday, ok := parseWeekday(fields[1])
+		if !ok {
+			return fmt.Errorf("%w: unknown weekday %s", ErrInvalidSeriesRule, fields[1])
+		}
+
+		series.Frequency = FrequencyWeekly
+		series.Weekday = &weekday
+		series.Day = 0
+	case fields[0] == string(FrequencyMonthly) && len(fields) == 3:
+		day, err := strconv.Atoi(fields[1])
+		if err != nil || day < 1 || day > 31 {
+			return fmt.Errorf("%w: invalid day %s", ErrInvalidSeriesRule, fields[1])
+		}
+
+		series.Frequency = FrequencyMonthly
+		series.Weekday = nil
+		series.Day = day
+	case fields[0] == string(FrequencyMonthly) && len(fields) == 4:
+		week, ok := weekOrdinals[fields[1]]
+		if !ok {
+			return fmt.Errorf("%w: invalid week %s", ErrInvalidSeriesRule, fields[1])
+		}
+
+		weekday, ok := parseWeekday(fields[2])
+		if !ok {
+			return fmt.Errorf("%w: unknown weekday %s", ErrInvalidSeriesRule, fields[2])
+		}
+
+		series.Frequency = FrequencyMonthly
+		series.Weekday = &weekday
+		series.Day = week
+	default:
+		return ErrInvalidSeriesRule
+	}
+
+	series.Time = clock
+
+	return nil
+}
+
+// ParseSeriesArgs reads the arguments of /recurring:
+// name | rule | location | game, game.
+func ParseSeriesArgs(text string) (*Series, error) {
+	parts := strings.Split(text, "|")
+	if len(parts) < 2 {
+		return nil, ErrInvalidSeriesRule
+	}
+
+	series := &Series{
+		Name:   strings.TrimSpace(parts[0]),
+		Status: SeriesActive,
+		Games:  []string{},
+	}
+	series.Locked = strings.Contains(series.Name, "🔒")
+
+	if err := ParseSeriesRule(parts[1], series); err != nil {
+		return nil, err
+	}
+
+	if len(parts) > 2 {
+		if l := strings.TrimSpace(parts[2]); l != "" {
+			series.Location = &l
+		}
+	}
+
+	if len(parts) > 3 {
+		for _, game := range strings.Split(strings.Join(parts[3:], "|"), ",") {
+			if game = strings.TrimSpace(game); game != "" {
+				series.Games = append(series.Games, game)
+			}
+		}
+	}
+
+	return series, nil
+}
+
+// IsActive reports whether the series still creates events.
+func (s Series) IsActive() bool {
+	return s.Status == SeriesActive
+}
+
+// CanEdit reports whether the user can pause, resume or stop the series.
+func (s Series) CanEdit(userID int64) bool {
+	return !s.Locked || s.UserID == userID
+}
+
+// EventStatus is the status of the events created by the series.
+func (s Series) EventStatus() EventStatus {
+	if s.Locked {
+		return StatusLocked
+	}
+
+	return StatusOpen
+}
+
+func (s Series) occurrenceIn(year int, month time.Month) time.Time {
+	clock, _ := time.Parse(SeriesTimeLayout, s.Time)
+	at := func(day int) time.Time {
+		return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	}
+
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local).Day()
+
+	if s.Weekday == nil {
+		return at(min(s.Day, daysInMonth))
+	}
+
+	if s.Day == LastWeek {
+		last := at(daysInMonth)
+		return last.AddDate(0, 0, -int((last.Weekday()-*s.Weekday+7)%7))
+	}
+
+	first := at(1)
+	return first.AddDate(0, 0, int((*s.Weekday-first.Weekday()+7)%7)+7*(s.Day-1))
+}
+
+// Next returns the first start of the series after the given time.
+func (s Series) Next(after time.Time) time.Time {
+	after = after.In(time.Local)
+
+	if s.Frequency == FrequencyWeekly {
+		clock, _ := time.Parse(SeriesTimeLayout, s.Time)
+		next := time.Date(after.Year(), after.Month(), after.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+		next = next.AddDate(0, 0, int((*s.Weekday-next.Weekday()+7)%7))
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+
+		return next
+	}
+
+	year, month := after.Year(), after.Month()
+	for {
+		if next := s.occurrenceIn(year, month); next.After(after) {
+			return next
+		}
+
+		month++
+		if month > time.December {
+			year, month = year+1, time.January
+		}
+	}
+}
+
+// NextStart returns the start of the next event of the series, the events
+// already created are skipped.
+func (s Series) NextStart(now time.Time) time.Time {
+	if s.LastStartsAt != nil && s.LastStartsAt.After(now) {
+		return s.Next(*s.LastStartsAt)
+	}
+
+	return s.Next(now)
+}
+
+// FormatRule describes when the events of the series take place.
+func (s Series) FormatRule(localizer *i18n.Localizer) string {
+	data := map[string]string{
+		"Time": s.Time,
+		"Day":  strconv.Itoa(s.Day),
+	}
+
+	if s.Weekday != nil {
+		data["Weekday"] = localizer.MustLocalizeMessage(&i18n.Message{ID: s.Weekday.String()})
+	}
+
+	messageID := "SeriesWeekly"
+	switch {
+	case s.Frequency == FrequencyMonthly && s.Weekday == nil:
+		messageID = "SeriesMonthlyDay"
+	case s.Frequency == FrequencyMonthly:
+		messageID = "SeriesMonthlyWeekday"
+		ordinalID := "OrdinalLast"
+		if s.Day != LastWeek {
+			ordinalID = fmt.Sprintf("Ordinal%d", s.Day)
+		}
+		data["Ordinal"] = localizer.MustLocalizeMessage(&i18n.Message{ID: ordinalID})
+	}
+
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: messageID,
+		},
+		TemplateData: data,
+	})
+}
+
+// FormatSeriesList renders the series of a chat with their next event.
+func FormatSeriesList(localizer *i18n.Localizer, series []Series, now time.Time) string {
+	if len(series) == 0 {
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "NoSeries"})
+	}
+
+	msg := localizer.MustLocalizeMessage(&i18n.Message{ID: "SeriesListTitle"}) + "\n"
+
+	for _, s := range series {
+		msg += fmt.Sprintf("\n🔁 <code>%d</code> <b>%s</b>\n", s.ID, html.EscapeString(s.Name))
+		msg += "🕗 " + s.FormatRule(localizer) + "\n"
+		if s.Location != nil && *s.Location != "" {
+			msg += "📍 " + html.EscapeString(*s.Location) + "\n"
+		}
+		if len(s.Games) > 0 {
+			msg += "🎲 " + html.EscapeString(strings.Join(s.Games, ", ")) + "\n"
+		}
+
+		if s.IsActive() {
+			msg += localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID: "SeriesNext",
+				},
+				TemplateData: map[string]string{
+					"When": s.NextStart(now).Format(EventTimeLayout),
+				},
+			}) + "\n"
+		} else {
+			msg += "<i>" + localizer.MustLocalizeMessage(&i18n.Message{ID: "SeriesIsPaused"}) + "</i>\n"
+		}
+	}
+
+	return msg
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func localTime(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestParseSeriesRule(t *testing.T) {
+	thursday, friday := time.Thursday, time.Friday
+
+	tests := []struct {
+		rule      string
+		frequency SeriesFrequency
+		weekday   *time.Weekday
+		day       int
+		valid     bool
+	}{
+		{rule: "weekly thursday 20:30", frequency: FrequencyWeekly, weekday: &thursday, valid: true},
+		{rule: "Weekly THU 20:30", frequency: FrequencyWeekly, weekday: &thursday, valid: true},
+		{rule: "monthly 31 20:30", frequency: FrequencyMonthly, day: 31, valid: true},
+		{rule: "monthly first friday 20:30", frequency: FrequencyMonthly, weekday: &friday, day: 1, valid: true},
+		{rule: "monthly last fri 20:30", frequency: FrequencyMonthly, weekday: &friday, day: LastWeek, valid: true},
+		{rule: "weekly th 20:30"},
+		{rule: "weekly funday 20:30"},
+		{rule: "weekly thursday 25:00"},
+		{rule: "monthly 0 20:30"},
+		{rule: "monthly 32 20:30"},
+		{rule: "monthly fifth friday 20:30"},
+		{rule: "daily 20:30 x"},
+		{rule: "weekly 20:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			var series Series
+			err := ParseSeriesRule(tt.rule, &series)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidSeriesRule) {
+					t.Fatalf("expected ErrInvalidSeriesRule, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if series.Frequency != tt.frequency || series.Day != tt.day || series.Time != "20:30" {
+				t.Errorf("unexpected series %+v", series)
+			}
+
+			if (series.Weekday == nil) != (tt.weekday == nil) || (series.Weekday != nil && *series.Weekday != *tt.weekday) {
+				t.Errorf("expected weekday %v, got %v", tt.weekday, series.Weekday)
+			}
+		})
+	}
+}
+
+func TestSeriesNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		after  time.Time
+		expect time.Time
+	}{
+		{
+			name:   "weekly later the same day",
+			rule:   "weekly thursday 20:30",
+			after:  localTime(2024, time.December, 26, 19, 0),
+			expect: localTime(2024, time.December, 26, 20, 30),
+		},
+		{
+			name:   "weekly already started today, into the next year",
+			rule:   "weekly thursday 20:30",
+			after:  localTime(2024, time.December, 26, 21, 0),
+			expect: localTime(2025, time.January, 2, 20, 30),
+		},
+		{
+			name:   "weekly right at the start",
+			rule:   "weekly thursday 20:30",
+			after:  localTime(2024, time.December, 26, 20, 30),
+			expect: localTime(2025, time.January, 2, 20, 30),
+		},
+		{
+			name:   "weekly into the next month",
+			rule:   "weekly friday 20:30",
+			after:  localTime(2024, time.January, 31, 10, 0),
+			expect: localTime(2024, time.February, 2, 20, 30),
+		},
+		{
+			name:   "day 31 in a leap February",
+			rule:   "monthly 31 20:30",
+			after:  localTime(2024, time.January, 31, 21, 0),
+			expect: localTime(2024, time.February, 29, 20, 30),
+		},
+		{
+			name:   "day 31 back in a long month",
+			rule:   "monthly 31 20:30",
+			after:  localTime(2024, time.February, 29, 21, 0),
+			expect: localTime(2024, time.March, 31, 20, 30),
+		},
+		{
+			name:   "day 31 in a 30 days month",
+			rule:   "monthly 31 20:30",
+			after:  localTime(2024, time.April, 1, 0, 0),
+			expect: localTime(2024, time.April, 30, 20, 30),
+		},
+		{
+			name:   "day of the month later the same day",
+			rule:   "monthly 15 20:30",
+			after:  localTime(2024, time.December, 15, 20, 0),
+			expect: localTime(2024, time.December, 15, 20, 30),
+		},
+		{
+			name:   "day of the month already started, into January",
+			rule:   "monthly 15 20:30",
+			after:  localTime(2024, time.December, 15, 21, 0),
+			expect: localTime(2025, time.January, 15, 20, 30),
+		},
+		{
+			name:   "last friday before the end of the month",
+			rule:   "monthly last friday 20:30",
+			after:  localTime(2024, time.February, 1, 0, 0),
+			expect: localTime(2024, time.February, 23, 20, 30),
+		},
+		{
+			name:   "last friday on the last day of the month",
+			rule:   "monthly last friday 20:30",
+			after:  localTime(2024, time.May, 1, 0, 0),
+			expect: localTime(2024, time.May, 31, 20, 30),
+		},
+		{
+			name:   "last thursday already started, into January",
+			rule:   "monthly last thursday 20:30",
+			after:  localTime(2024, time.December, 26, 21, 0),
+			expect: localTime(2025, time.January, 30, 20, 30),
+		},
+		{
+			name:   "first thursday",
+			rule:   "monthly first thursday 20:30",
+			after:  localTime(2024, time.December, 1, 0, 0),
+			expect: localTime(2024, time.December, 5, 20, 30),
+		},
+		{
+			name:   "first thursday on the first day, into January",
+			rule:   "monthly first thursday 20:30",
+			after:  localTime(2024, time.December, 5, 21, 0),
+			expect: localTime(2025, time.January, 2, 20, 30),
+		},
+		{
+			name:   "fourth thursday",
+			rule:   "monthly fourth thursday 20:30",
+			after:  localTime(2024, time.November, 1, 0, 0),
+			expect: localTime(2024, time.November, 28, 20, 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var series Series
+			if err := ParseSeriesRule(tt.rule, &series); err != nil {
+				t.Fatalf("ParseSeriesRule: %v", err)
+			}
+
+			if got := series.Next(tt.after); !got.Equal(tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestSeriesNextStart(t *testing.T) {
+	var series Series
+	if err := ParseSeriesRule("weekly thursday 20:30", &series); err != nil {
+		t.Fatalf("ParseSeriesRule: %v", err)
+	}
+
+	now := localTime(2024, time.December, 20, 12, 0)
+	if got := series.NextStart(now); !got.Equal(localTime(2024, time.December, 26, 20, 30)) {
+		t.Errorf("unexpected first start %v", got)
+	}
+
+	// the event of the 26th has already been created
+	last := localTime(2024, time.December, 26, 20, 30)
+	series.LastStartsAt = &last
+	if got := series.NextStart(now); !got.Equal(localTime(2025, time.January, 2, 20, 30)) {
+		t.Errorf("unexpected next start %v", got)
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+// User is a member of a chat who talked to the bot in private, ChatID is
+// their private chat with the bot. The Notify fields are the changes of their
+// games they are told about in private.
+type User struct {
+	ID               int64  `json:"id"`
+	UserName         string `json:"user_name"`
+	ChatID           *int64 `json:"chat_id"`
+	Reminders        bool   `json:"reminders"`
+	NotifyFull       bool   `json:"notify_full"`
+	NotifyDeleted    bool   `json:"notify_deleted"`
+	NotifyMaxPlayers bool   `json:"notify_max_players"`
+	NotifySeat       bool   `json:"notify_seat"`
+}
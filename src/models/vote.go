@@ -0,0 +1,414 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"gopkg.in/telebot.v3"
+)
+
+type VoteMethod string
+
+const (
+	// VoteApproval lets each player approve any number of games, the most
+	// approved ones win.
+	VoteApproval VoteMethod = "approval"
+	// VoteRanked lets each player rank the games, the winners are elected
+	// one after the other by instant runoff.
+	VoteRanked VoteMethod = "ranked"
+)
+
+type PollStatus string
+
+const (
+	PollOpen   PollStatus = "open"
+	PollClosed PollStatus = "closed"
+)
+
+// Poll is the vote on which games of an event get played, Winners is the
+// number of games kept when the organizer closes it.
+type Poll struct {
+	EventID   string     `json:"event_id"`
+	Method    VoteMethod `json:"method"`
+	Winners   int        `json:"winners"`
+	Status    PollStatus `json:"status"`
+	MessageID *int64     `json:"message_id"`
+	Votes     []Vote     `json:"votes"`
+}
+
+// Vote is a game approved or ranked by a user, Rank starts from 1.
+type Vote struct {
+	UserID      int64  `json:"user_id"`
+	UserName    string `json:"user_name"`
+	BoardGameID int64  `json:"boardgame_id"`
+	Rank        int    `json:"rank"`
+}
+
+// VoteResult is the score of a game, the approvals or the first choices in
+// the round it was elected or eliminated.
+type VoteResult struct {
+	BoardGame BoardGame
+	Score     int
+	Winner    bool
+}
+
+func ParseVoteMethod(s string) (VoteMethod, bool) {
+	switch VoteMethod(strings.ToLower(s)) {
+	case VoteApproval:
+		return VoteApproval, true
+	case VoteRanked:
+		return VoteRanked, true
+	}
+
+	return "", false
+}
+
+// VoteMethodMessageID is the localization key naming the method.
+func VoteMethodMessageID(m VoteMethod) string {
+	if m == VoteRanked {
+		return "VoteMethodRanked"
+	}
+
+	return "VoteMethodApproval"
+}
+
+func (p Poll) IsOpen() bool {
+	return p.Status == PollOpen
+}
+
+// Ballot returns the games voted by the user, in the order of their rank.
+func (p Poll) Ballot(userID int64) []int64 {
+	votes := []Vote{}
+	for _, v := range p.Votes {
+		if v.UserID == userID {
+			votes = append(votes, v)
+		}
+	}
+
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].Rank < votes[j].Rank
+	})
+
+	ballot := make([]int64, len(votes))
+	for i, v := range votes {
+		ballot[i] = v.BoardGameID
+	}
+
+	return ballot
+}
+
+// Voters returns the number of users who voted.
+func (p Poll) Voters() int {
+	voters := map[int64]bool{}
+	for _, v := range p.Votes {
+		voters[v.UserID] = true
+	}
+
+	return len(voters)
+}
+
+// ToggleBallot adds the game at the end of the ballot, or removes it when
+// it was already there.
+func ToggleBallot(ballot []int64, boardGameID int64) []int64 {
+	toggled := []int64{}
+	found := false
+	for _, id := range ballot {
+		if id == boardGameID {
+			found = true
+			continue
+		}
+		toggled = append(toggled, id)
+	}
+
+	if !found {
+		toggled = append(toggled, boardGameID)
+	}
+
+	return toggled
+}
+
+// VotableGames returns the games of the event that can be voted, leaving out
+// the counter of the players.
+func (e Event) VotableGames() []BoardGame {
+	games := []BoardGame{}
+	for _, bg := range e.BoardGames {
+		if bg.IsGame() {
+			games = append(games, bg)
+		}
+	}
+
+	return games
+}
+
+// DefaultWinners is the number of games needed to seat every player of the
+// event, the biggest games first.
+func (e Event) DefaultWinners() int {
+	players := map[int64]bool{}
+	for _, bg := range e.BoardGames {
+		for _, p := range bg.Participants {
+			players[p.UserID] = true
+		}
+		for _, p := range bg.Waitlist {
+			players[p.UserID] = true
+		}
+	}
+
+	games := e.VotableGames()
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].MaxPlayers > games[j].MaxPlayers
+	})
+
+	seats, winners := int64(0), 0
+	for _, bg := range games {
+		if winners > 0 && seats >= int64(len(players)) {
+			break
+		}
+		seats += bg.MaxPlayers
+		winners++
+	}
+
+	return max(winners, 1)
+}
+
+// Tally counts the votes on the games, returning them from the first to the
+// last place with the winners marked. Ties are broken by the number of
+// players already seated, then by name.
+func (p Poll) Tally(games []BoardGame) []VoteResult {
+	ballots := map[int64][]int64{}
+	valid := map[int64]bool{}
+	for _, bg := range games {
+		valid[bg.ID] = true
+	}
+	for _, v := range p.Votes {
+		if valid[v.BoardGameID] {
+			ballots[v.UserID] = nil
+		}
+	}
+	for userID := range ballots {
+		for _, id := range p.Ballot(userID) {
+			if valid[id] {
+				ballots[userID] = append(ballots[userID], id)
+			}
+		}
+	}
+
+	// less orders two games by the tie breakers
+	byID := map[int64]BoardGame{}
+	for _, bg := range games {
+		byID[bg.ID] = bg
+	}
+	less := func(a, b int64) bool {
+		ga, gb := byID[a], byID[b]
+		if len(ga.Participants) != len(gb.Participants) {
+			return len(ga.Participants) > len(gb.Participants)
+		}
+		if ga.Name != gb.Name {
+			return ga.Name < gb.Name
+		}
+		return a < b
+	}
+
+	var order []int64
+	var scores map[int64]int
+	if p.Method == VoteRanked {
+		order, scores = rankedOrder(games, ballots, less)
+	} else {
+		order, scores = approvalOrder(games, ballots, less)
+	}
+
+	results := make([]VoteResult, len(order))
+	for i, id := range order {
+		results[i] = VoteResult{BoardGame: byID[id], Score: scores[id], Winner: i < p.Winners}
+	}
+
+	return results
+}
+
+func approvalOrder(games []BoardGame, ballots map[int64][]int64, less func(a, b int64) bool) ([]int64, map[int64]int) {
+	scores := map[int64]int{}
+	order := []int64{}
+	for _, bg := range games {
+		order = append(order, bg.ID)
+	}
+
+	for _, ballot := range ballots {
+		for _, id := range ballot {
+			scores[id]++
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if scores[order[i]] != scores[order[j]] {
+			return scores[order[i]] > scores[order[j]]
+		}
+		return less(order[i], order[j])
+	})
+
+	return order, scores
+}
+
+// rankedOrder elects the games one at a time by instant runoff: the game
+// with the fewest first choices is eliminated until one has a majority,
+// then the election is repeated without the elected games.
+func rankedOrder(games []BoardGame, ballots map[int64][]int64, less func(a, b int64) bool) ([]int64, map[int64]int) {
+	scores := map[int64]int{}
+	remaining := map[int64]bool{}
+	for _, bg := range games {
+		remaining[bg.ID] = true
+	}
+
+	order := []int64{}
+	for len(remaining) > 0 {
+		running := map[int64]bool{}
+		for id := range remaining {
+			running[id] = true
+		}
+
+		for {
+			counts := map[int64]int{}
+			total := 0
+			for _, ballot := range ballots {
+				for _, id := range ballot {
+					if running[id] {
+						counts[id]++
+						total++
+						break
+					}
+				}
+			}
+
+			ids := []int64{}
+			for id := range running {
+				ids = append(ids, id)
+			}
+			sort.Slice(ids, func(i, j int) bool {
+				if counts[ids[i]] != counts[ids[j]] {
+					return counts[ids[i]] > counts[ids[j]]
+				}
+				return less(ids[i], ids[j])
+			})
+
+			if len(ids) == 1 || counts[ids[0]]*2 > total {
+				scores[ids[0]] = counts[ids[0]]
+				order = append(order, ids[0])
+				delete(remaining, ids[0])
+				break
+			}
+
+			last := ids[len(ids)-1]
+			scores[last] = counts[last]
+			delete(running, last)
+		}
+	}
+
+	return order, scores
+}
+
+// FormatPoll renders the vote with the current results and the buttons to
+// vote and to close it.
+func (e Event) FormatPoll(localizer *i18n.Localizer, poll Poll) (string, *telebot.ReplyMarkup) {
+	methodID := "VoteApproval"
+	if poll.Method == VoteRanked {
+		methodID = "VoteRanked"
+	}
+
+	msg := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "VoteTitle",
+		},
+		TemplateData: map[string]string{
+			"Name": html.EscapeString(e.Name),
+		},
+	}) + "\n"
+	msg += "<i>" + localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: methodID,
+		},
+		TemplateData: map[string]string{
+			"Winners": strconv.Itoa(poll.Winners),
+		},
+	}) + "</i>\n\n"
+
+	for i, r := range poll.Tally(e.VotableGames()) {
+		medal := "▫️"
+		if r.Winner {
+			medal = "🏆"
+		}
+		msg += fmt.Sprintf("%s %d. <b>%s</b> · %d\n", medal, i+1, html.EscapeString(r.BoardGame.Name), r.Score)
+	}
+
+	msg += "\n" + localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "VoteVoters",
+		},
+		TemplateData: map[string]string{
+			"Count": strconv.Itoa(poll.Voters()),
+		},
+	})
+
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	if !poll.IsOpen() {
+		msg += "\n<b>" + localizer.MustLocalizeMessage(&i18n.Message{ID: "VoteClosed"}) + "</b>"
+		return msg, markup
+	}
+
+	for _, bg := range e.VotableGames() {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   "🗳 " + bg.Name,
+			Unique: string(CastVote),
+			Data:   fmt.Sprintf("%s|%d", e.ID, bg.ID),
+		}})
+	}
+
+	markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{
+		{
+			Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "ClearVote"}),
+			Unique: string(CastVote),
+			Data:   fmt.Sprintf("%s|0", e.ID),
+		},
+		{
+			Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "CloseVote"}),
+			Unique: string(CloseVote),
+			Data:   e.ID,
+		},
+	})
+
+	return msg, markup
+}
+
+// FormatBallot describes the games voted by a user, in a short plain text
+// fit for a callback answer.
+func (e Event) FormatBallot(localizer *i18n.Localizer, poll Poll, userID int64) string {
+	names := []string{}
+	for i, id := range poll.Ballot(userID) {
+		bg := e.BoardGame(id)
+		if bg == nil {
+			continue
+		}
+
+		if poll.Method == VoteRanked {
+			names = append(names, fmt.Sprintf("%d. %s", i+1, bg.Name))
+		} else {
+			names = append(names, bg.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return localizer.MustLocalizeMessage(&i18n.Message{ID: "NoVote"})
+	}
+
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "YourVote",
+		},
+		TemplateData: map[string]string{
+			"Games": strings.Join(names, ", "),
+		},
+	})
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"testing"
+)
+
+// ballots builds the votes of a poll, each ballot listing the games of a
+// user in the order of their rank.
+func ballots(b map[int64][]int64) []Vote {
+	votes := []Vote{}
+	for userID, ids := range b {
+		for i, id := range ids {
+			votes = append(votes, Vote{UserID: userID, BoardGameID: id, Rank: i + 1})
+		}
+	}
+
+	return votes
+}
+
+func seated(n int) []Participant {
+	participants := []Participant{}
+	for i := 0; i < n; i++ {
+		participants = append(participants, Participant{UserID: int64(100 + i)})
+	}
+
+	return participants
+}
+
+func TestTally(t *testing.T) {
+	azul := BoardGame{ID: 1, Name: "Azul", MaxPlayers: 4}
+	brass := BoardGame{ID: 2, Name: "Brass", MaxPlayers: 4}
+	catan := BoardGame{ID: 3, Name: "Catan", MaxPlayers: 4}
+	dune := BoardGame{ID: 4, Name: "Dune", MaxPlayers: 4}
+	popularCatan := catan
+	popularCatan.Participants = seated(2)
+
+	tests := []struct {
+		name    string
+		method  VoteMethod
+		winners int
+		games   []BoardGame
+		votes   map[int64][]int64
+		order   []int64
+		scores  []int
+		won     int
+	}{
+		{
+			name:    "approval counts every approved game",
+			method:  VoteApproval,
+			winners: 2,
+			games:   []BoardGame{azul, brass, catan},
+			votes:   map[int64][]int64{1: {1, 3}, 2: {3}, 3: {2, 3}},
+			order:   []int64{3, 1, 2},
+			scores:  []int{3, 1, 1},
+			won:     2,
+		},
+		{
+			name:    "approval ties go to the game with more players, then by name",
+			method:  VoteApproval,
+			winners: 1,
+			games:   []BoardGame{azul, brass, popularCatan},
+			votes:   map[int64][]int64{1: {1}, 2: {2}, 3: {3}},
+			order:   []int64{3, 1, 2},
+			scores:  []int{1, 1, 1},
+			won:     1,
+		},
+		{
+			name:    "approval ignores the games no longer in the event",
+			method:  VoteApproval,
+			winners: 1,
+			games:   []BoardGame{azul, brass},
+			votes:   map[int64][]int64{1: {3, 2}, 2: {3}},
+			order:   []int64{2, 1},
+			scores:  []int{1, 0},
+			won:     1,
+		},
+		{
+			name:    "ranked elects the majority of first choices",
+			method:  VoteRanked,
+			winners: 1,
+			games:   []BoardGame{azul, brass, catan},
+			votes:   map[int64][]int64{1: {1, 2}, 2: {1, 3}, 3: {2}},
+			order:   []int64{1, 2, 3},
+			scores:  []int{2, 2, 1},
+			won:     1,
+		},
+		{
+			name:    "ranked transfers the votes of the eliminated game",
+			method:  VoteRanked,
+			winners: 2,
+			games:   []BoardGame{azul, brass, catan},
+			// Brass is eliminated first, its voter moves to Azul
+			votes:  map[int64][]int64{1: {1}, 2: {1}, 3: {2, 1}, 4: {3}, 5: {3}},
+			order:  []int64{1, 3, 2},
+			scores: []int{3, 2, 1},
+			won:    2,
+		},
+		{
+			name:    "ranked leaves the exhausted ballots out of the majority",
+			method:  VoteRanked,
+			winners: 2,
+			games:   []BoardGame{azul, brass, catan, dune},
+			// once Azul is elected the six ballots listing only Azul are
+			// exhausted, so Brass has the majority of the remaining ones
+			// before Dune is eliminated and passes its vote on
+			votes: map[int64][]int64{
+				1: {1}, 2: {1}, 3: {1}, 4: {1}, 5: {1}, 6: {1},
+				7: {2}, 8: {2}, 9: {2},
+				10: {3},
+				11: {4, 2},
+			},
+			order:  []int64{1, 2, 3, 4},
+			scores: []int{6, 3, 1, 1},
+			won:    2,
+		},
+		{
+			name:    "ranked eliminates the last by name on a tie",
+			method:  VoteRanked,
+			winners: 1,
+			games:   []BoardGame{azul, brass, catan},
+			votes:   map[int64][]int64{1: {1}, 2: {2}, 3: {3, 1}},
+			order:   []int64{1, 2, 3},
+			scores:  []int{2, 1, 1},
+			won:     1,
+		},
+		{
+			name:    "ranked keeps the game with more players on a tie",
+			method:  VoteRanked,
+			winners: 1,
+			games:   []BoardGame{azul, brass, popularCatan},
+			// Brass is eliminated and its only voter is exhausted, then the
+			// tie between Azul and Catan goes to Catan
+			votes:  map[int64][]int64{1: {1}, 2: {2}, 3: {3, 1}},
+			order:  []int64{3, 1, 2},
+			scores: []int{1, 2, 1},
+			won:    1,
+		},
+		{
+			name:    "ranked without votes orders by the tie breakers",
+			method:  VoteRanked,
+			winners: 1,
+			games:   []BoardGame{azul, brass, popularCatan},
+			votes:   map[int64][]int64{},
+			order:   []int64{3, 1, 2},
+			scores:  []int{0, 0, 0},
+			won:     1,
+		},
+		{
+			name:    "every game wins when the winners are as many as the games",
+			method:  VoteRanked,
+			winners: 5,
+			games:   []BoardGame{azul, brass, catan},
+			votes:   map[int64][]int64{1: {2, 1}},
+			order:   []int64{2, 1, 3},
+			scores:  []int{1, 1, 0},
+			won:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := Poll{Method: tt.method, Winners: tt.winners, Status: PollOpen, Votes: ballots(tt.votes)}
+
+			results := poll.Tally(tt.games)
+			if len(results) != len(tt.order) {
+				t.Fatalf("expected %d results, got %d", len(tt.order), len(results))
+			}
+
+			won := 0
+			for i, r := range results {
+				if r.BoardGame.ID != tt.order[i] || r.Score != tt.scores[i] {
+					t.Errorf("place %d: expected game %d with %d, got game %d with %d", i+1, tt.order[i], tt.scores[i], r.BoardGame.ID, r.Score)
+				}
+				if r.Winner {
+					won++
+					if i >= tt.winners {
+						t.Errorf("place %d should not win", i+1)
+					}
+				}
+			}
+
+			if won != tt.won {
+				t.Errorf("expected %d winners, got %d", tt.won, won)
+			}
+		})
+	}
+}
+
+func TestDefaultWinners(t *testing.T) {
+	counter := BoardGame{ID: 9, Name: PLAYER_COUNTER, MaxPlayers: -1, Participants: seated(1)}
+
+	tests := []struct {
+		name   string
+		games  []BoardGame
+		expect int
+	}{
+		{
+			name:   "no players still keeps a game",
+			games:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 4}, {ID: 2, Name: "Brass", MaxPlayers: 4}},
+			expect: 1,
+		},
+		{
+			name:   "the biggest game seats everyone",
+			games:  []BoardGame{{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: seated(2)}, {ID: 2, Name: "Brass", MaxPlayers: 5}},
+			expect: 1,
+		},
+		{
+			name: "the biggest games first until everyone is seated",
+			games: []BoardGame{
+				{ID: 1, Name: "Azul", MaxPlayers: 2, Participants: seated(2)},
+				{ID: 2, Name: "Brass", MaxPlayers: 4, Participants: seated(4)[2:], Waitlist: []Participant{{UserID: 200}, {UserID: 201}, {UserID: 202}}},
+				{ID: 3, Name: "Catan", MaxPlayers: 3},
+			},
+			expect: 2,
+		},
+		{
+			name:   "more players than seats keeps every game",
+			games:  []BoardGame{counter, {ID: 1, Name: "Azul", MaxPlayers: 2, Participants: seated(2), Waitlist: []Participant{{UserID: 200}, {UserID: 201}, {UserID: 202}}}, {ID: 2, Name: "Brass", MaxPlayers: 2}},
+			expect: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{BoardGames: tt.games}
+			if got := event.DefaultWinners(); got != tt.expect {
+				t.Errorf("expected %d winners, got %d", tt.expect, got)
+			}
+		})
+	}
+}
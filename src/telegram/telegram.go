@@ -7,10 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"log"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fzerorubigd/gobgg"
 	_ "github.com/mattn/go-sqlite3"
@@ -19,13 +20,17 @@ import (
 )
 
 type Telegram struct {
-	Bot            *telebot.Bot
-	DB             *database.Database
-	BGG            *gobgg.BGG
-	LanguageBundle *i18n.Bundle
-	LanguagePack   *language.LanguagePack
-	BaseUrl        string
-	BotName        string
+	Bot             *telebot.Bot
+	DB              database.Store
+	BGG             *gobgg.BGG
+	BGGCache        *models.BGGCache
+	Admins          *models.AdminCache
+	LanguageBundle  *i18n.Bundle
+	LanguagePack    *language.LanguagePack
+	BaseUrl         string
+	BotName         string
+	SeriesDaysAhead int
+	Edits           *models.EditQueue
 }
 
 func DefineUsername(user *telebot.User) string {
@@ -42,7 +47,12 @@ func DefineUsername(user *telebot.User) string {
 }
 
 func (t Telegram) Localizer(c telebot.Context) *i18n.Localizer {
-	return i18n.NewLocalizer(t.LanguageBundle, t.DB.GetPreferredLanguage(c.Chat().ID), "en")
+	return t.ChatLocalizer(c.Chat().ID)
+}
+
+// ChatLocalizer is used for the messages sent without an update to reply to.
+func (t Telegram) ChatLocalizer(chatID int64) *i18n.Localizer {
+	return i18n.NewLocalizer(t.LanguageBundle, t.DB.GetPreferredLanguage(chatID), "en")
 }
 
 func (t Telegram) Start(c telebot.Context) error {
@@ -54,9 +64,15 @@ func (t Telegram) Start(c telebot.Context) error {
 			DefaultMessage: &i18n.Message{
 				ID: "Welcome",
 			},
-			TemplateData: map[string]string{},
+			TemplateData: map[string]string{
+				"BotName": t.BotName,
+			},
 		})
 
+		if c.Chat().Type == telebot.ChatPrivate && strings.HasPrefix(c.Text(), "/start") {
+			welcomeT += "\n\n" + t.startNotifications(c)
+		}
+
 		return c.Send(welcomeT)
 	}
 
@@ -92,39 +108,57 @@ func (t Telegram) Start(c telebot.Context) error {
 	return c.Send(openT, markup)
 }
 
+func (t Telegram) createUsage(c telebot.Context) string {
+	eventNameT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventName"}})
+	eventLocationT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocation"}})
+
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "Usage",
+		},
+		TemplateData: map[string]string{
+			"Command": "/create",
+			"Example": fmt.Sprintf("%s | %s | %s", eventNameT, models.EventTimeLayout, eventLocationT),
+		},
+	})
+}
+
 func (t Telegram) CreateGame(c telebot.Context) error {
 	var err error
 	args := c.Args()
 	if len(args) < 1 {
-		eventNameT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventName"}})
-		usageT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
-			DefaultMessage: &i18n.Message{
-				ID: "Usage",
-			},
-			TemplateData: map[string]string{
-				"Command": "/create",
-				"Example": eventNameT,
-			},
-		})
-		return c.Reply(usageT)
+		return c.Reply(t.createUsage(c))
+	}
+
+	eventName, startsAt, endsAt, location, err := models.ParseCreateArgs(strings.Join(args[0:], " "))
+	if err != nil || eventName == "" {
+		log.Println("invalid event arguments:", err)
+		invalidT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidEventDate"}})
+		return c.Reply(invalidT + "\n" + t.createUsage(c))
 	}
-	eventName := strings.Join(args[0:], " ")
+
 	userID := c.Sender().ID
 	userName := DefineUsername(c.Sender())
 	chatID := c.Chat().ID
 
+	status := models.StatusOpen
+	if strings.Contains(eventName, "🔒") {
+		status = models.StatusLocked
+	}
+
 	var eventID string
 	log.Printf("Creating event: %s by user: %s (%d) in chat: %d", eventName, userName, userID, chatID)
 
-	if eventID, err = t.DB.InsertEvent(chatID, userID, userName, eventName, nil); err != nil {
+	if eventID, err = t.DB.InsertEvent(chatID, userID, userName, eventName, nil, startsAt, endsAt, location, status); err != nil {
 		log.Println("failed to create event:", err)
 		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToCreateEvent"}})
 		return c.Reply(failedT)
 	}
 	log.Printf("Event created with id: %s", eventID)
+	t.audit(c, eventID, models.AuditEventCreated, "", "", eventName)
 
 	if strings.Contains(eventName, "👥") {
-		if _, err = t.DB.InsertBoardGame(eventID, models.PLAYER_COUNTER, -1, nil, nil, nil, nil, nil); err != nil {
+		if _, err = t.DB.InsertBoardGame(eventID, models.PLAYER_COUNTER, -1, nil, nil, nil, nil, nil, &userName); err != nil {
 			log.Println("failed to add game:", err)
 			failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddGame"}})
 			return c.Reply(failedT)
@@ -158,10 +192,9 @@ func (t Telegram) CreateGame(c telebot.Context) error {
 }
 
 func (t Telegram) AddGame(c telebot.Context) error {
-	var err error
 	log.Println("user requested to add a game")
 
-	args := c.Args()
+	code, args := splitEventCode(c.Args())
 	if len(args) < 1 {
 		gameNameT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameName"}})
 		usageT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
@@ -176,91 +209,78 @@ func (t Telegram) AddGame(c telebot.Context) error {
 		return c.Reply(usageT)
 	}
 
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.addGame(c, event, args)
+}
+
+func (t Telegram) addGame(c telebot.Context, event *models.Event, args []string) error {
 	chatID := c.Chat().ID
 	userID := c.Sender().ID
-	userName := DefineUsername(c.Sender())
 	gameName := strings.Join(args[0:], " ")
 	maxPlayers := 5
-	log.Printf("Adding game: %s in chat id %d with max players: %d", gameName, chatID, maxPlayers)
-
-	var event *models.Event
-	var boardGameID int64
+	log.Printf("Adding game: %s in event %s of chat id %d with max players: %d", gameName, event.ID, chatID, maxPlayers)
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
-		log.Println("failed to add game:", err)
-		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddGame"}})
-		return c.Reply(failedT)
+	if !event.IsActive() {
+		log.Println("event is closed")
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}}))
 	}
 
-	if event.Locked && event.UserID != userID {
+	if !t.canEdit(event, userID) {
 		log.Println("event is locked")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocked"}}))
 	}
 
 	ctx := context.Background()
-	var results []gobgg.SearchResult
-	var bgUrl, bgName, bggImageUrl *string
-	var bgID *int64
-
-	if results, err = t.BGG.Search(ctx, gameName); err != nil {
-		log.Printf("Failed to search game %s: %v", gameName, err)
+	matches, match, ok := t.gameMatches(ctx, chatID, gameName)
+	if !ok && len(matches) > 0 {
+		return t.sendBGGPicker(c, event, gameName, matches)
 	}
 
-	if len(results) == 0 {
-		log.Printf("Game %s not found", gameName)
-	} else {
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].ID < results[j].ID
-		})
-
-		url := fmt.Sprintf("https://boardgamegeek.com/boardgame/%d", results[0].ID)
-		bgUrl = &url
-		if results[0].Name != "" {
-			bgName = &results[0].Name
-		}
-
-		bgID = &results[0].ID
-
-		log.Printf("Game %s id %d found: %s", gameName, *bgID, *bgUrl)
+	var bgID *int64
+	var bgName, bgUrl, bggImageUrl *string
+	if ok {
+		maxPlayers, bgID, bgName, bgUrl, bggImageUrl = t.matchInfo(ctx, match, maxPlayers)
+	}
 
-		var things []gobgg.ThingResult
+	return t.insertGame(c, event, c.Message(), gameName, maxPlayers, bgID, bgName, bgUrl, bggImageUrl)
+}
 
-		if things, err = t.BGG.GetThings(ctx, gobgg.GetThingIDs(*bgID)); err != nil {
-			log.Printf("Failed to get game %s: %v", gameName, err)
-		}
+// insertGame adds the game to the event with the sender seated at it, then
+// replies to the given message with the message of the game.
+func (t Telegram) insertGame(c telebot.Context, event *models.Event, replyTo *telebot.Message, gameName string, maxPlayers int, bgID *int64, bgName, bgUrl, bggImageUrl *string) error {
+	var err error
+	userID := c.Sender().ID
+	userName := DefineUsername(c.Sender())
 
-		if len(things) > 0 {
-			maxPlayers = things[0].MaxPlayers
-			if things[0].Name != "" {
-				bgName = &things[0].Name
-			} else {
-				bgName = &gameName
-			}
-			if things[0].Image != "" {
-				bggImageUrl = &things[0].Image
-				log.Println("image URL:", *bggImageUrl)
-			}
-		}
-	}
+	var boardGameID int64
 
-	if boardGameID, err = t.DB.InsertBoardGame(event.ID, gameName, maxPlayers, bgID, bgName, bgUrl, bggImageUrl, event.user_name); err != nil {
+	if boardGameID, err = t.DB.InsertBoardGame(event.ID, gameName, maxPlayers, bgID, bgName, bgUrl, bggImageUrl, &userID, &userName); err != nil {
 		log.Println("failed to add game:", err)
 		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddGame"}})
 		return c.Reply(failedT)
 	}
 
+	t.audit(c, event.ID, models.AuditGameAdded, gameName, "", strconv.Itoa(maxPlayers))
+
 	if _, err = t.DB.InsertParticipant(event.ID, boardGameID, userID, userName); err != nil {
 		log.Println("failed to add user to participants table:", err)
 		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddGame"}})
 		return c.Reply(failedT)
 	}
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
+	before := event
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
 		log.Println("failed to add game:", err)
 		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddGame"}})
 		return c.Reply(failedT)
 	}
 
+	t.auditJoin(c, before, event, boardGameID)
+
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
@@ -268,21 +288,7 @@ func (t Telegram) AddGame(c telebot.Context) error {
 
 	log.Printf("event message id: %d", *event.MessageID)
 
-	body, markup := event.FormatMsg(t.Localizer(c), t.BaseUrl, t.BotName)
-
-	_, err = t.Bot.Edit(&telebot.Message{
-		ID:   int(*event.MessageID),
-		Chat: c.Chat(),
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			return nil
-		}
-
-		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateMessageEvent"}})
-		return c.Reply(failedT)
-	}
+	t.refreshEventMessage(event)
 
 	link := ""
 	if bgUrl != nil && bgName != nil {
@@ -301,7 +307,7 @@ func (t Telegram) AddGame(c telebot.Context) error {
 	})
 
 	responseMsg, err := t.Bot.Reply(
-		c.Message(),
+		replyTo,
 		message,
 		telebot.NoPreview,
 	)
@@ -320,6 +326,79 @@ func (t Telegram) AddGame(c telebot.Context) error {
 	return nil
 }
 
+// searchGame looks the game up on BoardGameGeek, taking the best match. The
+// default number of players is kept when the game is not found.
+func (t Telegram) searchGame(ctx context.Context, gameName string, maxPlayers int) (int, *int64, *string, *string, *string) {
+	matches := t.searchMatches(ctx, gameName)
+	if len(matches) == 0 {
+		return maxPlayers, nil, nil, nil, nil
+	}
+
+	return t.matchInfo(ctx, matches[0], maxPlayers)
+}
+
+// searchMatches returns the games of BoardGameGeek matching the name, the
+// most plausible first.
+func (t Telegram) searchMatches(ctx context.Context, gameName string) []models.BGGMatch {
+	results, err := t.BGGCache.Search(ctx, gameName)
+	if err != nil {
+		log.Printf("Failed to search game %s: %v", gameName, err)
+	}
+
+	matches := models.RankBGGResults(gameName, results)
+	if len(matches) == 0 {
+		log.Printf("Game %s not found", gameName)
+	}
+
+	return matches
+}
+
+// gameMatches returns the games matching the name, the ones owned by the
+// users of the chat first, and the game to add without asking, if any.
+func (t Telegram) gameMatches(ctx context.Context, chatID int64, gameName string) ([]models.BGGMatch, models.BGGMatch, bool) {
+	games, err := t.DB.SelectChatCollection(chatID)
+	if err != nil {
+		log.Println("failed to load collections:", err)
+	}
+
+	owned := models.MatchCollection(gameName, games)
+	if match, ok := models.PickCollectionMatch(owned); ok {
+		return owned, match, true
+	}
+
+	found := t.searchMatches(ctx, gameName)
+	if len(owned) == 0 {
+		match, ok := models.PickBGGMatch(found)
+		return found, match, ok
+	}
+
+	return models.MergeMatches(owned, found), models.BGGMatch{}, false
+}
+
+// matchInfo returns the details of the game, the default number of players
+// is kept when BoardGameGeek does not have it.
+func (t Telegram) matchInfo(ctx context.Context, match models.BGGMatch, maxPlayers int) (int, *int64, *string, *string, *string) {
+	bgID := match.ID
+	url := models.BGGUrl(match.ID)
+	bgName := &match.Name
+
+	log.Printf("Game %s id %d found: %s", match.Name, bgID, url)
+
+	bgMaxPlayers, name, _, bggImageUrl, err := t.BGGCache.GameInfo(ctx, bgID, match.Name)
+	if err != nil {
+		return maxPlayers, &bgID, bgName, &url, nil
+	}
+
+	if bgMaxPlayers != nil && *bgMaxPlayers > 0 {
+		maxPlayers = *bgMaxPlayers
+	}
+	if name != nil {
+		bgName = name
+	}
+
+	return maxPlayers, &bgID, bgName, &url, bggImageUrl
+}
+
 func (t Telegram) UpdateGameDispatcher(c telebot.Context) error {
 	if c.Message().ReplyTo == nil {
 		return nil
@@ -339,7 +418,7 @@ func (t Telegram) UpdateGameNumberOfPlayer(c telebot.Context) error {
 	maxPlayerS := c.Text()
 
 	maxPlayers, err2 := strconv.ParseInt(maxPlayerS, 10, 64)
-	if exists := t.DB.HasBoardGameWithMessageID(int64(messageID)); !exists {
+	if exists := t.DB.HasBoardGameWithMessageID(chatID, int64(messageID)); !exists {
 		if err2 == nil {
 			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 		} else {
@@ -347,7 +426,7 @@ func (t Telegram) UpdateGameNumberOfPlayer(c telebot.Context) error {
 		}
 	}
 
-	if err2 != nil {
+	if err2 != nil || maxPlayers < 1 {
 		invalidT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidNumberOfPlayers"}})
 
 		return c.Reply(invalidT)
@@ -355,7 +434,17 @@ func (t Telegram) UpdateGameNumberOfPlayer(c telebot.Context) error {
 
 	log.Printf("Updating game message id: %d with number of players: %d", messageID, maxPlayers)
 
-	if err = t.DB.UpdateBoardGamePlayerNumber(int64(messageID), int(maxPlayers)); err != nil {
+	var before *models.Event
+	if before, err = t.DB.SelectEventByBoardGameMessageID(chatID, int64(messageID)); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
+	}
+
+	if denied := t.editDenied(c, before); denied != "" {
+		return c.Reply(denied)
+	}
+
+	if err = t.DB.UpdateBoardGamePlayerNumber(chatID, int64(messageID), int(maxPlayers)); err != nil {
 		if errors.Is(err, database.ErrNoRows) {
 			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 		}
@@ -365,36 +454,28 @@ func (t Telegram) UpdateGameNumberOfPlayer(c telebot.Context) error {
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
 	}
 
+	if bg := before.BoardGameByMessageID(int64(messageID)); bg != nil {
+		t.audit(c, before.ID, models.AuditMaxPlayersChanged, bg.Name, strconv.FormatInt(bg.MaxPlayers, 10), strconv.FormatInt(maxPlayers, 10))
+	}
+
 	var event *models.Event
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
+	if event, err = t.DB.SelectEventByBoardGameMessageID(chatID, int64(messageID)); err != nil {
 		log.Println("failed to add game:", err)
 		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}})
 
 		return c.Reply(failedT)
 	}
 
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 	}
 
-	body, markup := event.FormatMsg(t.Localizer(c), t.BaseUrl, t.BotName)
-
-	_, err = t.Bot.Edit(&telebot.Message{
-		ID:   int(*event.MessageID),
-		Chat: c.Chat(),
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			return nil
-		}
-
-		failedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateMessageEvent"}})
-
-		return c.Reply(failedT)
-	}
+	t.refreshEventMessage(event)
 
 	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameUpdated"}}))
 }
@@ -411,6 +492,16 @@ func (t Telegram) UpdateGameBGGInfo(c telebot.Context) error {
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidBggURL"}}))
 	}
 
+	var before *models.Event
+	if before, err = t.DB.SelectEventByBoardGameMessageID(chatID, int64(messageID)); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
+	}
+
+	if denied := t.editDenied(c, before); denied != "" {
+		return c.Reply(denied)
+	}
+
 	ctx := context.Background()
 	var maxPlayers *int
 	var bgName, bgUrl, bgImageUrl *string
@@ -422,7 +513,7 @@ func (t Telegram) UpdateGameBGGInfo(c telebot.Context) error {
 
 	log.Printf("Updating game message id: %d with number of players: %d", messageID, maxPlayers)
 
-	if err = t.DB.UpdateBoardGameBGGInfo(int64(messageID), *maxPlayers, &id, bgName, bgUrl, bgImageUrl); err != nil {
+	if err = t.DB.UpdateBoardGameBGGInfo(chatID, int64(messageID), *maxPlayers, &id, bgName, bgUrl, bgImageUrl); err != nil {
 		if errors.Is(err, database.ErrNoRows) {
 			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 		}
@@ -431,32 +522,31 @@ func (t Telegram) UpdateGameBGGInfo(c telebot.Context) error {
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
 	}
 
+	if bg := before.BoardGameByMessageID(int64(messageID)); bg != nil {
+		previous := ""
+		if bg.BggUrl != nil {
+			previous = *bg.BggUrl
+		}
+
+		t.audit(c, before.ID, models.AuditBGGLinked, bg.Name, previous, *bgUrl)
+	}
+
 	var event *models.Event
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
+	if event, err = t.DB.SelectEventByBoardGameMessageID(chatID, int64(messageID)); err != nil {
 		log.Println("failed to add game:", err)
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
 	}
 
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 	}
 
-	body, markup := event.FormatMsg(t.Localizer(c), t.BaseUrl, t.BotName)
-
-	_, err = t.Bot.Edit(&telebot.Message{
-		ID:   int(*event.MessageID),
-		Chat: c.Chat(),
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			return nil
-		}
-
-		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateMessageEvent"}}))
-	}
+	t.refreshEventMessage(event)
 
 	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameUpdated"}}))
 }
@@ -528,41 +618,118 @@ func (t Telegram) CallbackAddPlayer(c telebot.Context) error {
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
 	}
 
-	chatID := c.Chat().ID
 	userID := c.Sender().ID
 	userName := DefineUsername(c.Sender())
 	log.Printf("User %s (%d) clicked to join a game.", userName, userID)
 
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !event.IsActive() {
+		log.Println("event is closed")
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
 	if _, err = t.DB.InsertParticipant(eventID, boardGameID, userID, userName); err != nil {
 		log.Println("failed to add user to participants table:", err)
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToAddPlayer"}}))
 	}
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
-		log.Println("failed to add game:", err)
+	before := event
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
 	}
 
+	t.auditJoin(c, before, event, boardGameID)
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+	t.respondWaitlisted(c, event, boardGameID, userID)
+
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 	}
 
-	body, markup := event.FormatMsg(t.Localizer(c), t.BaseUrl, t.BotName)
-	_, err = t.Bot.Edit(&telebot.Message{
-		ID:   int(*event.MessageID),
-		Chat: c.Chat(),
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			return nil
-		}
+	t.refreshEventMessage(event)
+
+	return nil
+}
 
-		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateMessageEvent"}}))
+// CallbackBringGame makes the sender the one who brings the game, tapping
+// again when they already bring it leaves the game unclaimed.
+func (t Telegram) CallbackBringGame(c telebot.Context) error {
+	var event *models.Event
+	var err error
+
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
 	}
 
-	return nil
+	eventID := parts[1]
+	boardGameID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if !models.IsValidUUID(eventID) || err2 != nil {
+		log.Println("Invalid parsed id:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	userID := c.Sender().ID
+	userName := DefineUsername(c.Sender())
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !event.IsActive() {
+		log.Println("event is closed")
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
+	bg := event.BoardGame(boardGameID)
+	if bg == nil || !bg.IsGame() {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}})})
+	}
+
+	before := ""
+	if bg.BringerName != nil {
+		before = *bg.BringerName
+	}
+
+	bringerID, bringerName, after, responseID := &userID, &userName, userName, "YouBringGame"
+	if bg.IsBroughtBy(userID) {
+		bringerID, bringerName, after, responseID = nil, nil, "", "YouDontBringGame"
+	}
+
+	log.Printf("User %s (%d) changed who brings game %d.", userName, userID, boardGameID)
+
+	if err = t.DB.UpdateBoardGameBringer(boardGameID, bringerID, bringerName); err != nil {
+		log.Println("failed to update bringer:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateBringer"}}))
+	}
+
+	t.audit(c, eventID, models.AuditBringerChanged, bg.Name, before, after)
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.refreshEventMessage(event)
+
+	return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: responseID,
+		},
+		TemplateData: map[string]string{
+			"Name": bg.Name,
+		},
+	})})
 }
 
 func (t Telegram) CallbackRemovePlayer(c telebot.Context) error {
@@ -582,39 +749,2624 @@ func (t Telegram) CallbackRemovePlayer(c telebot.Context) error {
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
 	}
 
-	chatID := c.Chat().ID
 	userID := c.Sender().ID
 	userName := DefineUsername(c.Sender())
 	log.Printf("User %s (%d) clicked to exit a game.", userName, userID)
 
+	var before *models.Event
+	if before, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !before.IsActive() {
+		log.Println("event is closed")
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
 	if err = t.DB.RemoveParticipant(eventID, userID); err != nil {
 		log.Println("failed to remove user to participants table:", err)
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToRemovePlayer"}}))
 	}
 
-	if event, err = t.DB.SelectEvent(chatID); err != nil {
-		log.Println("failed to add game:", err)
+	for _, bg := range before.JoinedBoardGames(userID) {
+		t.audit(c, eventID, models.AuditPlayerLeft, bg.Name, "", "")
+	}
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
 	}
 
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
 	}
 
-	body, markup := event.FormatMsg(t.Localizer(c), t.BaseUrl, t.BotName)
-	_, err = t.Bot.Edit(&telebot.Message{
-		ID:   int(*event.MessageID),
-		Chat: c.Chat(),
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			return nil
-		}
+	t.refreshEventMessage(event)
+
+	return nil
+}
 
-		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateMessageEvent"}}))
+// CallbackLeaveGame removes the user from a single game, keeping the other
+// tables they joined.
+func (t Telegram) CallbackLeaveGame(c telebot.Context) error {
+	var before, event *models.Event
+	var err error
+
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
 	}
 
-	return nil
+	eventID := parts[1]
+	boardGameID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if !models.IsValidUUID(eventID) || err2 != nil {
+		log.Println("Invalid parsed id:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	userID := c.Sender().ID
+	userName := DefineUsername(c.Sender())
+	log.Printf("User %s (%d) clicked to leave game %d.", userName, userID, boardGameID)
+
+	if before, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !before.IsActive() {
+		log.Println("event is closed")
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
+	if err = t.DB.RemoveParticipantFromBoardGame(eventID, boardGameID, userID); err != nil {
+		log.Println("failed to remove user from game:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToRemovePlayer"}}))
+	}
+
+	if bg := before.BoardGame(boardGameID); bg != nil && (bg.IsSeated(userID) || bg.IsWaiting(userID)) {
+		t.audit(c, eventID, models.AuditPlayerLeft, bg.Name, "", "")
+	}
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+
+	t.refreshEventMessage(event)
+
+	return nil
+}
+
+// notifyPromotions announces in the chat the players seated from a waitlist.
+func (t Telegram) notifyPromotions(c telebot.Context, before, after *models.Event) {
+	models.SendPromotions(t.Bot, t.Localizer(c), before, after, models.SourceBot, func(entry models.AuditEntry) {
+		if err := t.DB.InsertAuditEntry(entry); err != nil {
+			log.Println("failed to write audit log:", err)
+		}
+	})
+}
+
+// notifyPlayers tells in private the players who opted in about the changes
+// of their games, leaving out the one who made them.
+func (t Telegram) notifyPlayers(before, after *models.Event, actorID int64) {
+	models.SendNotifications(t.Bot, t.ChatLocalizer(after.ChatID), before, after, actorID, database.KnownUser(t.DB))
+}
+
+// audit records a change made by the sender, a failure is only logged as it
+// must not prevent the change itself.
+func (t Telegram) audit(c telebot.Context, eventID string, action models.AuditAction, target, before, after string) {
+	entry := models.NewAuditEntry(eventID, c.Sender().ID, DefineUsername(c.Sender()), models.SourceBot, action, target, before, after)
+	if err := t.DB.InsertAuditEntry(entry); err != nil {
+		log.Println("failed to write audit log:", err)
+	}
+}
+
+// auditJoin records the sender joining a game, and leaving the games they
+// were moved out of, comparing the event before and after the join.
+func (t Telegram) auditJoin(c telebot.Context, before, after *models.Event, boardGameID int64) {
+	userID := c.Sender().ID
+
+	bg := after.BoardGame(boardGameID)
+	if bg == nil {
+		return
+	}
+
+	if previous := before.BoardGame(boardGameID); previous == nil || !(previous.IsSeated(userID) || previous.IsWaiting(userID)) {
+		action := models.AuditPlayerJoined
+		if bg.IsWaiting(userID) {
+			action = models.AuditPlayerWaitlisted
+		}
+
+		t.audit(c, after.ID, action, bg.Name, "", "")
+	}
+
+	for _, left := range before.JoinedBoardGames(userID) {
+		if current := after.BoardGame(left.ID); current != nil && !current.IsSeated(userID) && !current.IsWaiting(userID) {
+			t.audit(c, after.ID, models.AuditPlayerLeft, left.Name, "", "")
+		}
+	}
+}
+
+// respondWaitlisted tells the user who clicked to join that the table is full.
+func (t Telegram) respondWaitlisted(c telebot.Context, event *models.Event, boardGameID, userID int64) {
+	for _, bg := range event.BoardGames {
+		if bg.ID != boardGameID {
+			continue
+		}
+
+		for i, p := range bg.Waitlist {
+			if p.UserID != userID {
+				continue
+			}
+
+			waitlistedT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID: "JoinedWaitlist",
+				},
+				TemplateData: map[string]string{
+					"Name":     bg.Name,
+					"Position": strconv.Itoa(i + 1),
+				},
+			})
+
+			if err := c.Respond(&telebot.CallbackResponse{Text: waitlistedT}); err != nil {
+				log.Println("failed to respond to callback:", err)
+			}
+		}
+	}
+}
+
+func (t Telegram) Lock(c telebot.Context) error {
+	return t.changeEventStatus(c, models.StatusLocked)
+}
+
+func (t Telegram) Unlock(c telebot.Context) error {
+	return t.changeEventStatus(c, models.StatusOpen)
+}
+
+func (t Telegram) Close(c telebot.Context) error {
+	return t.changeEventStatus(c, models.StatusClosed)
+}
+
+func (t Telegram) Cancel(c telebot.Context) error {
+	return t.changeEventStatus(c, models.StatusCancelled)
+}
+
+func (t Telegram) SetJoinMode(c telebot.Context) error {
+	code, args := splitEventCode(c.Args())
+	if len(args) != 1 || !models.JoinMode(args[0]).IsValid() {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/join_mode",
+				"Example": fmt.Sprintf("%s | %s", models.JoinSingle, models.JoinMultiple),
+			},
+		}))
+	}
+
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.setJoinMode(c, event, models.JoinMode(args[0]))
+}
+
+func (t Telegram) setJoinMode(c telebot.Context, event *models.Event, joinMode models.JoinMode) error {
+	var err error
+
+	userID := c.Sender().ID
+	log.Printf("User %d requested to set event %s join mode to %s", userID, event.ID, joinMode)
+
+	if !t.canOrganize(event, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}}))
+	}
+
+	if !event.IsActive() {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}}))
+	}
+
+	if err = t.DB.UpdateEventJoinMode(event.ID, joinMode); err != nil {
+		log.Println("failed to update event join mode:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateEvent"}}))
+	}
+
+	if event.JoinMode != joinMode {
+		t.audit(c, event.ID, models.AuditJoinModeChanged, "", string(event.JoinMode), string(joinMode))
+	}
+
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "JoinModeChanged",
+		},
+		TemplateData: map[string]string{
+			"Name": event.Name,
+			"Mode": t.Localizer(c).MustLocalizeMessage(&i18n.Message{ID: models.JoinModeMessageID(joinMode)}),
+		},
+	}))
+}
+
+func (t Telegram) Events(c telebot.Context) error {
+	body, markup, err := t.eventsPage(c, 0)
+	if err != nil {
+		log.Println("failed to load events:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	return c.Reply(body, markup, telebot.NoPreview)
+}
+
+// CallbackEventsPage moves the /events list to another page.
+func (t Telegram) CallbackEventsPage(c telebot.Context) error {
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 2 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	page, err := strconv.Atoi(parts[1])
+	if err != nil || page < 0 {
+		log.Println("Invalid parsed page:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	body, markup, err := t.eventsPage(c, page)
+	if err != nil {
+		log.Println("failed to load events:", err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}})})
+	}
+
+	if _, err = t.Bot.Edit(c.Callback().Message, body, markup, telebot.NoPreview); err != nil {
+		log.Println("failed to edit message", err)
+		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
+			return c.Respond()
+		}
+
+		return err
+	}
+
+	return c.Respond()
+}
+
+// eventsPage renders a page of the events of the chat, the last one when the
+// page is past the end.
+func (t Telegram) eventsPage(c telebot.Context, page int) (string, *telebot.ReplyMarkup, error) {
+	chatID := c.Chat().ID
+
+	count, err := t.DB.CountEvents(chatID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pages := (count + models.EventsPageSize - 1) / models.EventsPageSize
+	if page >= pages {
+		page = max(pages-1, 0)
+	}
+
+	summaries, err := t.DB.SelectEventSummaries(chatID, models.EventsPageSize, page*models.EventsPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	body, markup := models.FormatEventList(t.Localizer(c), summaries, page, pages, t.BaseUrl, t.BotName, c.Chat().Username)
+
+	return body, markup, nil
+}
+
+func (t Telegram) Log(c telebot.Context) error {
+	code, _ := splitEventCode(c.Args())
+
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.showLog(c, event)
+}
+
+// maxLogLength keeps the /log reply below the Telegram message size limit.
+const maxLogLength = 3500
+
+// showLog replies with the latest changes of the event, newest first.
+func (t Telegram) showLog(c telebot.Context, event *models.Event) error {
+	entries, err := t.DB.SelectAuditLog(event.ID, models.AuditLogSize)
+	if err != nil {
+		log.Println("failed to load audit log:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if len(entries) == 0 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NoAuditEntries",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(event.Name),
+			},
+		}))
+	}
+
+	msg := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "AuditLogTitle",
+		},
+		TemplateData: map[string]string{
+			"Name": html.EscapeString(event.Name),
+		},
+	}) + "\n"
+
+	for _, entry := range entries {
+		line := "\n" + html.EscapeString(entry.Format(t.Localizer(c)))
+		if len(msg)+len(line) > maxLogLength {
+			break
+		}
+
+		msg += line
+	}
+
+	return c.Reply(msg, telebot.NoPreview)
+}
+
+func (t Telegram) changeEventStatus(c telebot.Context, status models.EventStatus) error {
+	code, _ := splitEventCode(c.Args())
+
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.setEventStatus(c, event, status)
+}
+
+func (t Telegram) setEventStatus(c telebot.Context, event *models.Event, status models.EventStatus) error {
+	var err error
+
+	userID := c.Sender().ID
+	log.Printf("User %d requested to set event %s status to %s", userID, event.ID, status)
+
+	if !t.canOrganize(event, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}}))
+	}
+
+	statusT := t.Localizer(c).MustLocalizeMessage(&i18n.Message{ID: models.StatusMessageID(status)})
+
+	if !event.Status.CanTransitionTo(status) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "InvalidStatusTransition",
+			},
+			TemplateData: map[string]string{
+				"Name":   event.Name,
+				"Status": statusT,
+			},
+		}))
+	}
+
+	if err = t.DB.UpdateEventStatus(event.ID, status); err != nil {
+		log.Println("failed to update event status:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateEvent"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditStatusChanged, "", string(event.Status), string(status))
+
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "EventStatusChanged",
+		},
+		TemplateData: map[string]string{
+			"Name":   event.Name,
+			"Status": statusT,
+		},
+	}))
+}
+
+// refreshEventMessage queues the edit of the event message. The event is
+// loaded again right before the edit, so a burst of changes is shown by a
+// single edit with the latest state.
+func (t Telegram) refreshEventMessage(event *models.Event) {
+	if event.MessageID == nil {
+		log.Println("event message id is nil")
+		return
+	}
+
+	eventID := event.ID
+	t.Edits.Enqueue(event.ChatID, int(*event.MessageID), func() (string, *telebot.ReplyMarkup, error) {
+		event, err := t.DB.SelectEventByEventID(eventID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		body, markup := event.FormatMsg(t.ChatLocalizer(event.ChatID), t.BaseUrl, t.BotName)
+
+		return body, markup, nil
+	})
+}
+
+var errEventPickerSent = errors.New("event picker sent")
+
+// splitEventCode separates a leading #code argument from the others.
+func splitEventCode(args []string) (string, []string) {
+	if len(args) > 0 && len(args[0]) > 1 && strings.HasPrefix(args[0], "#") {
+		return args[0][1:], args[1:]
+	}
+
+	return "", args
+}
+
+// targetEvent resolves the event a command refers to: the one with the given
+// code, the one whose event or game message the command replies to, or the
+// only active event of the chat. When several events are active and nothing
+// points to one of them, a picker is sent and errEventPickerSent returned.
+func (t Telegram) targetEvent(c telebot.Context, code string) (*models.Event, error) {
+	event, events, err := t.findEvent(c, code)
+	if err != nil || event != nil {
+		return event, err
+	}
+
+	if err = t.sendEventPicker(c, events); err != nil {
+		return nil, err
+	}
+
+	return nil, errEventPickerSent
+}
+
+// findEvent resolves the event like targetEvent, but returns the active
+// events instead of sending the picker when several of them could be meant.
+func (t Telegram) findEvent(c telebot.Context, code string) (*models.Event, []models.Event, error) {
+	chatID := c.Chat().ID
+
+	if code != "" {
+		event, err := t.DB.SelectEventByCode(chatID, code)
+		return event, nil, err
+	}
+
+	if replyTo := c.Message().ReplyTo; replyTo != nil {
+		event, err := t.DB.SelectEventByMessageID(chatID, int64(replyTo.ID))
+		if errors.Is(err, database.ErrNoRows) {
+			event, err = t.DB.SelectEventByBoardGameMessageID(chatID, int64(replyTo.ID))
+		}
+
+		if err == nil {
+			return event, nil, nil
+		}
+
+		if !errors.Is(err, database.ErrNoRows) {
+			return nil, nil, err
+		}
+	}
+
+	events, err := t.DB.SelectActiveEvents(chatID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var event *models.Event
+	switch len(events) {
+	case 0:
+		event, err = t.DB.SelectEvent(chatID)
+	case 1:
+		event, err = t.DB.SelectEventByEventID(events[0].ID)
+	default:
+		return nil, events, nil
+	}
+
+	return event, nil, err
+}
+
+func (t Telegram) replyTargetError(c telebot.Context, err error) error {
+	if errors.Is(err, errEventPickerSent) {
+		return nil
+	}
+
+	log.Println("failed to load event:", err)
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+}
+
+func eventLabel(event models.Event) string {
+	label := fmt.Sprintf("%s #%s", event.Name, event.Code())
+	if event.StartsAt != nil {
+		label += " · " + event.FormatWhen()
+	}
+
+	return label
+}
+
+// sendEventPicker replies to the command with one button per active event,
+// the choice is handled by CallbackPickEvent.
+func (t Telegram) sendEventPicker(c telebot.Context, events []models.Event) error {
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	for _, event := range events {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   eventLabel(event),
+			Unique: string(models.PickEvent),
+			Data:   event.ID,
+		}})
+	}
+
+	_, err := t.Bot.Reply(c.Message(), t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "PickEvent"}}), markup)
+	return err
+}
+
+// parseCommand splits a command message into the command, without the bot
+// mention, and its arguments.
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	command, _, _ := strings.Cut(fields[0], "@")
+
+	return command, fields[1:]
+}
+
+// CallbackPickEvent resumes the command the picker was sent for, on the
+// chosen event.
+func (t Telegram) CallbackPickEvent(c telebot.Context) error {
+	var event *models.Event
+	var err error
+
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 2 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	eventID := parts[1]
+	picker := c.Callback().Message
+	if !models.IsValidUUID(eventID) || picker == nil || picker.ReplyTo == nil {
+		log.Println("Invalid parsed id:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	if picker.ReplyTo.Sender == nil || picker.ReplyTo.Sender.ID != c.Sender().ID {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotYourPicker"}})})
+	}
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil || event.ChatID != c.Chat().ID {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if _, err = t.Bot.Edit(picker, "📆 "+eventLabel(*event)); err != nil {
+		log.Println("failed to edit message", err)
+	}
+
+	command, args := parseCommand(picker.ReplyTo.Text)
+	_, args = splitEventCode(args)
+	log.Printf("User %d picked event %s for %s", c.Sender().ID, event.ID, command)
+
+	switch command {
+	case "/add_game":
+		return t.addGame(c, event, args)
+	case "/lock":
+		return t.setEventStatus(c, event, models.StatusLocked)
+	case "/unlock":
+		return t.setEventStatus(c, event, models.StatusOpen)
+	case "/close":
+		return t.setEventStatus(c, event, models.StatusClosed)
+	case "/cancel":
+		return t.setEventStatus(c, event, models.StatusCancelled)
+	case "/log":
+		return t.showLog(c, event)
+	case "/remove_game", "/rename_game", "/set_players", "/result":
+		return t.sendGamePicker(c, event, command, picker)
+	case "/join_mode":
+		if len(args) > 0 {
+			return t.setJoinMode(c, event, models.JoinMode(args[0]))
+		}
+	case "/vote":
+		return t.vote(c, event, args)
+	case "/organizer":
+		return t.listOrganizers(c, event)
+	}
+
+	log.Println("Invalid picked command:", command)
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+}
+
+func (t Telegram) RemoveGame(c telebot.Context) error {
+	return t.gameCommand(c, "/remove_game")
+}
+
+func (t Telegram) RenameGame(c telebot.Context) error {
+	return t.gameCommand(c, "/rename_game")
+}
+
+func (t Telegram) SetPlayers(c telebot.Context) error {
+	return t.gameCommand(c, "/set_players")
+}
+
+// Result records the result of a game, see resultUsage.
+func (t Telegram) Result(c telebot.Context) error {
+	return t.gameCommand(c, "/result")
+}
+
+// gameCommand runs a command acting on a single game, the one whose message
+// is replied to, or the one chosen with a picker otherwise.
+func (t Telegram) gameCommand(c telebot.Context, command string) error {
+	code, args := splitEventCode(c.Args())
+	if usage := t.gameCommandUsage(c, command, args); usage != "" {
+		return c.Reply(usage)
+	}
+
+	if reply := c.Message().ReplyTo; reply != nil && code == "" {
+		event, err := t.DB.SelectEventByBoardGameMessageID(c.Chat().ID, int64(reply.ID))
+		if err == nil {
+			if bg := event.BoardGameByMessageID(int64(reply.ID)); bg != nil {
+				return t.runGameCommand(c, command, event, bg, args)
+			}
+		} else if !errors.Is(err, database.ErrNoRows) {
+			log.Println("failed to load event:", err)
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+		}
+	}
+
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.sendGamePicker(c, event, command, nil)
+}
+
+// gameCommandUsage returns the usage of the command when its arguments are
+// missing, empty otherwise.
+func (t Telegram) gameCommandUsage(c telebot.Context, command string, args []string) string {
+	example := ""
+	switch command {
+	case "/rename_game":
+		if len(args) > 0 {
+			return ""
+		}
+
+		example = t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameName"}})
+	case "/set_players":
+		if len(args) == 1 {
+			return ""
+		}
+
+		example = "4"
+	case "/result":
+		if len(args) > 0 {
+			return ""
+		}
+
+		return t.resultUsage(c)
+	default:
+		return ""
+	}
+
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "Usage",
+		},
+		TemplateData: map[string]string{
+			"Command": command,
+			"Example": example,
+		},
+	})
+}
+
+// canEdit reports whether the user can change the games of the event, the
+// administrators of the chat have the same rights as its organizers.
+func (t Telegram) canEdit(event *models.Event, userID int64) bool {
+	if event.CanEdit(userID) {
+		return true
+	}
+
+	return event.Status == models.StatusLocked && t.Admins.IsAdmin(event.ChatID, userID)
+}
+
+// canOrganize reports whether the user can change the settings of the event:
+// its organizers and the administrators of the chat can.
+func (t Telegram) canOrganize(event *models.Event, userID int64) bool {
+	return event.IsOrganizer(userID) || t.Admins.IsAdmin(event.ChatID, userID)
+}
+
+// editDenied explains why the sender cannot change the games of the event,
+// it is empty when they can.
+func (t Telegram) editDenied(c telebot.Context, event *models.Event) string {
+	if !event.IsActive() {
+		return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})
+	}
+
+	if !t.canEdit(event, c.Sender().ID) {
+		return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocked"}})
+	}
+
+	return ""
+}
+
+// sendGamePicker asks which game of the event the command is about, replacing
+// the event picker when there is one. The choice is handled by
+// CallbackPickGame.
+func (t Telegram) sendGamePicker(c telebot.Context, event *models.Event, command string, picker *telebot.Message) error {
+	// the players record the results also when they cannot change the event
+	if denied := t.editDenied(c, event); denied != "" && command != "/result" {
+		return c.Reply(denied)
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	for _, bg := range event.BoardGames {
+		if (command == "/rename_game" || command == "/result") && !bg.IsGame() {
+			continue
+		}
+
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   bg.DisplayName(t.Localizer(c)),
+			Unique: string(models.PickGame),
+			Data:   fmt.Sprintf("%s|%d", event.ID, bg.ID),
+		}})
+	}
+
+	if len(markup.InlineKeyboard) == 0 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NoGames",
+			},
+			TemplateData: map[string]string{
+				"Name": event.Name,
+			},
+		}))
+	}
+
+	pickT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "PickGame"}})
+
+	var err error
+	if picker != nil {
+		_, err = t.Bot.Edit(picker, pickT, markup)
+	} else {
+		_, err = t.Bot.Reply(c.Message(), pickT, markup)
+	}
+
+	return err
+}
+
+// CallbackPickGame resumes the command the game picker was sent for, on the
+// chosen game.
+func (t Telegram) CallbackPickGame(c telebot.Context) error {
+	var event *models.Event
+	var err error
+
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	eventID := parts[1]
+	boardGameID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	picker := c.Callback().Message
+	if !models.IsValidUUID(eventID) || err2 != nil || picker == nil || picker.ReplyTo == nil {
+		log.Println("Invalid parsed id:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	if picker.ReplyTo.Sender == nil || picker.ReplyTo.Sender.ID != c.Sender().ID {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotYourPicker"}})})
+	}
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil || event.ChatID != c.Chat().ID {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	bg := event.BoardGame(boardGameID)
+	if bg == nil {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}}))
+	}
+
+	if _, err = t.Bot.Edit(picker, "🎲 "+html.EscapeString(bg.DisplayName(t.Localizer(c)))); err != nil {
+		log.Println("failed to edit message", err)
+	}
+
+	command, args := parseCommand(picker.ReplyTo.Text)
+	_, args = splitEventCode(args)
+	log.Printf("User %d picked game %d for %s", c.Sender().ID, bg.ID, command)
+
+	switch command {
+	case "/remove_game", "/rename_game", "/set_players", "/result":
+		if usage := t.gameCommandUsage(c, command, args); usage != "" {
+			return c.Reply(usage)
+		}
+
+		return t.runGameCommand(c, command, event, bg, args)
+	}
+
+	log.Println("Invalid picked command:", command)
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+}
+
+func (t Telegram) runGameCommand(c telebot.Context, command string, event *models.Event, bg *models.BoardGame, args []string) error {
+	if command == "/result" {
+		return t.recordResult(c, event, bg, strings.Join(args, " "))
+	}
+
+	if denied := t.editDenied(c, event); denied != "" {
+		return c.Reply(denied)
+	}
+
+	switch command {
+	case "/remove_game":
+		return t.removeGame(c, event, bg)
+	case "/rename_game":
+		return t.renameGame(c, event, bg, strings.Join(args, " "))
+	case "/set_players":
+		maxPlayers, err := strconv.Atoi(args[0])
+		if err != nil || maxPlayers < 1 {
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidNumberOfPlayers"}}))
+		}
+
+		return t.setPlayers(c, event, bg, maxPlayers)
+	}
+
+	return nil
+}
+
+func (t Telegram) removeGame(c telebot.Context, event *models.Event, bg *models.BoardGame) error {
+	var err error
+
+	userName := DefineUsername(c.Sender())
+	log.Printf("User %s (%d) removes game %d from event %s", userName, c.Sender().ID, bg.ID, event.ID)
+
+	if err = t.DB.DeleteBoardGameByID(bg.ID); err != nil {
+		log.Println("failed to delete board game:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditGameDeleted, bg.Name, "", "")
+
+	message := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "GameHasBeenDeleted",
+		},
+		TemplateData: map[string]string{
+			"Username": userName,
+			"Game":     bg.DisplayName(t.Localizer(c)),
+			"Event":    event.Name,
+		},
+	})
+
+	before := event
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.notifyPlayers(before, event, c.Sender().ID)
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(message)
+}
+
+// resultUsage explains /result: the players with their scores, then the
+// duration and the notes.
+func (t Telegram) resultUsage(c telebot.Context) string {
+	notesT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ResultNotes"}})
+
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "Usage",
+		},
+		TemplateData: map[string]string{
+			"Command": "/result",
+			"Example": fmt.Sprintf("alice 42, bob 37 | 1h30m | %s", notesT),
+		},
+	}) + "\n" + t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ResultHelp"}})
+}
+
+// canRecordResult reports whether the user can record the result of the
+// game, the administrators of the chat have the same rights as its
+// organizers.
+func (t Telegram) canRecordResult(event *models.Event, bg *models.BoardGame, userID int64) bool {
+	if event.CanRecordResult(*bg, userID) {
+		return true
+	}
+
+	return event.Status != models.StatusCancelled && bg.IsGame() && t.Admins.IsAdmin(event.ChatID, userID)
+}
+
+// recordResult stores the winners, the scores, the duration and the notes of
+// a game, replacing its previous result.
+func (t Telegram) recordResult(c telebot.Context, event *models.Event, bg *models.BoardGame, text string) error {
+	userID := c.Sender().ID
+	userName := DefineUsername(c.Sender())
+	log.Printf("User %s (%d) records the result of game %d of event %s", userName, userID, bg.ID, event.ID)
+
+	if !t.canRecordResult(event, bg, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NotAPlayer",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(bg.Name),
+			},
+		}))
+	}
+
+	entries, duration, notes, err := models.ParseResult(text)
+	if err != nil {
+		return c.Reply(t.resultUsage(c))
+	}
+
+	scores, unknown := models.ResolveResult(event, bg, entries)
+	if unknown != "" {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "PlayerNotFound",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(unknown),
+			},
+		}))
+	}
+
+	play := models.NewPlay(event, bg, userID, userName, time.Now())
+	play.DurationMinutes = duration
+	play.Notes = notes
+	play.Scores = scores
+	play.SortScores()
+
+	if _, err = t.DB.InsertPlay(play); err != nil {
+		log.Println("failed to insert play:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToRecordResult"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditResultRecorded, bg.Name, "", strings.Join(play.Winners(), ", "))
+
+	return c.Reply(play.FormatPlay(t.Localizer(c), event.Name), telebot.NoPreview)
+}
+
+// Leaderboard replies with the ratings of the players of the chat, overall or
+// at the game named in the arguments, computed from the recorded results.
+func (t Telegram) Leaderboard(c telebot.Context) error {
+	plays, err := t.DB.SelectChatPlays(c.Chat().ID)
+	if err != nil {
+		log.Println("failed to load plays:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToLoadLeaderboard"}}))
+	}
+
+	if len(plays) == 0 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NoPlays"}}))
+	}
+
+	latest := plays[len(plays)-1]
+
+	game := strings.TrimSpace(strings.Join(c.Args(), " "))
+	if game != "" {
+		var name string
+		if plays, name = models.PlaysOfGame(plays, game); plays == nil {
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID: "NoPlaysOfGame",
+				},
+				TemplateData: map[string]string{
+					"Name": html.EscapeString(game),
+				},
+			}))
+		}
+		game = name
+	}
+
+	open := telebot.InlineButton{
+		Text: t.Localizer(c).MustLocalizeMessage(&i18n.Message{ID: "FullLeaderboard"}),
+		URL:  fmt.Sprintf("%s/events/%s/leaderboard", t.BaseUrl, latest.EventID),
+	}
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{{open}}
+
+	return c.Reply(models.FormatLeaderboard(t.Localizer(c), game, models.ComputeRatings(plays)), markup, telebot.NoPreview)
+}
+
+func (t Telegram) renameGame(c telebot.Context, event *models.Event, bg *models.BoardGame, name string) error {
+	var err error
+
+	name = strings.TrimSpace(name)
+	log.Printf("User %d renames game %d of event %s to %s", c.Sender().ID, bg.ID, event.ID, name)
+
+	if name == models.PLAYER_COUNTER {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	if err = t.DB.UpdateBoardGameName(bg.ID, name); err != nil {
+		log.Println("failed to rename game:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditGameRenamed, "", bg.Name, name)
+
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "GameRenamed",
+		},
+		TemplateData: map[string]string{
+			"Old":  bg.Name,
+			"Name": name,
+		},
+	}))
+}
+
+func (t Telegram) setPlayers(c telebot.Context, event *models.Event, bg *models.BoardGame, maxPlayers int) error {
+	var err error
+
+	log.Printf("User %d sets max players of game %d to %d", c.Sender().ID, bg.ID, maxPlayers)
+
+	if err = t.DB.UpdateBoardGamePlayerNumberByID(bg.ID, maxPlayers); err != nil {
+		log.Println("failed to update game:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateGame"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditMaxPlayersChanged, bg.Name, strconv.FormatInt(bg.MaxPlayers, 10), strconv.Itoa(maxPlayers))
+
+	before := event
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.notifyPromotions(c, before, event)
+	t.notifyPlayers(before, event, c.Sender().ID)
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameUpdated"}}))
+}
+
+// Reminders lets a user opt in to private reminders of the events they
+// joined, it only works in the private chat with the bot.
+func (t Telegram) Reminders(c telebot.Context) error {
+	if c.Chat().Type != telebot.ChatPrivate {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "RemindersPrivateOnly",
+			},
+			TemplateData: map[string]string{
+				"Link": fmt.Sprintf("https://t.me/%s", t.BotName),
+			},
+		}))
+	}
+
+	args := c.Args()
+	if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/reminders",
+				"Example": "on|off",
+			},
+		}))
+	}
+
+	user, err := t.privateUser(c)
+	if err != nil {
+		log.Println("failed to load user:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetReminders"}}))
+	}
+
+	user.Reminders = args[0] == "on"
+
+	if err = t.DB.UpsertUser(user); err != nil {
+		log.Println("failed to save reminders preference:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetReminders"}}))
+	}
+
+	messageID := "RemindersOff"
+	if user.Reminders {
+		messageID = "RemindersOn"
+	}
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: messageID}}))
+}
+
+// privateUser returns the sender with their private chat with the bot,
+// keeping the preferences they saved before.
+func (t Telegram) privateUser(c telebot.Context) (models.User, error) {
+	chatID := c.Chat().ID
+	user := models.User{ID: c.Sender().ID}
+
+	saved, err := t.DB.SelectUser(c.Sender().ID)
+	switch {
+	case err == nil:
+		user = *saved
+	case !errors.Is(err, database.ErrNoRows):
+		return user, err
+	}
+
+	user.UserName = DefineUsername(c.Sender())
+	user.ChatID = &chatID
+
+	return user, nil
+}
+
+// startNotifications turns on every private notification of the sender, it
+// is called by /start in the private chat with the bot.
+func (t Telegram) startNotifications(c telebot.Context) string {
+	user, err := t.privateUser(c)
+	if err == nil {
+		user.SetNotifications(true)
+		err = t.DB.UpsertUser(user)
+	}
+
+	if err != nil {
+		log.Println("failed to save notifications preference:", err)
+		return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetNotifications"}})
+	}
+
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotificationsStarted"}})
+}
+
+// Notifications shows the private notifications of the sender with a button
+// to turn each of them on or off, /notifications on|off changes all of them.
+// It only works in the private chat with the bot.
+func (t Telegram) Notifications(c telebot.Context) error {
+	if c.Chat().Type != telebot.ChatPrivate {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NotificationsPrivateOnly",
+			},
+			TemplateData: map[string]string{
+				"Link": fmt.Sprintf("https://t.me/%s", t.BotName),
+			},
+		}))
+	}
+
+	args := c.Args()
+	if len(args) > 1 || (len(args) == 1 && args[0] != "on" && args[0] != "off") {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/notifications",
+				"Example": "on|off",
+			},
+		}))
+	}
+
+	user, err := t.privateUser(c)
+	if err != nil {
+		log.Println("failed to load user:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetNotifications"}}))
+	}
+
+	if len(args) == 1 {
+		user.SetNotifications(args[0] == "on")
+
+		if err = t.DB.UpsertUser(user); err != nil {
+			log.Println("failed to save notifications preference:", err)
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetNotifications"}}))
+		}
+	}
+
+	body, markup := t.notificationSettings(c, user)
+
+	return c.Reply(body, markup)
+}
+
+// CallbackNotify turns one kind of private notification of the sender on or
+// off.
+func (t Telegram) CallbackNotify(c telebot.Context) error {
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 2 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	kind, ok := models.ParseNotificationKind(parts[1])
+	if !ok || c.Chat().Type != telebot.ChatPrivate {
+		log.Println("Invalid parsed kind:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	user, err := t.privateUser(c)
+	if err == nil {
+		user.SetNotification(kind, !user.Wants(kind))
+		err = t.DB.UpsertUser(user)
+	}
+
+	if err != nil {
+		log.Println("failed to save notifications preference:", err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToSetNotifications"}})})
+	}
+
+	body, markup := t.notificationSettings(c, user)
+	if err = c.Edit(body, markup); err != nil && !strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
+		log.Println("failed to edit notifications message:", err)
+	}
+
+	return c.Respond()
+}
+
+// notificationSettings lists the kinds of private notifications, each button
+// tells whether it is on and turns it on or off.
+func (t Telegram) notificationSettings(c telebot.Context, user models.User) (string, *telebot.ReplyMarkup) {
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	for _, kind := range models.NotificationKinds {
+		icon := "🔕 "
+		if user.Wants(kind) {
+			icon = "🔔 "
+		}
+
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   icon + t.Localizer(c).MustLocalizeMessage(&i18n.Message{ID: models.NotificationKindMessageID(kind)}),
+			Unique: string(models.Notify),
+			Data:   string(kind),
+		}})
+	}
+
+	return t.Localizer(c).MustLocalizeMessage(&i18n.Message{ID: "NotificationsTitle"}), markup
+}
+
+// SendReminders posts a reminder for the events starting within one of the
+// offsets. Each reminder is claimed in the database first, so that it is sent
+// once even across restarts, and the offsets due together are merged.
+func (t Telegram) SendReminders(offsets []time.Duration) {
+	now := time.Now()
+
+	events, err := t.DB.SelectScheduledEvents()
+	if err != nil {
+		log.Println("failed to load scheduled events:", err)
+		return
+	}
+
+	for _, e := range events {
+		due := models.DueReminders(*e.StartsAt, now, offsets)
+
+		claimed := false
+		for _, offset := range due {
+			ok, err := t.DB.ClaimReminder(e.ID, *e.StartsAt, offset)
+			if err != nil {
+				log.Println("failed to claim reminder:", err)
+				continue
+			}
+
+			claimed = claimed || ok
+		}
+
+		if !claimed {
+			continue
+		}
+
+		event, err := t.DB.SelectEventByEventID(e.ID)
+		if err != nil {
+			log.Println("failed to load event:", err)
+			continue
+		}
+
+		t.sendReminder(event, now)
+	}
+}
+
+// sendReminder posts the reminder in the chat of the event, as a reply to its
+// message, and sends it to the seated players who opted in.
+func (t Telegram) sendReminder(event *models.Event, now time.Time) {
+	log.Printf("Sending reminder of event %s in chat %d", event.ID, event.ChatID)
+
+	localizer := t.ChatLocalizer(event.ChatID)
+	chat := &telebot.Chat{ID: event.ChatID}
+
+	opts := &telebot.SendOptions{DisableWebPagePreview: true}
+	if event.MessageID != nil {
+		opts.ReplyTo = &telebot.Message{ID: int(*event.MessageID), Chat: chat}
+	}
+
+	if _, err := t.Bot.Send(chat, event.FormatReminder(localizer, now), opts); err != nil {
+		log.Println("failed to send reminder:", err)
+	}
+
+	notified := map[int64]bool{}
+	for _, bg := range event.BoardGames {
+		for _, p := range bg.Participants {
+			if notified[p.UserID] {
+				continue
+			}
+			notified[p.UserID] = true
+
+			user, err := t.DB.SelectUser(p.UserID)
+			if err != nil {
+				if !errors.Is(err, database.ErrNoRows) {
+					log.Println("failed to load user:", err)
+				}
+				continue
+			}
+
+			if !user.Reminders || user.ChatID == nil {
+				continue
+			}
+
+			if _, err = t.Bot.Send(&telebot.Chat{ID: *user.ChatID}, event.FormatPrivateReminder(localizer, now, p.UserID), telebot.NoPreview); err != nil {
+				log.Println("failed to send private reminder:", err)
+			}
+		}
+	}
+}
+
+var seriesActions = map[string]models.SeriesStatus{
+	"pause":  models.SeriesPaused,
+	"resume": models.SeriesActive,
+	"stop":   models.SeriesStopped,
+}
+
+func (t Telegram) recurringUsage(c telebot.Context) string {
+	eventNameT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventName"}})
+	eventLocationT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocation"}})
+	gameNameT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameName"}})
+
+	usage := func(example string) string {
+		return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/recurring",
+				"Example": example,
+			},
+		})
+	}
+
+	return usage(fmt.Sprintf("%s | weekly thursday 20:30 | %s | %s, %s", eventNameT, eventLocationT, gameNameT, gameNameT)) + "\n" +
+		usage("monthly 15 20:30, monthly first friday 20:30") + "\n" +
+		usage("pause|resume|stop [id]")
+}
+
+// Recurring lists the series of the chat, creates a new one or pauses,
+// resumes and stops one of them.
+func (t Telegram) Recurring(c telebot.Context) error {
+	args := c.Args()
+	if len(args) == 0 {
+		series, err := t.DB.SelectSeries(c.Chat().ID)
+		if err != nil {
+			log.Println("failed to load series:", err)
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SeriesNotFound"}}))
+		}
+
+		return c.Reply(models.FormatSeriesList(t.Localizer(c), series, time.Now()) + "\n\n" + t.recurringUsage(c))
+	}
+
+	text := strings.Join(args, " ")
+	if status, ok := seriesActions[args[0]]; ok && len(args) == 2 && !strings.Contains(text, "|") {
+		return t.setSeriesStatus(c, args[1], status)
+	}
+
+	series, err := models.ParseSeriesArgs(text)
+	if err != nil || series.Name == "" {
+		log.Println("invalid series arguments:", err)
+		invalidT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidSeriesRule"}})
+		return c.Reply(invalidT + "\n" + t.recurringUsage(c))
+	}
+
+	series.ChatID = c.Chat().ID
+	series.UserID = c.Sender().ID
+	series.UserName = DefineUsername(c.Sender())
+
+	log.Printf("Creating series: %s by user: %s (%d) in chat: %d", series.Name, series.UserName, series.UserID, series.ChatID)
+
+	if series.ID, err = t.DB.InsertSeries(*series); err != nil {
+		log.Println("failed to create series:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToCreateSeries"}}))
+	}
+
+	now := time.Now()
+	createdT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "SeriesCreated",
+		},
+		TemplateData: map[string]string{
+			"ID":   strconv.FormatInt(series.ID, 10),
+			"Name": html.EscapeString(series.Name),
+			"Rule": series.FormatRule(t.Localizer(c)),
+			"Days": strconv.Itoa(t.SeriesDaysAhead),
+			"Next": series.NextStart(now).Format(models.EventTimeLayout),
+		},
+	})
+
+	if err = c.Reply(createdT); err != nil {
+		return err
+	}
+
+	t.scheduleSeries(*series, now)
+
+	return nil
+}
+
+func (t Telegram) setSeriesStatus(c telebot.Context, arg string, status models.SeriesStatus) error {
+	notFoundT := t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SeriesNotFound"}})
+
+	seriesID, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+	if err != nil {
+		log.Println("invalid series id:", arg)
+		return c.Reply(notFoundT)
+	}
+
+	series, err := t.DB.SelectSeriesByID(seriesID)
+	if err != nil || series.ChatID != c.Chat().ID || series.Status == models.SeriesStopped {
+		log.Println("failed to load series:", err)
+		return c.Reply(notFoundT)
+	}
+
+	if !series.CanEdit(c.Sender().ID) {
+		log.Println("series is locked")
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SeriesLocked"}}))
+	}
+
+	log.Printf("Setting status of series %d to %s", series.ID, status)
+
+	if err = t.DB.UpdateSeriesStatus(series.ID, status); err != nil {
+		log.Println("failed to update series:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateSeries"}}))
+	}
+
+	messageID := "SeriesResumed"
+	switch status {
+	case models.SeriesPaused:
+		messageID = "SeriesPaused"
+	case models.SeriesStopped:
+		messageID = "SeriesStopped"
+	}
+
+	if err = c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: messageID,
+		},
+		TemplateData: map[string]string{
+			"ID":   strconv.FormatInt(series.ID, 10),
+			"Name": html.EscapeString(series.Name),
+		},
+	})); err != nil {
+		return err
+	}
+
+	if status == models.SeriesActive {
+		series.Status = status
+		t.scheduleSeries(*series, time.Now())
+	}
+
+	return nil
+}
+
+// CreateSeriesEvents creates the next event of the active series starting
+// within SeriesDaysAhead days.
+func (t Telegram) CreateSeriesEvents() {
+	series, err := t.DB.SelectActiveSeries()
+	if err != nil {
+		log.Println("failed to load series:", err)
+		return
+	}
+
+	now := time.Now()
+	for _, s := range series {
+		t.scheduleSeries(s, now)
+	}
+}
+
+// scheduleSeries creates the next event of the series when it is due. The
+// event is claimed in the database first, so that it is created only once.
+func (t Telegram) scheduleSeries(series models.Series, now time.Time) {
+	startsAt := series.NextStart(now)
+	if startsAt.Sub(now) > time.Duration(t.SeriesDaysAhead)*24*time.Hour {
+		return
+	}
+
+	claimed, err := t.DB.ClaimSeriesEvent(series.ID, startsAt)
+	if err != nil {
+		log.Println("failed to claim series event:", err)
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	if err = t.createSeriesEvent(series, startsAt); err != nil {
+		log.Printf("failed to create event of series %d: %v", series.ID, err)
+	}
+}
+
+// createSeriesEvent creates an event from the series template and posts its
+// message, followed by the message of each default game.
+func (t Telegram) createSeriesEvent(series models.Series, startsAt time.Time) error {
+	log.Printf("Creating event of series %d starting at %s in chat %d", series.ID, startsAt, series.ChatID)
+
+	eventID, err := t.DB.InsertEvent(series.ChatID, series.UserID, series.UserName, series.Name, nil, &startsAt, nil, series.Location, series.EventStatus())
+	if err != nil {
+		return err
+	}
+
+	t.auditSeries(series, eventID, models.AuditEventCreated, "", "", series.Name)
+
+	if strings.Contains(series.Name, "👥") {
+		if _, err = t.DB.InsertBoardGame(eventID, models.PLAYER_COUNTER, -1, nil, nil, nil, nil, nil, &series.UserName); err != nil {
+			return err
+		}
+	}
+
+	gameIDs := []int64{}
+	ctx := context.Background()
+	for _, gameName := range series.Games {
+		maxPlayers, bgID, bgName, bgUrl, bggImageUrl := t.searchGame(ctx, gameName, 5)
+
+		boardGameID, err := t.DB.InsertBoardGame(eventID, gameName, maxPlayers, bgID, bgName, bgUrl, bggImageUrl, &series.UserID, &series.UserName)
+		if err != nil {
+			return err
+		}
+
+		gameIDs = append(gameIDs, boardGameID)
+		t.auditSeries(series, eventID, models.AuditGameAdded, gameName, "", strconv.Itoa(maxPlayers))
+	}
+
+	event, err := t.DB.SelectEventByEventID(eventID)
+	if err != nil {
+		return err
+	}
+
+	localizer := t.ChatLocalizer(series.ChatID)
+	chat := &telebot.Chat{ID: series.ChatID}
+
+	body, markup := event.FormatMsg(localizer, t.BaseUrl, t.BotName)
+	eventMsg, err := t.Bot.Send(chat, body, markup, telebot.NoPreview)
+	if err != nil {
+		return err
+	}
+
+	if err = t.DB.UpdateEventMessageID(eventID, int64(eventMsg.ID)); err != nil {
+		return err
+	}
+
+	for _, boardGameID := range gameIDs {
+		bg := event.BoardGame(boardGameID)
+		if bg == nil {
+			continue
+		}
+
+		link := ""
+		if bg.BggUrl != nil && bg.BggName != nil {
+			link = fmt.Sprintf(", <a href='%s'>%s</a>", *bg.BggUrl, *bg.BggName)
+		}
+
+		message := localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "GameAdded",
+			},
+			TemplateData: map[string]string{
+				"Name":       bg.Name,
+				"Link":       link,
+				"MaxPlayers": strconv.FormatInt(bg.MaxPlayers, 10),
+			},
+		})
+
+		gameMsg, err := t.Bot.Send(chat, message, &telebot.SendOptions{ReplyTo: eventMsg, DisableWebPagePreview: true})
+		if err != nil {
+			log.Println("failed to dispatch add game message:", err)
+			continue
+		}
+
+		if err = t.DB.UpdateBoardGameMessageID(boardGameID, int64(gameMsg.ID)); err != nil {
+			log.Println("failed to update boardgame id:", err)
+		}
+	}
+
+	return nil
+}
+
+// auditSeries records a change made on behalf of the creator of the series.
+func (t Telegram) auditSeries(series models.Series, eventID string, action models.AuditAction, target, before, after string) {
+	entry := models.NewAuditEntry(eventID, series.UserID, series.UserName, models.SourceBot, action, target, before, after)
+	if err := t.DB.InsertAuditEntry(entry); err != nil {
+		log.Println("failed to write audit log:", err)
+	}
+}
+
+// inlineResultsSize is the number of games shown for an inline query.
+const inlineResultsSize = 10
+
+// InlineSearch answers the @bot queries with the matching games of
+// BoardGameGeek. The chosen game is posted in the chat with a link to its
+// page, InlineGameMessage then offers to add it to an event.
+func (t Telegram) InlineSearch(c telebot.Context) error {
+	query := strings.TrimSpace(c.Query().Text)
+	localizer := i18n.NewLocalizer(t.LanguageBundle, c.Sender().LanguageCode, "en")
+
+	results := telebot.Results{}
+	if len([]rune(query)) < 2 {
+		return c.Answer(&telebot.QueryResponse{Results: results, CacheTime: 60})
+	}
+
+	ctx := context.Background()
+	found, err := t.BGGCache.Search(ctx, query)
+	if err != nil {
+		log.Printf("Failed to search game %s: %v", query, err)
+		return c.Answer(&telebot.QueryResponse{Results: results, CacheTime: 10})
+	}
+
+	ids := []int64{}
+	for _, r := range found {
+		ids = append(ids, r.ID)
+		if len(ids) == inlineResultsSize {
+			break
+		}
+	}
+
+	things, err := t.BGGCache.GetThings(ctx, ids...)
+	if err != nil {
+		log.Printf("Failed to get games %v: %v", ids, err)
+	}
+
+	for _, thing := range things {
+		title := thing.Name
+		if thing.YearPublished > 0 {
+			title += fmt.Sprintf(" (%d)", thing.YearPublished)
+		}
+
+		description := localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "InlineGameDescription",
+			},
+			TemplateData: map[string]string{
+				"MinPlayers": strconv.Itoa(thing.MinPlayers),
+				"MaxPlayers": strconv.Itoa(thing.MaxPlayers),
+			},
+		})
+		if thing.PlayTime != "" && thing.PlayTime != "0" {
+			description += " · ⏱ " + thing.PlayTime + "'"
+		}
+
+		result := &telebot.ArticleResult{
+			Title:       title,
+			Description: description,
+			URL:         models.BGGUrl(thing.ID),
+			HideURL:     true,
+			ThumbURL:    thing.Thumbnail,
+		}
+		result.SetResultID(strconv.FormatInt(thing.ID, 10))
+		result.SetContent(&telebot.InputTextMessageContent{
+			Text:      fmt.Sprintf("🎲 <a href='%s'>%s</a>\n%s", models.BGGUrl(thing.ID), html.EscapeString(title), description),
+			ParseMode: telebot.ModeHTML,
+		})
+
+		results = append(results, result)
+	}
+
+	return c.Answer(&telebot.QueryResponse{Results: results, CacheTime: 300})
+}
+
+// ChosenInlineResult is received when a game of an inline query is posted,
+// its details are fetched right away so that adding it to an event is quick.
+func (t Telegram) ChosenInlineResult(c telebot.Context) error {
+	result := c.InlineResult()
+	log.Printf("User %d chose game %s for query %s", result.Sender.ID, result.ResultID, result.Query)
+
+	id, err := strconv.ParseInt(result.ResultID, 10, 64)
+	if err != nil {
+		log.Println("Invalid inline result:", result.ResultID)
+		return nil
+	}
+
+	if _, err = t.BGGCache.GetThings(context.Background(), id); err != nil {
+		log.Printf("Failed to get game %d: %v", id, err)
+	}
+
+	return nil
+}
+
+// IsInlineGameMessage reports whether the message was posted through an
+// inline query of the bot.
+func (t Telegram) IsInlineGameMessage(m *telebot.Message) bool {
+	return m.Via != nil && t.Bot.Me != nil && m.Via.ID == t.Bot.Me.ID
+}
+
+// InlineGameMessage replies to a game posted through an inline query with
+// one button per active event of the chat, to add the game in one tap.
+func (t Telegram) InlineGameMessage(c telebot.Context) error {
+	var bggID int64
+	for _, entity := range c.Message().Entities {
+		if id, ok := models.ExtractBoardGameID(entity.URL); ok {
+			bggID = id
+			break
+		}
+	}
+
+	if bggID == 0 || c.Chat().Type == telebot.ChatPrivate {
+		return nil
+	}
+
+	events, err := t.DB.SelectActiveEvents(c.Chat().ID)
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+	for _, event := range events {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   "➕ " + eventLabel(event),
+			Unique: string(models.AddBGGGame),
+			Data:   fmt.Sprintf("%d|%s", bggID, event.ID),
+		}})
+	}
+
+	_, err = t.Bot.Reply(c.Message(), t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "AddInlineGame"}}), markup)
+	return err
+}
+
+// CallbackAddBGGGame adds the game posted through an inline query to the
+// chosen event, the prompt is replaced by the message of the game.
+func (t Telegram) CallbackAddBGGGame(c telebot.Context) error {
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	bggID, err := strconv.ParseInt(parts[1], 10, 64)
+	prompt := c.Callback().Message
+	if err != nil || !models.IsValidUUID(parts[2]) || prompt == nil {
+		log.Println("Invalid parsed data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	event, err := t.DB.SelectEventByEventID(parts[2])
+	if err != nil || event.ChatID != c.Chat().ID {
+		log.Println("failed to load event:", err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}})})
+	}
+
+	if !event.IsActive() {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
+	if !t.canEdit(event, c.Sender().ID) {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocked"}})})
+	}
+
+	things, err := t.BGGCache.GetThings(context.Background(), bggID)
+	if err != nil || len(things) == 0 {
+		log.Printf("Failed to get game %d: %v", bggID, err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToGetGameInfo"}})})
+	}
+
+	thing := things[0]
+	log.Printf("Adding game %s (%d) from inline query to event %s", thing.Name, bggID, event.ID)
+
+	maxPlayers := thing.MaxPlayers
+	if maxPlayers <= 0 {
+		maxPlayers = 5
+	}
+
+	url := models.BGGUrl(bggID)
+	var imageUrl *string
+	if thing.Image != "" {
+		imageUrl = &thing.Image
+	}
+
+	replyTo := prompt
+	if prompt.ReplyTo != nil {
+		replyTo = prompt.ReplyTo
+	}
+
+	if err = t.insertGame(c, event, replyTo, thing.Name, maxPlayers, &bggID, &thing.Name, &url, imageUrl); err != nil {
+		return err
+	}
+
+	if err = t.Bot.Delete(prompt); err != nil {
+		log.Println("failed to delete message", err)
+	}
+
+	return c.Respond()
+}
+
+// sendBGGPicker asks which of the games of BoardGameGeek matching the name
+// is meant, the choice is handled by CallbackPickBGG. The picker replies to
+// the /add_game command, where the name is read back from.
+func (t Telegram) sendBGGPicker(c telebot.Context, event *models.Event, gameName string, matches []models.BGGMatch) error {
+	command := c.Message()
+	if c.Callback() != nil && command.ReplyTo != nil {
+		command = command.ReplyTo
+	}
+
+	matches = matches[:min(len(matches), models.BGGPickerSize)]
+	t.BGGCache.AddThumbnails(context.Background(), matches)
+
+	localizer := t.Localizer(c)
+	expansionT := localizer.MustLocalizeMessage(&i18n.Message{ID: "BGGExpansion"})
+
+	msg := ""
+	for _, m := range matches {
+		if m.Thumbnail != "" {
+			// the hidden link shows the thumbnail of the best match as preview
+			msg = fmt.Sprintf("<a href='%s'>\u200b</a>", m.Thumbnail)
+			break
+		}
+	}
+
+	msg += localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "PickBGGGame",
+		},
+		TemplateData: map[string]string{
+			"Name": html.EscapeString(gameName),
+		},
+	}) + "\n"
+
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{}
+
+	for i, m := range matches {
+		line := fmt.Sprintf("\n%d. <a href='%s'>%s</a>", i+1, models.BGGUrl(m.ID), html.EscapeString(m.Label()))
+		label := fmt.Sprintf("%d. %s", i+1, m.Label())
+		if m.Expansion {
+			line += " · <i>" + expansionT + "</i>"
+			label += " · " + expansionT
+		}
+		if len(m.Owners) > 0 {
+			line += " · 🏠 " + html.EscapeString(strings.Join(m.Owners, ", "))
+			label += " · 🏠"
+		}
+		msg += line
+
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+			Text:   label,
+			Unique: string(models.PickBGG),
+			Data:   fmt.Sprintf("%d|%s", m.ID, event.ID),
+		}})
+	}
+
+	markup.InlineKeyboard = append(markup.InlineKeyboard, []telebot.InlineButton{{
+		Text:   localizer.MustLocalizeMessage(&i18n.Message{ID: "NoBGGMatch"}),
+		Unique: string(models.PickBGG),
+		Data:   fmt.Sprintf("0|%s", event.ID),
+	}})
+
+	_, err := t.Bot.Reply(command, msg, markup)
+	return err
+}
+
+// CallbackPickBGG adds the game with the BoardGameGeek entry chosen in the
+// picker, or without any when none of them was right.
+func (t Telegram) CallbackPickBGG(c telebot.Context) error {
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	bggID, err := strconv.ParseInt(parts[1], 10, 64)
+	picker := c.Callback().Message
+	if err != nil || !models.IsValidUUID(parts[2]) || picker == nil || picker.ReplyTo == nil {
+		log.Println("Invalid parsed data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	if picker.ReplyTo.Sender == nil || picker.ReplyTo.Sender.ID != c.Sender().ID {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotYourGamePicker"}})})
+	}
+
+	_, args := parseCommand(picker.ReplyTo.Text)
+	_, args = splitEventCode(args)
+	gameName := strings.Join(args, " ")
+	if gameName == "" {
+		log.Println("Invalid picked command:", picker.ReplyTo.Text)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	event, err := t.DB.SelectEventByEventID(parts[2])
+	if err != nil || event.ChatID != c.Chat().ID {
+		log.Println("failed to load event:", err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}})})
+	}
+
+	if !event.IsActive() {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
+	if !t.canEdit(event, c.Sender().ID) {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventLocked"}})})
+	}
+
+	maxPlayers := 5
+	var bgID *int64
+	var bgName, bgUrl, bggImageUrl *string
+	label := gameName
+
+	if bggID != 0 {
+		ctx := context.Background()
+		match := models.BGGMatch{ID: bggID, Name: gameName}
+		matches, _, _ := t.gameMatches(ctx, event.ChatID, gameName)
+		for _, m := range matches {
+			if m.ID == bggID {
+				match = m
+			}
+		}
+
+		maxPlayers, bgID, bgName, bgUrl, bggImageUrl = t.matchInfo(ctx, match, maxPlayers)
+		label = match.Label()
+	}
+
+	log.Printf("User %d picked BoardGameGeek game %d for %s", c.Sender().ID, bggID, gameName)
+
+	if _, err = t.Bot.Edit(picker, "🎲 "+html.EscapeString(label), telebot.NoPreview); err != nil {
+		log.Println("failed to edit message", err)
+	}
+
+	if err = t.insertGame(c, event, picker.ReplyTo, gameName, maxPlayers, bgID, bgName, bgUrl, bggImageUrl); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// collectionTimeout bounds the fetch of a collection, BoardGameGeek queues
+// the big ones and asks to retry until they are ready.
+const collectionTimeout = 2 * time.Minute
+
+// LinkBGG links the BoardGameGeek account of the sender and imports the
+// games they own, /link_bgg off unlinks it.
+func (t Telegram) LinkBGG(c telebot.Context) error {
+	args := c.Args()
+	userID := c.Sender().ID
+
+	if len(args) == 0 {
+		account, err := t.DB.SelectBGGAccount(userID)
+		if err == nil {
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID: "BGGLinked",
+				},
+				TemplateData: map[string]string{
+					"Username": html.EscapeString(account.BGGUsername),
+				},
+			}))
+		}
+
+		if !errors.Is(err, database.ErrNoRows) {
+			log.Println("failed to load bgg account:", err)
+		}
+
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/link_bgg",
+				"Example": "username|off",
+			},
+		}))
+	}
+
+	if len(args) != 1 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "Usage",
+			},
+			TemplateData: map[string]string{
+				"Command": "/link_bgg",
+				"Example": "username|off",
+			},
+		}))
+	}
+
+	if args[0] == "off" {
+		if err := t.DB.UnlinkBGGAccount(userID); err != nil && !errors.Is(err, database.ErrNoRows) {
+			log.Println("failed to unlink bgg account:", err)
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToLinkBGG"}}))
+		}
+
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BGGUnlinked"}}))
+	}
+
+	account := models.BGGAccount{
+		UserID:      userID,
+		UserName:    DefineUsername(c.Sender()),
+		BGGUsername: args[0],
+	}
+
+	var chatID *int64
+	if c.Chat().Type != telebot.ChatPrivate {
+		chatID = &c.Chat().ID
+	}
+
+	if err := t.DB.LinkBGGAccount(account, chatID); err != nil {
+		log.Println("failed to link bgg account:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToLinkBGG"}}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectionTimeout)
+	defer cancel()
+
+	count, err := t.syncCollection(ctx, account)
+	if err != nil {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "CollectionNotImported",
+			},
+			TemplateData: map[string]string{
+				"Username": html.EscapeString(account.BGGUsername),
+			},
+		}))
+	}
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "CollectionImported",
+		},
+		TemplateData: map[string]string{
+			"Username": html.EscapeString(account.BGGUsername),
+			"Count":    strconv.Itoa(count),
+		},
+	}))
+}
+
+// SyncCollections fetches again the collections not refreshed in the given
+// interval.
+func (t Telegram) SyncCollections(refresh time.Duration) {
+	accounts, err := t.DB.SelectBGGAccountsToSync(time.Now().Add(-refresh))
+	if err != nil {
+		log.Println("failed to load bgg accounts:", err)
+		return
+	}
+
+	for _, account := range accounts {
+		ctx, cancel := context.WithTimeout(context.Background(), collectionTimeout)
+		if _, err = t.syncCollection(ctx, account); err != nil {
+			log.Printf("Collection of %s will be fetched again later", account.BGGUsername)
+		}
+		cancel()
+	}
+}
+
+// syncCollection replaces the stored collection of the account with the
+// games it owns on BoardGameGeek, returning how many they are.
+func (t Telegram) syncCollection(ctx context.Context, account models.BGGAccount) (int, error) {
+	items, err := t.BGG.GetCollection(ctx, account.BGGUsername, gobgg.SetCollectionTypes(gobgg.CollectionTypeOwn))
+	if err != nil {
+		log.Printf("Failed to get collection of %s: %v", account.BGGUsername, err)
+		return 0, err
+	}
+
+	games := make([]models.CollectionGame, len(items))
+	for i, item := range items {
+		games[i] = models.CollectionGame{
+			UserID: account.UserID,
+			BGGID:  item.ID,
+			Name:   item.Name,
+			Year:   item.YearPublished,
+		}
+	}
+
+	if err = t.DB.ReplaceCollection(account.UserID, games, time.Now()); err != nil {
+		log.Printf("Failed to store collection of %s: %v", account.BGGUsername, err)
+		return 0, err
+	}
+
+	log.Printf("Collection of %s imported: %d games", account.BGGUsername, len(games))
+
+	return len(games), nil
+}
+
+func (t Telegram) voteUsage(c telebot.Context) string {
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "Usage",
+		},
+		TemplateData: map[string]string{
+			"Command": "/vote",
+			"Example": fmt.Sprintf("%s 2 | %s | close", models.VoteApproval, models.VoteRanked),
+		},
+	})
+}
+
+// parseVoteArgs reads the method and the number of winners of /vote, both
+// optional and in any order. A zero number of winners means the default.
+func parseVoteArgs(args []string) (models.VoteMethod, int, bool) {
+	method, winners := models.VoteApproval, 0
+	if len(args) > 2 {
+		return "", 0, false
+	}
+
+	for _, arg := range args {
+		if m, ok := models.ParseVoteMethod(arg); ok {
+			method = m
+			continue
+		}
+
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return "", 0, false
+		}
+		winners = n
+	}
+
+	return method, winners, true
+}
+
+// isVoteClosing tells whether the arguments of /vote ask to close the vote.
+func isVoteClosing(args []string) bool {
+	return len(args) == 1 && strings.ToLower(args[0]) == "close"
+}
+
+// Vote starts the vote on the games of the event, shows it again when it is
+// already open, or closes it with /vote close.
+func (t Telegram) Vote(c telebot.Context) error {
+	code, args := splitEventCode(c.Args())
+
+	if _, _, ok := parseVoteArgs(args); !ok && !isVoteClosing(args) {
+		return c.Reply(t.voteUsage(c))
+	}
+
+	event, err := t.targetEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	return t.vote(c, event, args)
+}
+
+// vote runs /vote with its arguments on the event, for the command and for
+// the event picker.
+func (t Telegram) vote(c telebot.Context, event *models.Event, args []string) error {
+	if isVoteClosing(args) {
+		return t.closeVote(c, event)
+	}
+
+	method, winners, ok := parseVoteArgs(args)
+	if !ok {
+		return c.Reply(t.voteUsage(c))
+	}
+
+	if len(args) == 0 {
+		poll, err := t.DB.SelectPoll(event.ID)
+		if err != nil && !errors.Is(err, database.ErrNoRows) {
+			log.Println("failed to load poll:", err)
+			return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateVote"}}))
+		}
+
+		if err == nil && poll.IsOpen() {
+			return t.sendPoll(c, event, poll)
+		}
+	}
+
+	return t.startVote(c, event, method, winners)
+}
+
+func (t Telegram) startVote(c telebot.Context, event *models.Event, method models.VoteMethod, winners int) error {
+	userID := c.Sender().ID
+	log.Printf("User %d requested to start a %s vote on event %s", userID, method, event.ID)
+
+	if !t.canOrganize(event, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}}))
+	}
+
+	if !event.IsActive() {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}}))
+	}
+
+	games := event.VotableGames()
+	if len(games) < 2 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NotEnoughGamesToVote",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(event.Name),
+			},
+		}))
+	}
+
+	if winners == 0 {
+		winners = event.DefaultWinners()
+	}
+
+	poll := models.Poll{
+		EventID: event.ID,
+		Method:  method,
+		Winners: min(winners, len(games)),
+		Status:  models.PollOpen,
+		Votes:   []models.Vote{},
+	}
+
+	if err := t.DB.InsertPoll(poll); err != nil {
+		log.Println("failed to create poll:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateVote"}}))
+	}
+
+	t.audit(c, event.ID, models.AuditVoteStarted, "", "", string(method))
+
+	return t.sendPoll(c, event, &poll)
+}
+
+// sendPoll posts the vote with its buttons, the clicks edit this message
+// from then on.
+func (t Telegram) sendPoll(c telebot.Context, event *models.Event, poll *models.Poll) error {
+	body, markup := event.FormatPoll(t.Localizer(c), *poll)
+
+	msg, err := t.Bot.Reply(c.Message(), body, markup, telebot.NoPreview)
+	if err != nil {
+		log.Println("failed to send poll:", err)
+		return err
+	}
+
+	if err = t.DB.UpdatePollMessageID(event.ID, int64(msg.ID)); err != nil {
+		log.Println("failed to update poll message id:", err)
+	}
+
+	return nil
+}
+
+// refreshPollMessage queues the edit of the vote, the ballots are loaded
+// again right before the edit. The games are the ones of the given event, so
+// that a closed vote still shows the games that lost.
+func (t Telegram) refreshPollMessage(c telebot.Context, event *models.Event, poll *models.Poll) {
+	if poll.MessageID == nil {
+		return
+	}
+
+	localizer := t.Localizer(c)
+	t.Edits.Enqueue(event.ChatID, int(*poll.MessageID), func() (string, *telebot.ReplyMarkup, error) {
+		poll, err := t.DB.SelectPoll(event.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		body, markup := event.FormatPoll(localizer, *poll)
+
+		return body, markup, nil
+	})
+}
+
+// CallbackCastVote adds the game to the ballot of the user, or removes it
+// when it was already there. A zero game clears the ballot.
+func (t Telegram) CallbackCastVote(c telebot.Context) error {
+	var event *models.Event
+	var poll *models.Poll
+	var err error
+
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 3 {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	eventID := parts[1]
+	boardGameID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if !models.IsValidUUID(eventID) || err2 != nil {
+		log.Println("Invalid parsed id:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	userID := c.Sender().ID
+	userName := DefineUsername(c.Sender())
+
+	if event, err = t.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !event.IsActive() {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}})})
+	}
+
+	if poll, err = t.DB.SelectPoll(eventID); err != nil {
+		log.Println("failed to load poll:", err)
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "VoteIsClosed"}})})
+	}
+
+	ballot := []int64{}
+	if boardGameID != 0 {
+		if bg := event.BoardGame(boardGameID); bg == nil || !bg.IsGame() {
+			return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GameNotFound"}})})
+		}
+
+		ballot = models.ToggleBallot(poll.Ballot(userID), boardGameID)
+	}
+
+	log.Printf("User %s (%d) voted %v on event %s.", userName, userID, ballot, eventID)
+
+	if err = t.DB.ReplaceBallot(eventID, userID, userName, ballot); err != nil {
+		if errors.Is(err, database.ErrNoRows) {
+			return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "VoteIsClosed"}})})
+		}
+
+		log.Println("failed to update ballot:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateVote"}}))
+	}
+
+	if poll, err = t.DB.SelectPoll(eventID); err != nil {
+		log.Println("failed to load poll:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateVote"}}))
+	}
+
+	t.refreshPollMessage(c, event, poll)
+
+	return c.Respond(&telebot.CallbackResponse{Text: event.FormatBallot(t.Localizer(c), *poll, userID)})
+}
+
+func (t Telegram) CallbackCloseVote(c telebot.Context) error {
+	data := c.Callback().Data
+	parts := strings.Split(data, "|")
+	if len(parts) != 2 || !models.IsValidUUID(parts[1]) {
+		log.Println("Invalid data:", data)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidData"}}))
+	}
+
+	event, err := t.DB.SelectEventByEventID(parts[1])
+	if err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	if !t.canOrganize(event, c.Sender().ID) {
+		return c.Respond(&telebot.CallbackResponse{Text: t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}})})
+	}
+
+	if err = t.closeVote(c, event); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// closeVote closes the vote and removes from the event the games that lost,
+// the players who joined them are removed with them.
+func (t Telegram) closeVote(c telebot.Context, event *models.Event) error {
+	var poll *models.Poll
+	var err error
+
+	userID := c.Sender().ID
+	log.Printf("User %d requested to close the vote on event %s", userID, event.ID)
+
+	if !t.canOrganize(event, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}}))
+	}
+
+	if !event.IsActive() {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventClosed"}}))
+	}
+
+	if poll, err = t.DB.SelectPoll(event.ID); err != nil {
+		if !errors.Is(err, database.ErrNoRows) {
+			log.Println("failed to load poll:", err)
+		}
+
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "VoteNotFound",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(event.Name),
+			},
+		}))
+	}
+
+	closed, err := t.DB.ClosePoll(event.ID)
+	if err != nil {
+		log.Println("failed to close poll:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateVote"}}))
+	}
+
+	if !closed {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "VoteIsClosed"}}))
+	}
+
+	poll.Status = models.PollClosed
+
+	winners := []string{}
+	for _, r := range poll.Tally(event.VotableGames()) {
+		if r.Winner {
+			winners = append(winners, r.BoardGame.Name)
+			continue
+		}
+
+		if err = t.DB.DeleteBoardGameByID(r.BoardGame.ID); err != nil {
+			log.Println("failed to delete board game:", err)
+			continue
+		}
+
+		t.audit(c, event.ID, models.AuditGameDeleted, r.BoardGame.Name, "", "")
+	}
+
+	t.audit(c, event.ID, models.AuditVoteClosed, "", "", strings.Join(winners, ", "))
+
+	// the closed vote shows the results of every game, also the removed ones
+	t.refreshPollMessage(c, event, poll)
+
+	before := event
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.notifyPlayers(before, event, userID)
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "VoteResult",
+		},
+		TemplateData: map[string]string{
+			"Name":  html.EscapeString(event.Name),
+			"Games": html.EscapeString(strings.Join(winners, ", ")),
+		},
+	}))
+}
+
+// organizerTarget returns the user the /organizer command is about: the
+// author of the message it replies to, or the first user mentioned without a
+// username.
+func organizerTarget(m *telebot.Message) *telebot.User {
+	if m.ReplyTo != nil && m.ReplyTo.Sender != nil && !m.ReplyTo.Sender.IsBot {
+		return m.ReplyTo.Sender
+	}
+
+	for _, entity := range m.Entities {
+		if entity.Type == telebot.EntityTMention && entity.User != nil {
+			return entity.User
+		}
+	}
+
+	return nil
+}
+
+// Organizer names the co-organizers of an event, who can change it like its
+// creator. Reply to a message of the user with /organizer to add them, with
+// /organizer remove to remove them, /organizer alone lists them.
+func (t Telegram) Organizer(c telebot.Context) error {
+	code, args := splitEventCode(c.Args())
+	removing := len(args) > 0 && strings.ToLower(args[0]) == "remove"
+
+	target := organizerTarget(c.Message())
+	if target == nil {
+		event, err := t.targetEvent(c, code)
+		if err != nil {
+			return t.replyTargetError(c, err)
+		}
+
+		return t.listOrganizers(c, event)
+	}
+
+	// the reply points to the user, so the event is the only active one or
+	// the one with the given code. The picker is not sent, its answer would
+	// no longer know the user.
+	event, events, err := t.findEvent(c, code)
+	if err != nil {
+		return t.replyTargetError(c, err)
+	}
+
+	if event == nil && len(events) > 0 {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OrganizerNeedsCode"}}))
+	}
+
+	userID := c.Sender().ID
+	log.Printf("User %d requested to change the co-organizers of event %s", userID, event.ID)
+
+	if event.UserID != userID && !t.Admins.IsAdmin(event.ChatID, userID) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "NotEventOwner"}}))
+	}
+
+	targetName := DefineUsername(target)
+	messageID, action := "OrganizerAdded", models.AuditOrganizerAdded
+	if removing {
+		messageID, action = "OrganizerRemoved", models.AuditOrganizerRemoved
+		err = t.DB.DeleteEventOrganizer(event.ID, target.ID)
+	} else {
+		err = t.DB.InsertEventOrganizer(event.ID, target.ID, targetName)
+	}
+
+	if errors.Is(err, database.ErrNoRows) {
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NotAnOrganizer",
+			},
+			TemplateData: map[string]string{
+				"Name": html.EscapeString(targetName),
+			},
+		}))
+	}
+
+	if err != nil {
+		log.Println("failed to update co-organizers:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FailedToUpdateEvent"}}))
+	}
+
+	t.audit(c, event.ID, action, targetName, "", "")
+
+	if event, err = t.DB.SelectEventByEventID(event.ID); err != nil {
+		log.Println("failed to load event:", err)
+		return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EventNotFound"}}))
+	}
+
+	t.refreshEventMessage(event)
+
+	return c.Reply(t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: messageID,
+		},
+		TemplateData: map[string]string{
+			"Name":  html.EscapeString(targetName),
+			"Event": html.EscapeString(event.Name),
+		},
+	}))
+}
+
+// listOrganizers replies with the co-organizers of the event and how to
+// change them.
+func (t Telegram) listOrganizers(c telebot.Context, event *models.Event) error {
+	return c.Reply(t.formatOrganizers(c, event) + "\n\n" + t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OrganizerUsage"}}))
+}
+
+func (t Telegram) formatOrganizers(c telebot.Context, event *models.Event) string {
+	if len(event.Organizers) == 0 {
+		return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NoOrganizers",
+			},
+			TemplateData: map[string]string{
+				"Event": html.EscapeString(event.Name),
+			},
+		})
+	}
+
+	names := []string{}
+	for _, o := range event.Organizers {
+		names = append(names, html.EscapeString(o.UserName))
+	}
+
+	return t.Localizer(c).MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID: "OrganizersList",
+		},
+		TemplateData: map[string]string{
+			"Event": html.EscapeString(event.Name),
+			"Names": strings.Join(names, ", "),
+		},
+	})
 }
@@ -4,10 +4,11 @@ import (
 	"boardgame-night-bot/src/database"
 	"boardgame-night-bot/src/models"
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,23 +21,31 @@ import (
 
 type Controller struct {
 	Router         *gin.RouterGroup
-	DB             *database.Database
+	DB             database.Store
 	BGG            *gobgg.BGG
+	BGGCache       *models.BGGCache
+	Admins         *models.AdminCache
 	Bot            *telebot.Bot
+	Edits          *models.EditQueue
 	LanguageBundle *i18n.Bundle
 	BaseUrl        string
 	BotName        string
+	WebhookSecret  string
 }
 
-func NewController(router *gin.RouterGroup, db *database.Database, bgg *gobgg.BGG, bot *telebot.Bot, LanguageBundle *i18n.Bundle, baseUrl, botName string) *Controller {
+func NewController(router *gin.RouterGroup, db database.Store, bgg *gobgg.BGG, bggCache *models.BGGCache, admins *models.AdminCache, bot *telebot.Bot, edits *models.EditQueue, LanguageBundle *i18n.Bundle, baseUrl, botName, webhookSecret string) *Controller {
 	return &Controller{
 		Router:         router,
 		DB:             db,
 		BGG:            bgg,
+		BGGCache:       bggCache,
+		Admins:         admins,
 		Bot:            bot,
+		Edits:          edits,
 		LanguageBundle: LanguageBundle,
 		BaseUrl:        baseUrl,
 		BotName:        botName,
+		WebhookSecret:  webhookSecret,
 	}
 }
 
@@ -51,11 +60,41 @@ func (t Controller) Localizer(chatID *int64) *i18n.Localizer {
 func (c *Controller) InjectRoute() {
 	c.Router.GET("/", c.Index)
 	c.Router.GET("/events/:event_id", c.Event)
+	c.Router.POST("/events/:event_id", c.UpdateEvent)
+	c.Router.POST("/events/:event_id/status", c.UpdateEventStatus)
 	c.Router.GET("/events/:event_id/games/:game_id", c.Game)
 	c.Router.POST("/events/:event_id/games/:game_id", c.UpdateGame)
 	c.Router.DELETE("/events/:event_id/games/:game_id", c.DeleteGame)
+	c.Router.POST("/events/:event_id/games/:game_id/result", c.RecordResult)
 	c.Router.POST("/events/:event_id/add-game", c.AddGame)
 	c.Router.POST("/events/:event_id/join", c.AddPlayer)
+	c.Router.POST("/events/:event_id/leave", c.LeaveGame)
+	c.Router.POST("/events/:event_id/bring", c.BringGame)
+	c.Router.POST("/events/:event_id/vote", c.CastVote)
+	c.Router.POST("/events/:event_id/vote/close", c.CloseVote)
+	c.Router.GET("/events/:event_id/log", c.AuditLog)
+	c.Router.GET("/events/:event_id/leaderboard", c.Leaderboard)
+}
+
+// Webhook passes the updates sent by Telegram to the bot, the requests
+// without the secret token given to setWebhook are refused.
+func (c *Controller) Webhook(ctx *gin.Context) {
+	token := ctx.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if c.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.WebhookSecret)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid secret token"})
+		return
+	}
+
+	var update telebot.Update
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		log.Println("failed to bind update:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid update"})
+		return
+	}
+
+	c.Bot.Updates <- update
+
+	ctx.Status(http.StatusOK)
 }
 
 func (c *Controller) Index(ctx *gin.Context) {
@@ -114,29 +153,334 @@ func (c *Controller) Event(ctx *gin.Context) {
 		},
 	})
 
-	for i, bg := range event.BoardGames {
-		if bg.Name == models.PLAYER_COUNTER {
-			event.BoardGames[i].Name = localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
+	var poll *models.Poll
+	pollResults := []models.VoteResult{}
+	pollVoters := map[int64]string{}
+	pollMethod := ""
+	if poll, err = c.DB.SelectPoll(event.ID); err == nil {
+		pollResults = poll.Tally(event.VotableGames())
+		pollMethod = localizer.MustLocalizeMessage(&i18n.Message{ID: models.VoteMethodMessageID(poll.Method)})
+
+		voters := map[int64][]string{}
+		for _, v := range poll.Votes {
+			voters[v.BoardGameID] = append(voters[v.BoardGameID], strconv.FormatInt(v.UserID, 10))
+		}
+		for id, ids := range voters {
+			pollVoters[id] = strings.Join(ids, ",")
+		}
+	} else if !errors.Is(err, database.ErrNoRows) {
+		log.Println("failed to load poll:", err)
+	}
+
+	// the names of the games owned in the chat are suggested in the add game form
+	collection := []string{}
+	if games, err := c.DB.SelectChatCollection(event.ChatID); err != nil {
+		log.Println("failed to load collections:", err)
+	} else {
+		seen := map[string]bool{}
+		for _, g := range games {
+			if !seen[g.Name] {
+				seen[g.Name] = true
+				collection = append(collection, g.Name)
+			}
 		}
 	}
 
+	startsAt, endsAt := "", ""
+	if event.StartsAt != nil {
+		startsAt = event.StartsAt.Local().Format("2006-01-02T15:04")
+	}
+	if event.EndsAt != nil {
+		endsAt = event.EndsAt.Local().Format("2006-01-02T15:04")
+	}
+
 	// serve an html file
 	ctx.HTML(http.StatusOK, "event", gin.H{
 		"Id":             event.ID,
 		"Title":          event.Name,
+		"When":           event.FormatWhen(),
+		"Banner":         event.Banner(localizer),
+		"Status":         string(event.Status),
+		"Active":         event.IsActive(),
+		"CreatorID":      event.UserID,
+		"Location":       event.Location,
+		"StartsAtValue":  startsAt,
+		"EndsAtValue":    endsAt,
 		"Games":          event.BoardGames,
+		"JoinEvent":      localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"}),
+		"Collection":     collection,
 		"UpdatedAt":      timeT,
 		"NoParticipants": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebNoParticipants"}),
 		"Players":        localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlayers"}),
 		"Join":           localizer.MustLocalizeMessage(&i18n.Message{ID: "WebJoin"}),
+		"Leave":          localizer.MustLocalizeMessage(&i18n.Message{ID: "WebLeave"}),
+		"JoinMode":       string(event.JoinMode),
+		"JoinModeLabel":  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebJoinMode"}),
+		"JoinModeSingle": localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinModeSingle"}),
+		"JoinModeMulti":  localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinModeMultiple"}),
+		"JoinWaitlist":   localizer.MustLocalizeMessage(&i18n.Message{ID: "WebJoinWaitlist"}),
+		"Waitlist":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebWaitlist"}),
 		"AddGame":        localizer.MustLocalizeMessage(&i18n.Message{ID: "WebAddGame"}),
 		"Welcome":        localizer.MustLocalizeMessage(&i18n.Message{ID: "WebWelcome"}),
 		"AddNewGame":     localizer.MustLocalizeMessage(&i18n.Message{ID: "WebAddNewGame"}),
 		"GameName":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebGameName"}),
 		"MaxPlayers":     localizer.MustLocalizeMessage(&i18n.Message{ID: "WebMaxPlayers"}),
+		"EventDetails":   localizer.MustLocalizeMessage(&i18n.Message{ID: "WebEventDetails"}),
+		"StartsAt":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebStartsAt"}),
+		"EndsAt":         localizer.MustLocalizeMessage(&i18n.Message{ID: "WebEndsAt"}),
+		"LocationLabel":  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebLocation"}),
+		"Save":           localizer.MustLocalizeMessage(&i18n.Message{ID: "WebSave"}),
+		"Lock":           localizer.MustLocalizeMessage(&i18n.Message{ID: "WebLock"}),
+		"Unlock":         localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUnlock"}),
+		"Close":          localizer.MustLocalizeMessage(&i18n.Message{ID: "WebClose"}),
+		"CancelEvent":    localizer.MustLocalizeMessage(&i18n.Message{ID: "WebCancelEvent"}),
+		"AuditLog":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebAuditLog"}),
+		"Leaderboard":    localizer.MustLocalizeMessage(&i18n.Message{ID: "WebLeaderboard"}),
+		"OwnedBy":        localizer.MustLocalizeMessage(&i18n.Message{ID: "WebOwnedBy"}),
+		"BroughtBy":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebBroughtBy"}),
+		"NobodyBrings":   localizer.MustLocalizeMessage(&i18n.Message{ID: "NobodyBrings"}),
+		"BringIt":        localizer.MustLocalizeMessage(&i18n.Message{ID: "BringIt"}),
+		"Poll":           poll,
+		"PollResults":    pollResults,
+		"PollVoters":     pollVoters,
+		"PollMethod":     pollMethod,
+		"VoteTitle":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebVoteTitle"}),
+		"ClearVote":      localizer.MustLocalizeMessage(&i18n.Message{ID: "ClearVote"}),
+		"CloseVote":      localizer.MustLocalizeMessage(&i18n.Message{ID: "CloseVote"}),
+		"VoteClosed":     localizer.MustLocalizeMessage(&i18n.Message{ID: "VoteClosed"}),
+	})
+}
+
+func (c *Controller) AuditLog(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load game:", err)
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var entries []models.AuditEntry
+
+	if entries, err = c.DB.SelectAuditLog(event.ID, models.AuditLogSize); err != nil {
+		log.Println("failed to load audit log:", err)
+		c.renderError(ctx, &event.ID, &event.ChatID, "Failed to load the audit log")
+		return
+	}
+
+	localizer := c.Localizer(&event.ChatID)
+
+	lines := []string{}
+	for _, entry := range entries {
+		lines = append(lines, entry.Format(localizer))
+	}
+
+	ctx.HTML(http.StatusOK, "log", gin.H{
+		"Id":       event.ID,
+		"Title":    event.Name,
+		"Entries":  lines,
+		"AuditLog": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebAuditLog"}),
+		"NoEntries": localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "NoAuditEntries",
+			},
+			TemplateData: map[string]string{
+				"Name": event.Name,
+			},
+		}),
+	})
+}
+
+// Leaderboard shows the ratings of the players of the chat of the event,
+// overall and at each game played.
+func (c *Controller) Leaderboard(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load game:", err)
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var plays []models.Play
+
+	if plays, err = c.DB.SelectChatPlays(event.ChatID); err != nil {
+		log.Println("failed to load plays:", err)
+		c.renderError(ctx, &event.ID, &event.ChatID, "Failed to load the leaderboard")
+		return
+	}
+
+	localizer := c.Localizer(&event.ChatID)
+
+	ctx.HTML(http.StatusOK, "leaderboard", gin.H{
+		"Id":          event.ID,
+		"Title":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebLeaderboard"}),
+		"Overall":     models.ComputeRatings(plays),
+		"Games":       models.ComputeGameRatings(plays),
+		"OverallT":    localizer.MustLocalizeMessage(&i18n.Message{ID: "WebOverall"}),
+		"Player":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlayer"}),
+		"Rating":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebRating"}),
+		"Plays":       localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlays"}),
+		"Wins":        localizer.MustLocalizeMessage(&i18n.Message{ID: "WebWins"}),
+		"RatingsHelp": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebRatingsHelp"}),
+		"NoPlays":     localizer.MustLocalizeMessage(&i18n.Message{ID: "NoPlays"}),
 	})
 }
 
+func (c *Controller) UpdateEventStatus(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.UpdateEventStatusRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil || !req.Status.IsValid() {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !c.canOrganize(event, req.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the organizers can change the event status"})
+		return
+	}
+
+	if !event.Status.CanTransitionTo(req.Status) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot change status from %s to %s", event.Status, req.Status)})
+		return
+	}
+
+	if err = c.DB.UpdateEventStatus(event.ID, req.Status); err != nil {
+		log.Println("failed to update event status:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		return
+	}
+
+	c.audit(models.NewAuditEntry(event.ID, req.UserID, req.UserName, models.SourceWeb, models.AuditStatusChanged, "", string(event.Status), string(req.Status)))
+
+	if _, err = c.updateTelegram(ctx, eventID); err != nil {
+		log.Println("failed to update telegram", err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Event updated.", "status": req.Status})
+}
+
+func (c *Controller) UpdateEvent(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load game:", err)
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var req models.UpdateEventRequest
+	if err = ctx.ShouldBind(&req); err != nil {
+		log.Println("failed to bind form:", err)
+		c.renderError(ctx, &event.ID, &event.ChatID, "Invalid submitted form data")
+		return
+	}
+
+	if !c.canEdit(event, req.UserID) {
+		log.Println("event is locked or closed")
+		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to update locked or closed event")
+		return
+	}
+
+	var startsAt, endsAt *time.Time
+	if req.StartsAt != "" {
+		if startsAt, err = models.ParseEventTime(req.StartsAt); err != nil {
+			c.renderError(ctx, &event.ID, &event.ChatID, "Invalid start date")
+			return
+		}
+	}
+
+	if req.EndsAt != "" {
+		if endsAt, err = models.ParseEventTime(req.EndsAt); err != nil || startsAt == nil || endsAt.Before(*startsAt) {
+			c.renderError(ctx, &event.ID, &event.ChatID, "Invalid end date")
+			return
+		}
+	}
+
+	var location *string
+	if l := strings.TrimSpace(req.Location); l != "" {
+		location = &l
+	}
+
+	if req.JoinMode != "" && req.JoinMode != event.JoinMode {
+		if !req.JoinMode.IsValid() {
+			c.renderError(ctx, &event.ID, &event.ChatID, "Invalid join mode")
+			return
+		}
+
+		if !c.canOrganize(event, req.UserID) {
+			c.renderError(ctx, &event.ID, &event.ChatID, "Only the organizers can change the join mode")
+			return
+		}
+
+		if err = c.DB.UpdateEventJoinMode(event.ID, req.JoinMode); err != nil {
+			log.Println("failed to update event join mode:", err)
+			c.renderError(ctx, &event.ID, &event.ChatID, "Failed to update event")
+			return
+		}
+
+		c.audit(models.NewAuditEntry(event.ID, req.UserID, req.UserName, models.SourceWeb, models.AuditJoinModeChanged, "", string(event.JoinMode), string(req.JoinMode)))
+	}
+
+	if err = c.DB.UpdateEventDetails(event.ID, startsAt, endsAt, location); err != nil {
+		log.Println("failed to update event:", err)
+		c.renderError(ctx, &event.ID, &event.ChatID, "Failed to update event")
+		return
+	}
+
+	updated := *event
+	updated.StartsAt, updated.EndsAt, updated.Location = startsAt, endsAt, location
+	if before, after := formatDetails(*event), formatDetails(updated); before != after {
+		c.audit(models.NewAuditEntry(event.ID, req.UserID, req.UserName, models.SourceWeb, models.AuditEventUpdated, "", before, after))
+	}
+
+	if _, err = c.updateTelegram(ctx, eventID); err != nil {
+		log.Println("failed to update telegram", err)
+	}
+
+	ctx.Redirect(http.StatusFound, fmt.Sprintf("/events/%s", eventID))
+}
+
 func (c *Controller) Game(ctx *gin.Context) {
 	var err error
 	eventID := ctx.Param("event_id")
@@ -160,8 +504,6 @@ func (c *Controller) Game(ctx *gin.Context) {
 		return
 	}
 
-	localizer := c.Localizer(&event.ChatID)
-
 	for _, g := range event.BoardGames {
 		if g.ID == gameID {
 			game = &g
@@ -169,25 +511,12 @@ func (c *Controller) Game(ctx *gin.Context) {
 		}
 	}
 
-	if game.Name == models.PLAYER_COUNTER {
-		game.Name = localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
+	if game == nil {
+		c.renderError(ctx, &event.ID, &event.ChatID, "Invalid game ID")
+		return
 	}
 
-	ctx.HTML(http.StatusOK, "game_info", gin.H{
-		"Id":                      event.ID,
-		"Title":                   event.Name,
-		"Game":                    game,
-		"NoParticipants":          localizer.MustLocalizeMessage(&i18n.Message{ID: "WebNoParticipants"}),
-		"Players":                 localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlayers"}),
-		"MaxPlayers":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebMaxPlayers"}),
-		"UpdateGame":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUpdateGame"}),
-		"Update":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "Update"}),
-		"UnlinkFormBoardGameGeek": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUnlinkFormBoardGameGeek"}),
-		"GameDeletedSuccessfully": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebGameDeletedSuccessfully"}),
-		"DeleteGameConfirmation":  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDeleteGameConfirmation"}),
-		"FailedToDeleteGame":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebFailedToDeleteGame"}),
-		"Delete":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDelete"}),
-	})
+	c.renderGame(ctx, event, game)
 }
 
 func (c *Controller) UpdateGame(ctx *gin.Context) {
@@ -220,9 +549,9 @@ func (c *Controller) UpdateGame(ctx *gin.Context) {
 		return
 	}
 
-	if event.Locked && event.UserID != bg.UserID {
-		log.Println("event is locked")
-		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to add game to locked event")
+	if !c.canEdit(event, bg.UserID) {
+		log.Println("event is locked or closed")
+		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to add game to locked or closed event")
 		return
 	}
 
@@ -275,10 +604,28 @@ func (c *Controller) UpdateGame(ctx *gin.Context) {
 		return
 	}
 
+	if int64(maxPlayers) != game.MaxPlayers {
+		c.audit(models.NewAuditEntry(eventID, bg.UserID, bg.UserName, models.SourceWeb, models.AuditMaxPlayersChanged, game.Name, strconv.FormatInt(game.MaxPlayers, 10), strconv.Itoa(maxPlayers)))
+	}
+
+	if previous, current := valueOf(game.BggUrl), valueOf(bgUrl); previous != current {
+		action := models.AuditBGGLinked
+		if current == "" {
+			action = models.AuditBGGUnlinked
+		}
+
+		c.audit(models.NewAuditEntry(eventID, bg.UserID, bg.UserName, models.SourceWeb, action, game.Name, previous, current))
+	}
+
+	before := event
 	if event, err = c.updateTelegram(ctx, eventID); err != nil {
 		log.Println("failed to update telegram", err)
+		return
 	}
 
+	c.notifyPromotions(before, event)
+	c.notifyPlayers(before, event, bg.UserID)
+
 	for _, g := range event.BoardGames {
 		if g.ID == gameID {
 			game = &g
@@ -286,8 +633,22 @@ func (c *Controller) UpdateGame(ctx *gin.Context) {
 		}
 	}
 
+	c.renderGame(ctx, event, game)
+}
+
+// renderGame shows the page of a game, with the form recording its result
+// once it has players.
+func (c *Controller) renderGame(ctx *gin.Context, event *models.Event, game *models.BoardGame) {
 	localizer := c.Localizer(&event.ChatID)
-	if game.Name == models.PLAYER_COUNTER {
+
+	var play *models.Play
+	var err error
+	if play, err = c.DB.SelectPlay(game.ID); err != nil && !errors.Is(err, database.ErrNoRows) {
+		log.Println("failed to load play:", err)
+	}
+
+	isGame := game.IsGame()
+	if !isGame {
 		game.Name = localizer.MustLocalizeMessage(&i18n.Message{ID: "JoinEvent"})
 	}
 
@@ -295,9 +656,13 @@ func (c *Controller) UpdateGame(ctx *gin.Context) {
 		"Id":                      event.ID,
 		"Title":                   event.Name,
 		"Game":                    game,
+		"IsGame":                  isGame,
+		"Play":                    play,
+		"ResultScores":            game.ResultScores(play),
 		"NoParticipants":          localizer.MustLocalizeMessage(&i18n.Message{ID: "WebNoParticipants"}),
 		"Players":                 localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlayers"}),
 		"MaxPlayers":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebMaxPlayers"}),
+		"Waitlist":                localizer.MustLocalizeMessage(&i18n.Message{ID: "WebWaitlist"}),
 		"UpdateGame":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUpdateGame"}),
 		"Update":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "Update"}),
 		"UnlinkFormBoardGameGeek": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUnlinkFormBoardGameGeek"}),
@@ -305,6 +670,15 @@ func (c *Controller) UpdateGame(ctx *gin.Context) {
 		"DeleteGameConfirmation":  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDeleteGameConfirmation"}),
 		"FailedToDeleteGame":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebFailedToDeleteGame"}),
 		"Delete":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDelete"}),
+		"Result":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebResult"}),
+		"RecordResult":            localizer.MustLocalizeMessage(&i18n.Message{ID: "WebRecordResult"}),
+		"ResultHelp":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebResultHelp"}),
+		"Winner":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebWinner"}),
+		"Score":                   localizer.MustLocalizeMessage(&i18n.Message{ID: "WebScore"}),
+		"DurationMinutes":         localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDurationMinutes"}),
+		"Notes":                   localizer.MustLocalizeMessage(&i18n.Message{ID: "ResultNotes"}),
+		"Save":                    localizer.MustLocalizeMessage(&i18n.Message{ID: "WebSave"}),
+		"FailedToRecordResult":    localizer.MustLocalizeMessage(&i18n.Message{ID: "FailedToRecordResult"}),
 	})
 }
 
@@ -338,9 +712,9 @@ func (c *Controller) DeleteGame(ctx *gin.Context) {
 		return
 	}
 
-	if event.Locked && event.UserID != userID {
-		log.Println("event is locked")
-		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to delete game to locked event")
+	if !c.canEdit(event, userID) {
+		log.Println("event is locked or closed")
+		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to delete game from locked or closed event")
 		return
 	}
 
@@ -362,6 +736,8 @@ func (c *Controller) DeleteGame(ctx *gin.Context) {
 		return
 	}
 
+	c.audit(models.NewAuditEntry(event.ID, userID, username, models.SourceWeb, models.AuditGameDeleted, game.Name, "", ""))
+
 	to := &telebot.Chat{
 		ID: event.ChatID,
 	}
@@ -385,155 +761,280 @@ func (c *Controller) DeleteGame(ctx *gin.Context) {
 		log.Println("failed to send message:", err)
 	}
 
-	if _, err = c.updateTelegram(ctx, eventID); err != nil {
+	before := event
+	if event, err = c.updateTelegram(ctx, eventID); err != nil {
 		log.Println("failed to update telegram", err)
+	} else {
+		c.notifyPlayers(before, event, userID)
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Game deleted."})
 }
 
-func (c *Controller) AddGame(ctx *gin.Context) {
+// RecordResult stores the result of a game sent by the form of its page, and
+// posts it in the chat of the event.
+func (c *Controller) RecordResult(ctx *gin.Context) {
 	var err error
 	eventID := ctx.Param("event_id")
+	gameID, err2 := strconv.ParseInt(ctx.Param("game_id"), 10, 64)
 
-	if !models.IsValidUUID(eventID) {
-		c.renderError(ctx, nil, nil, "Invalid event ID")
+	if !models.IsValidUUID(eventID) || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event or game ID"})
+		return
+	}
+
+	var req models.ResultRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
 		return
 	}
 
 	var event *models.Event
 
 	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
-		log.Println("failed to load game:", err)
-		c.renderError(ctx, nil, nil, "Invalid event ID")
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
-	var bg models.AddGameRequest
-	if err = ctx.ShouldBind(&bg); err != nil {
-		log.Println("failed to bind form:", err)
-		c.renderError(ctx, &event.ID, &event.ChatID, "Invalid submitted form data")
+	game := event.BoardGame(gameID)
+	if game == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
 		return
 	}
 
-	if event.Locked && event.UserID != bg.UserID {
-		log.Println("event is locked")
-		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to add game to locked event")
+	if !c.canRecordResult(event, game, req.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the players can record the result"})
 		return
 	}
 
-	if bg.MaxPlayers == nil {
-		defaultMax := 5
-		bg.MaxPlayers = &defaultMax
+	if req.DurationMinutes != nil && *req.DurationMinutes <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration"})
+		return
 	}
 
-	bgCtx := context.Background()
-
-	var bgID *int64
-	var bgName, bgUrl, bgImageUrl *string
-	if bg.BggUrl != nil && *bg.BggUrl != "" {
-		var valid bool
-		var id int64
-		if id, valid = models.ExtractBoardGameID(*bg.BggUrl); !valid {
-			c.renderError(ctx, &event.ID, &event.ChatID, "Invalid bgg url")
-
+	scores := []models.PlayScore{}
+	for _, s := range req.Scores {
+		player := event.Participant(s.UserID)
+		if player == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player"})
 			return
 		}
 
-		var bgMaxPlayers *int
+		s.UserName = player.UserName
+		scores = append(scores, s)
+	}
 
-		if bgMaxPlayers, bgName, bgUrl, bgImageUrl, err = models.ExtractGameInfo(bgCtx, c.BGG, id, bg.Name); err != nil {
-			log.Printf("Failed to get game %d: %v", id, err)
-		} else {
-			bgID = &id
-			bg.MaxPlayers = bgMaxPlayers
-		}
-	} else {
-		log.Printf("Searching for game %s", bg.Name)
-		var results []gobgg.SearchResult
+	if len(scores) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No players"})
+		return
+	}
 
-		if results, err = c.BGG.Search(bgCtx, bg.Name); err != nil {
-			log.Printf("Failed to search game %s: %v", bg.Name, err)
-		}
+	play := models.NewPlay(event, game, req.UserID, req.UserName, time.Now())
+	play.DurationMinutes = req.DurationMinutes
+	if req.Notes != nil && strings.TrimSpace(*req.Notes) != "" {
+		notes := strings.TrimSpace(*req.Notes)
+		play.Notes = &notes
+	}
+	play.Scores = models.MarkWinners(scores)
+	play.SortScores()
 
-		if len(results) == 0 {
-			log.Printf("Game %s not found", bg.Name)
-		} else {
-			sort.Slice(results, func(i, j int) bool {
-				return results[i].ID < results[j].ID
-			})
+	if _, err = c.DB.InsertPlay(play); err != nil {
+		log.Println("failed to insert play:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record the result"})
+		return
+	}
 
-			url := fmt.Sprintf("https://boardgamegeek.com/boardgame/%d", results[0].ID)
-			bgUrl = &url
-			if results[0].Name != "" {
-				bgName = &results[0].Name
-			}
+	c.audit(models.NewAuditEntry(eventID, req.UserID, req.UserName, models.SourceWeb, models.AuditResultRecorded, game.Name, "", strings.Join(play.Winners(), ", ")))
 
-			bgID = &results[0].ID
+	chat := &telebot.Chat{ID: event.ChatID}
+	opts := &telebot.SendOptions{ParseMode: telebot.ModeHTML, DisableWebPagePreview: true}
+	if event.MessageID != nil {
+		opts.ReplyTo = &telebot.Message{ID: int(*event.MessageID), Chat: chat}
+	}
 
-			log.Printf("Game %s id %d found: %s", bg.Name, *bgID, *bgUrl)
+	if _, err = c.Bot.Send(chat, play.FormatPlay(c.Localizer(&event.ChatID), event.Name), opts); err != nil {
+		log.Println("failed to send result:", err)
+	}
 
-			var things []gobgg.ThingResult
+	ctx.JSON(http.StatusOK, gin.H{"message": "Result recorded.", "winners": play.Winners()})
+}
 
-			if things, err = c.BGG.GetThings(bgCtx, gobgg.GetThingIDs(*bgID)); err != nil {
-				log.Printf("Failed to get game %s: %v", bg.Name, err)
-			}
+// renderGamePicker asks which of the games of BoardGameGeek matching the name
+// is meant, each choice submits the add game form again with its url.
+func (c *Controller) renderGamePicker(ctx *gin.Context, event *models.Event, bg models.AddGameRequest, matches []models.BGGMatch) {
+	matches = matches[:min(len(matches), models.BGGPickerSize)]
+	c.BGGCache.AddThumbnails(context.Background(), matches)
 
-			if len(things) > 0 {
-				log.Printf("Game details of %s found", bg.Name)
-				if things[0].MaxPlayers > 0 {
-					bg.MaxPlayers = &things[0].MaxPlayers
-				}
-
-				if things[0].Name != "" {
-					bgName = &things[0].Name
-				} else {
-					bgName = &bg.Name
-				}
-				if things[0].Image != "" {
-					bgImageUrl = &things[0].Image
-				}
+	type pickerMatch struct {
+		models.BGGMatch
+		Url string
+	}
+
+	choices := make([]pickerMatch, len(matches))
+	for i, m := range matches {
+		choices[i] = pickerMatch{BGGMatch: m, Url: models.BGGUrl(m.ID)}
+	}
+
+	localizer := c.Localizer(&event.ChatID)
+
+	ctx.HTML(http.StatusOK, "game_picker", gin.H{
+		"Id":         event.ID,
+		"Name":       bg.Name,
+		"MaxPlayers": bg.MaxPlayers,
+		"UserID":     bg.UserID,
+		"UserName":   valueOf(bg.UserName),
+		"Matches":    choices,
+		"PickTitle": localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID: "WebPickBGGGame",
+			},
+			TemplateData: map[string]string{
+				"Name": bg.Name,
+			},
+		}),
+		"Expansion":  localizer.MustLocalizeMessage(&i18n.Message{ID: "BGGExpansion"}),
+		"OwnedBy":    localizer.MustLocalizeMessage(&i18n.Message{ID: "WebOwnedBy"}),
+		"Choose":     localizer.MustLocalizeMessage(&i18n.Message{ID: "WebChoose"}),
+		"NoBGGMatch": localizer.MustLocalizeMessage(&i18n.Message{ID: "NoBGGMatch"}),
+	})
+}
+
+func (c *Controller) AddGame(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load game:", err)
+		c.renderError(ctx, nil, nil, "Invalid event ID")
+		return
+	}
+
+	var bg models.AddGameRequest
+	if err = ctx.ShouldBind(&bg); err != nil {
+		log.Println("failed to bind form:", err)
+		c.renderError(ctx, &event.ID, &event.ChatID, "Invalid submitted form data")
+		return
+	}
+
+	if !c.canEdit(event, bg.UserID) {
+		log.Println("event is locked or closed")
+		c.renderError(ctx, &event.ID, &event.ChatID, "Unable to add game to locked or closed event")
+		return
+	}
+
+	if bg.MaxPlayers == nil {
+		defaultMax := 5
+		bg.MaxPlayers = &defaultMax
+	}
+
+	bgCtx := context.Background()
+
+	var bgID *int64
+	var bgName, bgUrl, bgImageUrl *string
+	if bg.BggUrl != nil && *bg.BggUrl != "" {
+		var valid bool
+		var id int64
+		if id, valid = models.ExtractBoardGameID(*bg.BggUrl); !valid {
+			c.renderError(ctx, &event.ID, &event.ChatID, "Invalid bgg url")
+
+			return
+		}
+
+		var bgMaxPlayers *int
+
+		if bgMaxPlayers, bgName, bgUrl, bgImageUrl, err = models.ExtractGameInfo(bgCtx, c.BGG, id, bg.Name); err != nil {
+			log.Printf("Failed to get game %d: %v", id, err)
+		} else {
+			bgID = &id
+			bg.MaxPlayers = bgMaxPlayers
+		}
+	} else if !bg.NoBGG {
+		log.Printf("Searching for game %s", bg.Name)
+		var results []gobgg.SearchResult
+
+		var games []models.CollectionGame
+		if games, err = c.DB.SelectChatCollection(event.ChatID); err != nil {
+			log.Println("failed to load collections:", err)
+		}
+
+		owned := models.MatchCollection(bg.Name, games)
+		match, ok := models.PickCollectionMatch(owned)
+
+		var matches []models.BGGMatch
+		if !ok {
+			if results, err = c.BGGCache.Search(bgCtx, bg.Name); err != nil {
+				log.Printf("Failed to search game %s: %v", bg.Name, err)
+			}
+
+			matches = models.RankBGGResults(bg.Name, results)
+			if len(owned) == 0 {
+				match, ok = models.PickBGGMatch(matches)
+			} else {
+				matches = models.MergeMatches(owned, matches)
+			}
+		}
+
+		if !ok && len(matches) > 0 {
+			c.renderGamePicker(ctx, event, bg, matches)
+			return
+		}
+
+		if !ok {
+			log.Printf("Game %s not found", bg.Name)
+		} else {
+			url := models.BGGUrl(match.ID)
+			bgUrl = &url
+			bgName = &match.Name
+			bgID = &match.ID
+
+			log.Printf("Game %s id %d found: %s", bg.Name, *bgID, *bgUrl)
+
+			var bgMaxPlayers *int
+			if bgMaxPlayers, bgName, _, bgImageUrl, err = c.BGGCache.GameInfo(bgCtx, match.ID, bg.Name); err != nil {
+				bgName = &match.Name
+			} else if bgMaxPlayers != nil && *bgMaxPlayers > 0 {
+				bg.MaxPlayers = bgMaxPlayers
 			}
 		}
 	}
 
 	log.Printf("Inserting %s in the db", bg.Name)
 
-	if _, err = c.DB.InsertBoardGame(event.ID, bg.Name, *bg.MaxPlayers, bgID, bgName, bgUrl, bgImageUrl); err != nil {
+	var initiatorID *int64
+	if bg.UserID != 0 {
+		initiatorID = &bg.UserID
+	}
+
+	var gameID int64
+	if gameID, err = c.DB.InsertBoardGame(event.ID, bg.Name, *bg.MaxPlayers, bgID, bgName, bgUrl, bgImageUrl, initiatorID, bg.UserName); err != nil {
 		log.Println("failed to insert board game:", err)
 		c.renderError(ctx, &event.ID, &event.ChatID, "Failed to insert board game")
 		return
 	}
 
+	c.audit(models.NewAuditEntry(event.ID, bg.UserID, valueOf(bg.UserName), models.SourceWeb, models.AuditGameAdded, bg.Name, "", strconv.Itoa(*bg.MaxPlayers)))
+
 	if event, err = c.updateTelegram(ctx, eventID); err != nil {
 		log.Println("failed to update telegram", err)
+		return
 	}
 
-	var game *models.BoardGame
-	for _, g := range event.BoardGames {
-		if g.Name == bg.Name {
-			game = &g
-			break
-		}
+	game := event.BoardGame(gameID)
+	if game == nil {
+		c.renderError(ctx, &event.ID, &event.ChatID, "Game not found")
+		return
 	}
 
-	localizer := c.Localizer(&event.ChatID)
-
-	ctx.HTML(http.StatusOK, "game_info", gin.H{
-		"Id":                      event.ID,
-		"Title":                   event.Name,
-		"Game":                    game,
-		"NoParticipants":          localizer.MustLocalizeMessage(&i18n.Message{ID: "WebNoParticipants"}),
-		"Players":                 localizer.MustLocalizeMessage(&i18n.Message{ID: "WebPlayers"}),
-		"MaxPlayers":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebMaxPlayers"}),
-		"UpdateGame":              localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUpdateGame"}),
-		"Update":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "Update"}),
-		"UnlinkFormBoardGameGeek": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebUnlinkFormBoardGameGeek"}),
-		"GameDeletedSuccessfully": localizer.MustLocalizeMessage(&i18n.Message{ID: "WebGameDeletedSuccessfully"}),
-		"DeleteGameConfirmation":  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDeleteGameConfirmation"}),
-		"FailedToDeleteGame":      localizer.MustLocalizeMessage(&i18n.Message{ID: "WebFailedToDeleteGame"}),
-		"Delete":                  localizer.MustLocalizeMessage(&i18n.Message{ID: "WebDelete"}),
-	})
+	c.renderGame(ctx, event, game)
 }
 
 func (c *Controller) AddPlayer(ctx *gin.Context) {
@@ -552,30 +1053,443 @@ func (c *Controller) AddPlayer(ctx *gin.Context) {
 		return
 	}
 
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !event.IsActive() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Event is closed"})
+		return
+	}
+
 	if _, err = c.DB.InsertParticipant(eventID, addPlayer.GameID, addPlayer.UserID, addPlayer.UserName); err != nil {
 		log.Println("failed to add user to participants table:", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
 		return
 	}
 
+	before := event
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusCreated, gin.H{"message": "Player added."})
+		return
+	}
+
+	c.auditJoin(before, event, addPlayer)
+
+	if event, err = c.updateTelegram(ctx, eventID); err != nil {
+		log.Println("failed to update telegram", err)
+		ctx.JSON(http.StatusCreated, gin.H{"message": "Player added."})
+		return
+	}
+
+	c.notifyPromotions(before, event)
+	c.notifyPlayers(before, event, addPlayer.UserID)
+
+	for _, bg := range event.BoardGames {
+		for _, p := range bg.Waitlist {
+			if bg.ID == addPlayer.GameID && p.UserID == addPlayer.UserID {
+				ctx.JSON(http.StatusCreated, gin.H{"message": "Player added to the waitlist.", "waitlisted": true})
+				return
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Player added.", "waitlisted": false})
+}
+
+// BringGame makes the user the one who brings the game, or leaves it
+// unclaimed when they already bring it.
+func (c *Controller) BringGame(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var bring models.BringGameRequest
+	if err = ctx.ShouldBindJSON(&bring); err != nil {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !event.IsActive() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Event is closed"})
+		return
+	}
+
+	bg := event.BoardGame(bring.GameID)
+	if bg == nil || !bg.IsGame() {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+		return
+	}
+
+	before := valueOf(bg.BringerName)
+	bringerID, bringerName := &bring.UserID, &bring.UserName
+	if bg.IsBroughtBy(bring.UserID) {
+		bringerID, bringerName = nil, nil
+	}
+
+	if err = c.DB.UpdateBoardGameBringer(bg.ID, bringerID, bringerName); err != nil {
+		log.Println("failed to update bringer:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the game"})
+		return
+	}
+
+	c.audit(models.NewAuditEntry(eventID, bring.UserID, bring.UserName, models.SourceWeb, models.AuditBringerChanged, bg.Name, before, valueOf(bringerName)))
+
 	if _, err = c.updateTelegram(ctx, eventID); err != nil {
 		log.Println("failed to update telegram", err)
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"message": "Player added."})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Bringer updated.", "bringing": bringerID != nil})
+}
+
+// CastVote adds the game to the ballot of the user, or removes it when it
+// was already there. A zero game clears the ballot.
+func (c *Controller) CastVote(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var vote models.VoteRequest
+	if err = ctx.ShouldBindJSON(&vote); err != nil {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !event.IsActive() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Event is closed"})
+		return
+	}
+
+	var poll *models.Poll
+
+	if poll, err = c.DB.SelectPoll(eventID); err != nil {
+		log.Println("failed to load poll:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Vote not found"})
+		return
+	}
+
+	ballot := []int64{}
+	if vote.GameID != 0 {
+		if bg := event.BoardGame(vote.GameID); bg == nil || !bg.IsGame() {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Game not found"})
+			return
+		}
+
+		ballot = models.ToggleBallot(poll.Ballot(vote.UserID), vote.GameID)
+	}
+
+	if err = c.DB.ReplaceBallot(eventID, vote.UserID, vote.UserName, ballot); err != nil {
+		if errors.Is(err, database.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Vote is closed"})
+			return
+		}
+
+		log.Println("failed to update ballot:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the vote"})
+		return
+	}
+
+	if poll, err = c.DB.SelectPoll(eventID); err != nil {
+		log.Println("failed to load poll:", err)
+	} else {
+		c.updatePoll(event, poll)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Vote updated.", "ballot": ballot})
+}
+
+// CloseVote closes the vote and removes from the event the games that lost,
+// only the creator of the event can do it.
+func (c *Controller) CloseVote(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.CloseVoteRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !c.canOrganize(event, req.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only the organizers can close the vote"})
+		return
+	}
+
+	if !event.IsActive() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Event is closed"})
+		return
+	}
+
+	var poll *models.Poll
+
+	if poll, err = c.DB.SelectPoll(eventID); err != nil {
+		log.Println("failed to load poll:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Vote not found"})
+		return
+	}
+
+	closed, err := c.DB.ClosePoll(eventID)
+	if err != nil {
+		log.Println("failed to close poll:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close the vote"})
+		return
+	}
+
+	if !closed {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Vote is closed"})
+		return
+	}
+
+	poll.Status = models.PollClosed
+
+	winners := []string{}
+	for _, r := range poll.Tally(event.VotableGames()) {
+		if r.Winner {
+			winners = append(winners, r.BoardGame.Name)
+			continue
+		}
+
+		if err = c.DB.DeleteBoardGameByID(r.BoardGame.ID); err != nil {
+			log.Println("failed to delete board game:", err)
+			continue
+		}
+
+		c.audit(models.NewAuditEntry(eventID, req.UserID, req.UserName, models.SourceWeb, models.AuditGameDeleted, r.BoardGame.Name, "", ""))
+	}
+
+	c.audit(models.NewAuditEntry(eventID, req.UserID, req.UserName, models.SourceWeb, models.AuditVoteClosed, "", "", strings.Join(winners, ", ")))
+
+	c.updatePoll(event, poll)
+
+	before := event
+	if event, err = c.updateTelegram(ctx, eventID); err != nil {
+		log.Println("failed to update telegram", err)
+	} else {
+		c.notifyPlayers(before, event, req.UserID)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Vote closed.", "winners": winners})
+}
+
+// updatePoll queues the edit of the vote message in the chat, event must
+// still have the games of the vote.
+func (c *Controller) updatePoll(event *models.Event, poll *models.Poll) {
+	if poll.MessageID == nil {
+		return
+	}
+
+	c.Edits.Enqueue(event.ChatID, int(*poll.MessageID), func() (string, *telebot.ReplyMarkup, error) {
+		poll, err := c.DB.SelectPoll(event.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		body, markup := event.FormatPoll(c.Localizer(&event.ChatID), *poll)
+
+		return body, markup, nil
+	})
+}
+
+func (c *Controller) LeaveGame(ctx *gin.Context) {
+	var err error
+	eventID := ctx.Param("event_id")
+
+	if !models.IsValidUUID(eventID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var leaveGame models.LeaveGameRequest
+	if err = ctx.ShouldBindJSON(&leaveGame); err != nil {
+		log.Println("failed to bind form:", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	var event *models.Event
+
+	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
+		log.Println("failed to load event:", err)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if !event.IsActive() {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Event is closed"})
+		return
+	}
+
+	if err = c.DB.RemoveParticipantFromBoardGame(eventID, leaveGame.GameID, leaveGame.UserID); err != nil {
+		log.Println("failed to remove user from game:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave the game"})
+		return
+	}
+
+	if bg := event.BoardGame(leaveGame.GameID); bg != nil && (bg.IsSeated(leaveGame.UserID) || bg.IsWaiting(leaveGame.UserID)) {
+		c.audit(models.NewAuditEntry(eventID, leaveGame.UserID, leaveGame.UserName, models.SourceWeb, models.AuditPlayerLeft, bg.Name, "", ""))
+	}
+
+	before := event
+	if event, err = c.updateTelegram(ctx, eventID); err != nil {
+		log.Println("failed to update telegram", err)
+		ctx.JSON(http.StatusOK, gin.H{"message": "Player removed."})
+		return
+	}
+
+	c.notifyPromotions(before, event)
+	c.notifyPlayers(before, event, leaveGame.UserID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Player removed."})
+}
+
+// notifyPromotions announces in the chat the players seated from a waitlist.
+func (c *Controller) notifyPromotions(before, after *models.Event) {
+	models.SendPromotions(c.Bot, c.Localizer(&after.ChatID), before, after, models.SourceWeb, c.audit)
+}
+
+// notifyPlayers tells in private the players who opted in about the changes
+// of their games, leaving out the one who made them.
+func (c *Controller) notifyPlayers(before, after *models.Event, actorID int64) {
+	models.SendNotifications(c.Bot, c.Localizer(&after.ChatID), before, after, actorID, database.KnownUser(c.DB))
+}
+
+// audit records a change made from the web, a failure is only logged as it
+// must not prevent the change itself.
+func (c *Controller) audit(entry models.AuditEntry) {
+	if err := c.DB.InsertAuditEntry(entry); err != nil {
+		log.Println("failed to write audit log:", err)
+	}
+}
+
+// auditJoin records the player joining a game, and leaving the games they
+// were moved out of, comparing the event before and after the join.
+func (c *Controller) auditJoin(before, after *models.Event, req models.AddPlayerRequest) {
+	bg := after.BoardGame(req.GameID)
+	if bg == nil {
+		return
+	}
+
+	if previous := before.BoardGame(req.GameID); previous == nil || !(previous.IsSeated(req.UserID) || previous.IsWaiting(req.UserID)) {
+		action := models.AuditPlayerJoined
+		if bg.IsWaiting(req.UserID) {
+			action = models.AuditPlayerWaitlisted
+		}
+
+		c.audit(models.NewAuditEntry(after.ID, req.UserID, req.UserName, models.SourceWeb, action, bg.Name, "", ""))
+	}
+
+	for _, left := range before.JoinedBoardGames(req.UserID) {
+		if current := after.BoardGame(left.ID); current != nil && !current.IsSeated(req.UserID) && !current.IsWaiting(req.UserID) {
+			c.audit(models.NewAuditEntry(after.ID, req.UserID, req.UserName, models.SourceWeb, models.AuditPlayerLeft, left.Name, "", ""))
+		}
+	}
+}
+
+// formatDetails describes when and where the event takes place.
+func formatDetails(event models.Event) string {
+	details := event.FormatWhen()
+	if event.Location != nil {
+		if details != "" {
+			details += " · "
+		}
+
+		details += *event.Location
+	}
+
+	return details
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
 }
 
 func P(x string) *string {
 	return &x
 }
 
+// canEdit reports whether the user can change the games of the event, the
+// administrators of the chat have the same rights as its organizers.
+func (c *Controller) canEdit(event *models.Event, userID int64) bool {
+	if event.CanEdit(userID) {
+		return true
+	}
+
+	return event.Status == models.StatusLocked && c.Admins.IsAdmin(event.ChatID, userID)
+}
+
+// canOrganize reports whether the user can change the settings of the event:
+// its organizers and the administrators of the chat can.
+func (c *Controller) canOrganize(event *models.Event, userID int64) bool {
+	return event.IsOrganizer(userID) || c.Admins.IsAdmin(event.ChatID, userID)
+}
+
+// canRecordResult reports whether the user can record the result of the
+// game, the administrators of the chat have the same rights as its
+// organizers.
+func (c *Controller) canRecordResult(event *models.Event, game *models.BoardGame, userID int64) bool {
+	if event.CanRecordResult(*game, userID) {
+		return true
+	}
+
+	return event.Status != models.StatusCancelled && game.IsGame() && c.Admins.IsAdmin(event.ChatID, userID)
+}
+
 func (c *Controller) updateTelegram(ctx *gin.Context, eventID string) (*models.Event, error) {
 	var err error
 	var event *models.Event
 
 	if event, err = c.DB.SelectEventByEventID(eventID); err != nil {
 		log.Println("failed to load game:", err)
-		c.renderError(ctx, &eventID, &event.ChatID, "Invalid event ID")
+		c.renderError(ctx, &eventID, nil, "Invalid event ID")
 
 		return nil, err
 	}
@@ -583,23 +1497,21 @@ func (c *Controller) updateTelegram(ctx *gin.Context, eventID string) (*models.E
 	if event.MessageID == nil {
 		log.Println("event message id is nil")
 		c.renderError(ctx, &eventID, &event.ChatID, "Invalid message ID")
-		return nil, err
+		return nil, errors.New("event message id is nil")
 	}
 
-	body, markup := event.FormatMsg(c.Localizer(&event.ChatID), c.BaseUrl, c.BotName)
-
-	_, err = c.Bot.Edit(&telebot.Message{
-		ID: int(*event.MessageID),
-		Chat: &telebot.Chat{
-			ID: event.ChatID,
-		},
-	}, body, markup, telebot.NoPreview)
-	if err != nil {
-		log.Println("failed to edit message", err)
-		if strings.Contains(err.Error(), models.MessageUnchangedErrorMessage) {
-			log.Println("failed because unchanged", err)
+	// the event is loaded again right before the edit, so a burst of changes
+	// is shown by a single edit with the latest state
+	c.Edits.Enqueue(event.ChatID, int(*event.MessageID), func() (string, *telebot.ReplyMarkup, error) {
+		event, err := c.DB.SelectEventByEventID(eventID)
+		if err != nil {
+			return "", nil, err
 		}
-	}
+
+		body, markup := event.FormatMsg(c.Localizer(&event.ChatID), c.BaseUrl, c.BotName)
+
+		return body, markup, nil
+	})
 
 	return event, nil
 }
@@ -2,6 +2,7 @@ package web
 
 import (
 	"boardgame-night-bot/src/database"
+	"boardgame-night-bot/src/models"
 	"boardgame-night-bot/src/web/api"
 	"fmt"
 
@@ -11,17 +12,21 @@ import (
 	"gopkg.in/telebot.v3"
 )
 
-func StartServer(port int, db *database.Database, bgg *gobgg.BGG, bot *telebot.Bot, bundle *i18n.Bundle, baseUrl, botName string) {
+func StartServer(port int, db database.Store, bgg *gobgg.BGG, bggCache *models.BGGCache, admins *models.AdminCache, bot *telebot.Bot, edits *models.EditQueue, bundle *i18n.Bundle, baseUrl, botName, webhookSecret string) {
 	var err error
 	router := gin.Default()
 
 	router.Use(gin.Logger())
 	router.LoadHTMLGlob("templates/*")
 
-	controller := api.NewController(router.Group("/"), db, bgg, bot, bundle, baseUrl, botName)
+	controller := api.NewController(router.Group("/"), db, bgg, bggCache, admins, bot, edits, bundle, baseUrl, botName, webhookSecret)
 
 	controller.InjectRoute()
 
+	if webhookSecret != "" {
+		router.POST(WebhookPath, controller.Webhook)
+	}
+
 	router.NoRoute(func(ctx *gin.Context) {
 		controller.NoRoute(ctx)
 	})
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+)
+
+// WebhookPath is the route of the web server receiving the updates when the
+// bot runs in webhook mode.
+const WebhookPath = "/telegram/webhook"
+
+// WebhookPoller registers the webhook when the bot starts and deletes it when
+// the bot stops. The updates are passed to the bot by the webhook route of the
+// web server, instead of being polled.
+type WebhookPoller struct {
+	Webhook *telebot.Webhook
+}
+
+func NewWebhookPoller(baseUrl, secret string, allowedUpdates []string) *WebhookPoller {
+	return &WebhookPoller{
+		Webhook: &telebot.Webhook{
+			SecretToken:    secret,
+			AllowedUpdates: allowedUpdates,
+			Endpoint: &telebot.WebhookEndpoint{
+				PublicURL: strings.TrimSuffix(baseUrl, "/") + WebhookPath,
+			},
+		},
+	}
+}
+
+func (p *WebhookPoller) Poll(b *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	if err := b.SetWebhook(p.Webhook); err != nil {
+		log.Fatal("failed to set the webhook: ", err)
+	}
+
+	log.Println("webhook set to", p.Webhook.Endpoint.PublicURL)
+
+	<-stop
+
+	if err := b.RemoveWebhook(); err != nil {
+		log.Println("failed to delete the webhook:", err)
+		return
+	}
+
+	log.Println("webhook deleted")
+}
+
+// NewWebhookSecret returns a random secret token, used when WEBHOOK_SECRET is
+// not set.
+func NewWebhookSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("failed to generate the webhook secret: ", err)
+	}
+
+	return hex.EncodeToString(b)
+}